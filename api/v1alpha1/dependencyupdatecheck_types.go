@@ -15,6 +15,7 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -52,6 +53,34 @@ type NamespaceSpec struct {
 	Applications []ApplicationSpec `json:"applications,omitempty"`
 }
 
+// PodSchedulingSpec controls where the pods of Renovate PipelineRuns are scheduled.
+// Fields that are set replace the ones of the MintMaker configuration.
+type PodSchedulingSpec struct {
+	// Specifies the node labels the pods must be scheduled on.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Specifies the tolerations of the pods, e.g. for dedicated Renovate nodes.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Specifies the node affinity and pod (anti-)affinity of the pods.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// Specifies the PriorityClass of the pods.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// Specifies how the pods are spread across topology domains.
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// Specifies the RuntimeClass of the pods.
+	// +optional
+	RuntimeClassName string `json:"runtimeClassName,omitempty"`
+}
+
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
@@ -66,12 +95,37 @@ type DependencyUpdateCheckSpec struct {
 	// If omitted, MintMaker will run for all namespaces.
 	// +optional
 	Namespaces []NamespaceSpec `json:"namespaces,omitempty"`
+
+	// Specifies where the pods of the created PipelineRuns are scheduled.
+	// If omitted, the scheduling of the MintMaker configuration is used.
+	// +optional
+	PodScheduling *PodSchedulingSpec `json:"podScheduling,omitempty"`
+}
+
+// SkippedComponent records a Component for which no PipelineRun was created.
+type SkippedComponent struct {
+	// Namespace of the Component.
+	Namespace string `json:"namespace"`
+
+	// Name of the Component.
+	Name string `json:"name"`
+
+	// Reason is a CamelCase reason the Component was skipped, e.g. CredentialsExpired.
+	Reason string `json:"reason"`
+
+	// Message is a human readable explanation of the reason.
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // DependencyUpdateCheckStatus defines the observed state of DependencyUpdateCheck
 type DependencyUpdateCheckStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+
+	// Specifies the Components that were skipped, e.g. because their credentials failed pre-flight validation.
+	// +optional
+	SkippedComponents []SkippedComponent `json:"skippedComponents,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,58 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/konflux-ci/mintmaker/internal/tekton"
+)
+
+// Runs as a step after the leaktk scan, redacting its findings and the
+// secrets known to MintMaker from the Renovate log. With --known-secrets-only
+// it runs before the scan instead, redacting the known secrets only. A log
+// that could not be sanitized is removed, and the step still succeeds.
+func main() {
+	logFile := flag.String("log-file", "/workspace/shared-data/renovate-logs.json", "Renovate JSON log to sanitize")
+	scanDir := flag.String("scan-dir", tekton.LogScanDir, "Directory with the leaktk findings")
+	knownSecretsDir := flag.String("known-secrets-dir", "", "Directory with the secrets injected into the run, one per file")
+	fingerprintsFile := flag.String("known-fingerprints-file", "", "File with the fingerprints of the secrets fetched during the run, one per line")
+	knownSecretsOnly := flag.Bool("known-secrets-only", false, "Only redact the known secrets, before the leaktk scan")
+	redactionsFile := flag.String("redactions", "", "File to write the number of redacted secrets to")
+	flag.Parse()
+
+	if *knownSecretsOnly {
+		if err := tekton.RedactKnownSecrets(*logFile, *scanDir, *knownSecretsDir, *fingerprintsFile, os.Stdout); err != nil {
+			fmt.Println("failed to remove the log: ", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	redactions, err := tekton.SanitizeLog(*logFile, *scanDir, *knownSecretsDir, *fingerprintsFile, os.Stdout)
+	if err != nil {
+		fmt.Println("failed to remove the log: ", err)
+		os.Exit(1)
+	}
+	if *redactionsFile != "" {
+		if err := os.WriteFile(*redactionsFile, []byte(strconv.Itoa(redactions)), 0644); err != nil { //nolint:gosec // Tekton results are not secret
+			fmt.Println("failed to write result: ", err)
+			os.Exit(1)
+		}
+	}
+}
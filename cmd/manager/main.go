@@ -44,9 +44,12 @@ import (
 
 	mmv1alpha1 "github.com/konflux-ci/mintmaker/api/v1alpha1"
 	"github.com/konflux-ci/mintmaker/internal/component"
+	"github.com/konflux-ci/mintmaker/internal/config"
 	mmconst "github.com/konflux-ci/mintmaker/internal/constant"
 	"github.com/konflux-ci/mintmaker/internal/controller"
 	mintmakermetrics "github.com/konflux-ci/mintmaker/internal/metrics"
+	"github.com/konflux-ci/mintmaker/internal/presetserver"
+	"github.com/konflux-ci/mintmaker/internal/tokenbroker"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -159,6 +162,10 @@ func main() {
 					&corev1.ConfigMap{},
 					&corev1.Pod{},
 					&appstudiov1alpha1.Component{},
+					// Read when a PipelineRun template is refreshed
+					&tektonv1.Pipeline{},
+					// Read when classifying failed PipelineRuns for retries
+					&tektonv1.TaskRun{},
 				},
 			},
 		},
@@ -254,6 +261,43 @@ func main() {
 		os.Exit(1)
 	}
 
+	if brokerCfg := config.Get().TokenBroker; brokerCfg.Enabled {
+		if err := mgr.Add(&tokenbroker.Broker{
+			Client:          mgr.GetClient(),
+			NewGitComponent: component.NewGitComponent,
+			Audience:        brokerCfg.Audience,
+			BindAddress:     brokerCfg.BindAddress,
+			CertFile:        brokerCfg.CertFile,
+			KeyFile:         brokerCfg.KeyFile,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up token broker")
+			os.Exit(1)
+		}
+	}
+
+	if presetsCfg := config.Get().Presets; presetsCfg.Enabled {
+		// ConfigMaps are not cached by the manager, the server gets its own cache
+		presetCluster, err := presetserver.NewCluster(mgr.GetConfig(), mgr.GetScheme(), mmconst.MintMakerNamespaceName)
+		if err != nil {
+			setupLog.Error(err, "unable to set up preset cache")
+			os.Exit(1)
+		}
+		if err := mgr.Add(presetCluster); err != nil {
+			setupLog.Error(err, "unable to set up preset cache")
+			os.Exit(1)
+		}
+		if err := mgr.Add(&presetserver.Server{
+			Client:      presetCluster.GetCache(),
+			Namespace:   mmconst.MintMakerNamespaceName,
+			BindAddress: presetsCfg.BindAddress,
+			CertFile:    presetsCfg.CertFile,
+			KeyFile:     presetsCfg.KeyFile,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up preset server")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -0,0 +1,184 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
+
+	"github.com/konflux-ci/mintmaker/internal/component"
+	"github.com/konflux-ci/mintmaker/internal/credentials"
+)
+
+const usage = `Usage: mintmaker <command> [flags] [args]
+
+Commands:
+  explain-credentials <namespace>/<component>
+        Print which secrets MintMaker uses for a component and why
+
+Exit status of explain-credentials:
+  0  every credential resolved to a single secret
+  1  the component could not be explained
+  2  invalid arguments
+  3  no SCM secret applies to the component
+  4  a secret was picked by name out of several equally good matches
+`
+
+// Exit codes of explain-credentials
+const (
+	exitOK        = 0
+	exitError     = 1
+	exitUsage     = 2
+	exitNoMatch   = 3
+	exitAmbiguous = 4
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(exitUsage)
+	}
+
+	switch os.Args[1] {
+	case "explain-credentials":
+		os.Exit(explainCredentials(context.Background(), os.Args[2:], os.Stdout, os.Stderr, newClient))
+	case "-h", "--help", "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(exitUsage)
+	}
+}
+
+// newClient creates a client for the cluster of the current kubeconfig
+func newClient() (client.Client, error) {
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := appstudiov1alpha1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	cl, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	return cl, nil
+}
+
+// explainCredentials prints the credential decisions of a component and
+// returns the exit code
+func explainCredentials(ctx context.Context, args []string, out, errOut io.Writer, newClient func() (client.Client, error)) int {
+	flags := flag.NewFlagSet("explain-credentials", flag.ContinueOnError)
+	flags.SetOutput(errOut)
+	output := flags.String("o", "text", "Output format, text or json")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	usageError := func(format string, args ...any) int {
+		fmt.Fprintf(errOut, "explain-credentials: "+format+"\n", args...)
+		return exitUsage
+	}
+	if *output != "text" && *output != "json" {
+		return usageError("unknown output format %q", *output)
+	}
+	if flags.NArg() != 1 {
+		return usageError("expected a single <namespace>/<component> argument")
+	}
+	namespace, name, found := strings.Cut(flags.Arg(0), "/")
+	if !found || namespace == "" || name == "" {
+		return usageError("invalid component %q, expected <namespace>/<component>", flags.Arg(0))
+	}
+
+	gitComp, decisions, err := explain(ctx, namespace, name, newClient)
+	if err != nil {
+		fmt.Fprintln(errOut, "explain-credentials:", err)
+		return exitError
+	}
+
+	if *output == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(decisions); err != nil {
+			fmt.Fprintln(errOut, "explain-credentials:", err)
+			return exitError
+		}
+	} else {
+		printDecisions(out, gitComp, decisions)
+	}
+	return exitCode(decisions)
+}
+
+func explain(ctx context.Context, namespace, name string, newClient func() (client.Client, error)) (component.GitComponent, []credentials.Decision, error) {
+	cl, err := newClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	comp := &appstudiov1alpha1.Component{}
+	if err := cl.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, comp); err != nil {
+		return nil, nil, fmt.Errorf("failed to get component: %w", err)
+	}
+	gitComp, err := component.NewGitComponent(ctx, comp, cl)
+	if err != nil {
+		return nil, nil, err
+	}
+	decisions, err := gitComp.ExplainCredentials()
+	if err != nil {
+		return nil, nil, err
+	}
+	return gitComp, decisions, nil
+}
+
+// exitCode reports a missing SCM secret before an ambiguous decision. The
+// RPM activation key is optional, so it never being found is not an error.
+func exitCode(decisions []credentials.Decision) int {
+	for _, decision := range decisions {
+		if decision.Kind == credentials.KindSCM && decision.Secret == "" {
+			return exitNoMatch
+		}
+	}
+	for _, decision := range decisions {
+		if decision.Ambiguous {
+			return exitAmbiguous
+		}
+	}
+	return exitOK
+}
+
+func printDecisions(out io.Writer, comp component.GitComponent, decisions []credentials.Decision) {
+	fmt.Fprintf(out, "Component %s/%s (%s/%s)\n", comp.GetNamespace(), comp.GetName(), comp.GetHost(), comp.GetRepository())
+	for _, decision := range decisions {
+		fmt.Fprintf(out, "\n%s\n", decision)
+		for _, step := range decision.Path {
+			fmt.Fprintf(out, "  - %s\n", step)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
+
+	"github.com/konflux-ci/mintmaker/internal/credentials"
+)
+
+func newComponent() *appstudiov1alpha1.Component {
+	return &appstudiov1alpha1.Component{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "tenant"},
+		Spec: appstudiov1alpha1.ComponentSpec{
+			Source: appstudiov1alpha1.ComponentSource{
+				ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+					GitSource: &appstudiov1alpha1.GitSource{URL: "https://gitlab.com/org/repo"},
+				},
+			},
+		},
+	}
+}
+
+func scmSecret(name, repositories string) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "tenant",
+			Labels: map[string]string{
+				credentials.CredentialsLabel: string(credentials.KindSCM),
+				credentials.HostLabel:        "gitlab.com",
+			},
+		},
+		Type: corev1.SecretTypeBasicAuth,
+		Data: map[string][]byte{corev1.BasicAuthPasswordKey: []byte("token")},
+	}
+	if repositories != "" {
+		secret.Annotations = map[string]string{credentials.RepositoryAnnotation: repositories}
+	}
+	return secret
+}
+
+func newScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := appstudiov1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return scheme
+}
+
+func TestExplainCredentials(t *testing.T) {
+	scheme := newScheme(t)
+
+	tests := []struct {
+		name           string
+		args           []string
+		objects        []client.Object
+		clientErr      error
+		expectedCode   int
+		expectedOut    []string
+		expectedErrOut string
+	}{
+		{
+			name:         "matched",
+			args:         []string{"tenant/app"},
+			objects:      []client.Object{newComponent(), scmSecret("repo-token", "org/repo"), scmSecret("org-token", "org/*")},
+			expectedCode: exitOK,
+			expectedOut: []string{
+				"Component tenant/app (gitlab.com/org/repo)",
+				"scm: secret repo-token (exact-match)\n",
+				"  - secret repo-token lists repository org/repo",
+				"rpm: no secret (no-match)",
+			},
+		},
+		{
+			name:         "ambiguous",
+			args:         []string{"tenant/app"},
+			objects:      []client.Object{newComponent(), scmSecret("b-token", "org/*"), scmSecret("a-token", "org/r*")},
+			expectedCode: exitAmbiguous,
+			expectedOut: []string{
+				"scm: secret a-token (wildcard-match, ambiguous)",
+				"  - secrets a-token, b-token tie, using a-token by name",
+			},
+		},
+		{
+			name:         "no match",
+			args:         []string{"tenant/app"},
+			objects:      []client.Object{newComponent(), scmSecret("other-token", "other/repo")},
+			expectedCode: exitNoMatch,
+			expectedOut: []string{
+				"scm: no secret (no-match)",
+				"  - no secret matches repository org/repo",
+			},
+		},
+		{
+			name:         "json output",
+			args:         []string{"-o", "json", "tenant/app"},
+			objects:      []client.Object{newComponent(), scmSecret("b-token", ""), scmSecret("a-token", "")},
+			expectedCode: exitAmbiguous,
+			expectedOut:  []string{`"secret": "a-token"`, `"reason": "host-only"`, `"ambiguous": true`},
+		},
+		{
+			name:           "missing component",
+			args:           []string{"tenant/missing"},
+			expectedCode:   exitError,
+			expectedErrOut: "failed to get component",
+		},
+		{
+			name:           "client error",
+			args:           []string{"tenant/app"},
+			clientErr:      errors.New("no kubeconfig"),
+			expectedCode:   exitError,
+			expectedErrOut: "no kubeconfig",
+		},
+		{
+			name:           "invalid component argument",
+			args:           []string{"app"},
+			expectedCode:   exitUsage,
+			expectedErrOut: `invalid component "app"`,
+		},
+		{
+			name:           "missing argument",
+			expectedCode:   exitUsage,
+			expectedErrOut: "expected a single <namespace>/<component> argument",
+		},
+		{
+			name:           "unknown output format",
+			args:           []string{"-o", "yaml", "tenant/app"},
+			expectedCode:   exitUsage,
+			expectedErrOut: `unknown output format "yaml"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			newClient := func() (client.Client, error) {
+				if tc.clientErr != nil {
+					return nil, tc.clientErr
+				}
+				return fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build(), nil
+			}
+			var out, errOut bytes.Buffer
+			code := explainCredentials(context.Background(), tc.args, &out, &errOut, newClient)
+
+			if code != tc.expectedCode {
+				t.Errorf("expected exit code %d, got %d (stderr %q)", tc.expectedCode, code, errOut.String())
+			}
+			for _, expected := range tc.expectedOut {
+				if !strings.Contains(out.String(), expected) {
+					t.Errorf("expected output to contain %q, got:\n%s", expected, out.String())
+				}
+			}
+			if tc.expectedErrOut != "" && !strings.Contains(errOut.String(), tc.expectedErrOut) {
+				t.Errorf("expected stderr to contain %q, got %q", tc.expectedErrOut, errOut.String())
+			}
+			if tc.expectedErrOut == "" && errOut.Len() > 0 {
+				t.Errorf("unexpected stderr %q", errOut.String())
+			}
+		})
+	}
+}
+
+func TestExplainCredentialsJSON(t *testing.T) {
+	scheme := newScheme(t)
+	newClient := func() (client.Client, error) {
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(newComponent(), scmSecret("repo-token", "org/repo")).Build(), nil
+	}
+
+	var out, errOut bytes.Buffer
+	if code := explainCredentials(context.Background(), []string{"-o", "json", "tenant/app"}, &out, &errOut, newClient); code != exitOK {
+		t.Fatalf("expected exit code %d, got %d (stderr %q)", exitOK, code, errOut.String())
+	}
+	var decisions []credentials.Decision
+	if err := json.Unmarshal(out.Bytes(), &decisions); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+	}
+	if len(decisions) != 2 || decisions[0].Kind != credentials.KindSCM || decisions[1].Kind != credentials.KindRPM {
+		t.Fatalf("expected scm and rpm decisions, got %+v", decisions)
+	}
+	if decisions[0].Secret != "repo-token" || decisions[0].Reason != credentials.ReasonExactMatch || decisions[0].Ambiguous {
+		t.Errorf("unexpected scm decision %+v", decisions[0])
+	}
+}
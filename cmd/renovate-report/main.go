@@ -0,0 +1,84 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+
+	"github.com/konflux-ci/mintmaker/internal/renovatelog"
+)
+
+// Runs as a step after Renovate, summarizing its JSON log into task results
+func main() {
+	logFile := flag.String("log-file", "/workspace/shared-data/renovate-logs.json", "Renovate JSON log to summarize")
+	reportFile := flag.String("report", "", "File to write the JSON report to")
+	// Tekton limits the size of all results of a task to 4KiB
+	maxReportBytes := flag.Int("max-report-bytes", 2500, "Maximum size of the JSON report")
+	prsCreatedFile := flag.String("prs-created", "", "File to write the number of created pull requests to")
+	prsUpdatedFile := flag.String("prs-updated", "", "File to write the number of updated pull requests to")
+	updatedDependenciesFile := flag.String("updated-dependencies", "", "File to write the number of updated dependencies to")
+	errorsFile := flag.String("errors", "", "File to write the number of errors to")
+	flag.Parse()
+
+	report := &renovatelog.Report{}
+	file, err := os.Open(*logFile)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		fmt.Println("Log file not found, reporting no results")
+	case err != nil:
+		fmt.Println("failed to open the Renovate log: ", err)
+		os.Exit(1)
+	default:
+		report, err = renovatelog.Parse(file)
+		_ = file.Close()
+		if err != nil {
+			fmt.Println("failed to parse the Renovate log: ", err)
+			os.Exit(1)
+		}
+	}
+
+	data, err := report.Marshal(*maxReportBytes)
+	if err != nil {
+		fmt.Println("failed to encode the report: ", err)
+		os.Exit(1)
+	}
+	results := []struct {
+		file  string
+		value string
+	}{
+		{*reportFile, string(data)},
+		{*prsCreatedFile, strconv.Itoa(report.PullRequests.Created)},
+		{*prsUpdatedFile, strconv.Itoa(report.PullRequests.Updated)},
+		{*updatedDependenciesFile, strconv.Itoa(report.Updates.Total)},
+		{*errorsFile, strconv.Itoa(report.ErrorCount)},
+	}
+	for _, result := range results {
+		if result.file == "" {
+			continue
+		}
+		if err := os.WriteFile(result.file, []byte(result.value), 0644); err != nil { //nolint:gosec // Tekton results are not secret
+			fmt.Println("failed to write result: ", err)
+			os.Exit(1)
+		}
+	}
+	fmt.Printf("PRs created: %d, PRs updated: %d, PRs closed: %d, updated dependencies: %d, warnings: %d, errors: %d\n",
+		report.PullRequests.Created, report.PullRequests.Updated, report.PullRequests.Closed,
+		report.Updates.Total, report.WarningCount, report.ErrorCount)
+}
@@ -20,22 +20,26 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"slices"
+	"sort"
 	"strings"
-	"sync"
+	"time"
 
+	"golang.org/x/oauth2"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logger "sigs.k8s.io/controller-runtime/pkg/log"
 
-	bslices "github.com/konflux-ci/mintmaker/internal/slices"
+	"github.com/konflux-ci/mintmaker/internal/config"
+	mmconst "github.com/konflux-ci/mintmaker/internal/constant"
+	"github.com/konflux-ci/mintmaker/internal/credentials"
+	mintmakermetrics "github.com/konflux-ci/mintmaker/internal/metrics"
+	"github.com/konflux-ci/mintmaker/internal/renovateconfig"
 )
 
-var (
-	renovateBaseConfig      map[string]interface{}
-	renovateBaseConfigMutex sync.RWMutex
-)
+// renovateBaseConfig is read from the renovate-config ConfigMap and refreshed
+// after the configured refresh interval
+var renovateBaseConfig = renovateconfig.NewBaseLoader(types.NamespacedName{Namespace: "mintmaker", Name: "renovate-config"})
 
 type BaseComponent struct {
 	Name        string
@@ -49,6 +53,8 @@ type BaseComponent struct {
 	Repository    string
 	Versions      []string
 	OldCRDVersion bool
+	// Names of the MintMaker presets the component extends
+	Presets []string
 }
 
 func (c *BaseComponent) GetName() string {
@@ -79,86 +85,212 @@ func (c *BaseComponent) GetRepository() string {
 	return c.Repository
 }
 
-type HostRule map[string]string
+// dockerAuth is an entry of the auths of a dockerconfigjson
+type dockerAuth struct {
+	Auth          string `json:"auth"`
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	IdentityToken string `json:"identitytoken"`
+	RegistryToken string `json:"registrytoken"`
+}
 
-func (c *BaseComponent) GetHostRules(ctx context.Context, registrySecret *corev1.Secret) ([]HostRule, error) {
+// GetHostRules returns docker host rules for the auths of the registry
+// secret's dockerconfigjson. Entries scoped to a repository path match the
+// registry API URLs of that path and follow the rules of their registry, so
+// Renovate prefers them. Entries without credentials only match their host,
+// invalid entries are skipped.
+func (c *BaseComponent) GetHostRules(ctx context.Context, registrySecret *corev1.Secret) ([]renovateconfig.HostRule, error) {
 	log := logger.FromContext(ctx)
 
 	if _, exists := registrySecret.Data[corev1.DockerConfigJsonKey]; !exists {
 		return nil, fmt.Errorf("secret %s is missing the %s key", registrySecret.Name, corev1.DockerConfigJsonKey)
 	}
 
-	var hostRules []HostRule
-	var secrets map[string]map[string]HostRule
-
-	err := json.Unmarshal(registrySecret.Data[corev1.DockerConfigJsonKey], &secrets)
-
-	if err != nil {
+	var dockerConfig struct {
+		Auths map[string]json.RawMessage `json:"auths"`
+	}
+	if err := json.Unmarshal(registrySecret.Data[corev1.DockerConfigJsonKey], &dockerConfig); err != nil {
 		log.Info(fmt.Sprintf("Cannot unmarshal registry secret: %s", err))
 		return nil, err
 	}
 
-	for registry, credentials := range secrets["auths"] {
-		hostRule := HostRule{}
-		hostRule["matchHost"] = registry
-
-		if _, ok := credentials["auth"]; ok {
-			auth_plain, err := base64.StdEncoding.DecodeString(string(credentials["auth"]))
-
-			if err != nil {
-				log.Info("Cannot base64 decode auth")
-				return nil, err
-			}
+	type scopedHostRule struct {
+		host, path string
+		hostRule   renovateconfig.HostRule
+	}
+	var scoped []scopedHostRule
+	for registry, raw := range dockerConfig.Auths {
+		var auth dockerAuth
+		if err := json.Unmarshal(raw, &auth); err != nil {
+			log.Info("Skipping invalid registry auth", "registry", registry, "err", err.Error())
+			continue
+		}
+		host, path := splitRegistry(registry)
+		hostRule, err := dockerHostRule(auth)
+		if err != nil {
+			log.Info("Skipping invalid registry auth", "registry", registry, "err", err.Error())
+			continue
+		}
+		if auth.IdentityToken != "" && hostRule.Password == "" && hostRule.Token == "" {
+			log.Info("Ignoring registry identity token, it is an OAuth refresh token Renovate cannot log in with", "registry", registry)
+		}
+		hostRule.MatchHost = host
+		if path != "" {
+			hostRule.MatchHost = "https://" + host + "/v2/" + path
+		}
+		scoped = append(scoped, scopedHostRule{host: host, path: path, hostRule: hostRule})
+	}
 
-			username, password, found := strings.Cut(string(auth_plain), ":")
+	// Registries first, then their repository paths from the least to the
+	// most specific, so more specific rules come later and win
+	sort.Slice(scoped, func(i, j int) bool {
+		if scoped[i].host != scoped[j].host {
+			return scoped[i].host < scoped[j].host
+		}
+		if len(scoped[i].path) != len(scoped[j].path) {
+			return len(scoped[i].path) < len(scoped[j].path)
+		}
+		return scoped[i].path < scoped[j].path
+	})
+	hostRules := make([]renovateconfig.HostRule, 0, len(scoped))
+	for _, rule := range scoped {
+		hostRules = append(hostRules, rule.hostRule)
+	}
+	return hostRules, nil
+}
 
-			if !found {
-				log.Info("Could not find delimiter in auth")
-				return nil, errors.New("could not find delimiter in auth")
-			}
+// splitRegistry returns the host and repository path of an auths key, which
+// may be a URL such as https://index.docker.io/v1/ or be scoped to a path
+// such as quay.io/org/repo
+func splitRegistry(registry string) (string, string) {
+	registry = strings.TrimPrefix(strings.TrimPrefix(registry, "https://"), "http://")
+	host, path, _ := strings.Cut(strings.TrimSuffix(registry, "/"), "/")
+	if host == "index.docker.io" || host == "registry-1.docker.io" {
+		host = "docker.io"
+	}
+	// Legacy Docker Hub and v2 API keys carry the API version, not a path
+	if path == "v1" || path == "v2" {
+		path = ""
+	}
+	return host, path
+}
 
-			hostRule["username"] = username
-			hostRule["password"] = password
-			hostRule["hostType"] = "docker"
+// dockerHostRule returns the credentials of an auths entry. A registry token
+// is sent as bearer token. Identity tokens are OAuth refresh tokens exchanged
+// for registry tokens by the docker CLI, they are not passwords and ignored.
+// Entries without credentials get a rule without host type, as before.
+func dockerHostRule(auth dockerAuth) (renovateconfig.HostRule, error) {
+	hostRule := renovateconfig.HostRule{HostType: "docker", Username: auth.Username, Password: auth.Password}
+	if auth.Auth != "" {
+		authPlain, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return hostRule, fmt.Errorf("cannot base64 decode auth: %w", err)
 		}
-
-		hostRules = append(hostRules, hostRule)
+		username, password, found := strings.Cut(string(authPlain), ":")
+		if !found {
+			return hostRule, errors.New("could not find delimiter in auth")
+		}
+		hostRule.Username = username
+		hostRule.Password = password
+	}
+	if auth.RegistryToken != "" {
+		hostRule.Username = ""
+		hostRule.Password = ""
+		hostRule.Token = auth.RegistryToken
 	}
+	if hostRule.Password == "" && hostRule.Token == "" {
+		return renovateconfig.HostRule{}, nil
+	}
+	return hostRule, nil
+}
 
-	return hostRules, nil
+// GetRenovateBaseConfig returns the base Renovate config shared by all
+// components. It is cached and must not be modified, use NewRenovateConfigBuilder.
+func (c *BaseComponent) GetRenovateBaseConfig(ctx context.Context, client client.Client) (*renovateconfig.Config, error) {
+	return renovateBaseConfig.Get(ctx, client, config.Get().RenovateConfig.RefreshInterval)
 }
 
-func (c *BaseComponent) GetRenovateBaseConfig(ctx context.Context, client client.Client) (map[string]interface{}, error) {
-	renovateBaseConfigMutex.RLock()
-	if renovateBaseConfig != nil {
-		defer renovateBaseConfigMutex.RUnlock()
-		return renovateBaseConfig, nil
+// NewRenovateConfigBuilder returns a Renovate config builder on top of a copy
+// of the base config, with the allowed options of the namespace ConfigMaps as
+// namespace layer. The component layer holds the host rules of registrySecret,
+// when set, the registry mirror aliases and the host rules of the namespace's
+// package registry secrets.
+func (c *BaseComponent) NewRenovateConfigBuilder(ctx context.Context, client client.Client, registrySecret *corev1.Secret) (*renovateconfig.Builder, error) {
+	baseConfig, err := c.GetRenovateBaseConfig(ctx, client)
+	if err != nil {
+		return nil, err
 	}
-	renovateBaseConfigMutex.RUnlock()
+	builder := renovateconfig.NewBuilder(baseConfig)
 
-	baseConfig := corev1.ConfigMap{}
-	configmapKey := types.NamespacedName{Namespace: "mintmaker", Name: "renovate-config"}
-	if err := client.Get(ctx, configmapKey, &baseConfig); err != nil {
-		return nil, err
+	namespaceOptions := config.Get().RenovateConfig.NamespaceOptions
+	if namespaceOptions == nil {
+		namespaceOptions = renovateconfig.DefaultNamespaceOptions
+	}
+	namespaceConfig, dropped, err := renovateconfig.NamespaceOverlay(ctx, client, c.Namespace, namespaceOptions)
+	if err != nil {
+		// Tenant customizations are optional, the run goes ahead without them
+		logger.FromContext(ctx).Error(err, "ignoring namespace Renovate config", "namespace", c.Namespace)
+	}
+	if len(dropped) > 0 {
+		logger.FromContext(ctx).Info("ignoring Renovate options namespaces may not override", "namespace", c.Namespace, "options", dropped)
+	}
+	builder.With(renovateconfig.LayerNamespace, namespaceConfig)
+
+	var hostRules []renovateconfig.HostRule
+	if registrySecret != nil && len(registrySecret.Data[corev1.DockerConfigJsonKey]) > 0 {
+		hostRules, err = c.GetHostRules(ctx, registrySecret)
+		if err != nil {
+			// Registry credentials are optional, the run goes ahead without them
+			logger.FromContext(ctx).Error(err, "ignoring registry secret host rules", "secret", registrySecret.Name)
+		} else if len(hostRules) > 0 {
+			builder.With(renovateconfig.LayerComponent, &renovateconfig.Config{HostRules: hostRules})
+		}
 	}
+	builder.With(renovateconfig.LayerComponent, renovateconfig.MirrorOverlay(registryMirrors(ctx, client)))
 
-	var config map[string]interface{}
-	if err := json.Unmarshal([]byte(baseConfig.Data["renovate.json"]), &config); err != nil {
-		return nil, fmt.Errorf("error unmarshaling Renovate config: %v", err)
+	registryHostRules, err := renovateconfig.RegistryHostRules(ctx, client, c.Namespace)
+	if err != nil {
+		// Private package registries are optional, the run goes ahead without them
+		logger.FromContext(ctx).Error(err, "ignoring package registry secrets", "namespace", c.Namespace)
 	}
-	var selfHostedConfig map[string]interface{}
-	if err := json.Unmarshal([]byte(baseConfig.Data["self_hosted.json"]), &selfHostedConfig); err != nil {
-		return nil, fmt.Errorf("error unmarshaling Renovate self-hosted config: %v", err)
+	if len(registryHostRules) > 0 {
+		builder.With(renovateconfig.LayerComponent, &renovateconfig.Config{HostRules: registryHostRules})
 	}
 
-	for k, v := range selfHostedConfig {
-		config[k] = v
+	if len(c.Presets) > 0 {
+		presetsConfig := config.Get().Presets
+		if !presetsConfig.Enabled {
+			return nil, &renovateconfig.SchemaError{Path: "/extends", Message: "MintMaker presets are disabled"}
+		}
+		presets, err := renovateconfig.LoadPresets(ctx, client, mmconst.MintMakerNamespaceName)
+		if err != nil {
+			return nil, err
+		}
+		presetConfig, err := renovateconfig.PresetOverlay(baseConfig, c.Presets, presets, presetsConfig.URL)
+		if err != nil {
+			return nil, err
+		}
+		builder.With(renovateconfig.LayerComponent, presetConfig)
 	}
+	return builder, nil
+}
 
-	renovateBaseConfigMutex.Lock()
-	renovateBaseConfig = config
-	renovateBaseConfigMutex.Unlock()
-	return config, nil
+// registryMirrors returns the configured registry mirrors followed by the ones
+// of the cluster's ImageDigestMirrorSets when enabled
+func registryMirrors(ctx context.Context, client client.Client) []renovateconfig.Mirror {
+	mirrorsConfig := config.Get().RegistryMirrors
+	var mirrors []renovateconfig.Mirror
+	for _, mirror := range mirrorsConfig.Mirrors {
+		mirrors = append(mirrors, renovateconfig.Mirror{Source: mirror.Source, Mirrors: mirror.Mirrors})
+	}
+	if mirrorsConfig.ImageDigestMirrorSets {
+		clusterMirrors, err := renovateconfig.ClusterMirrors(ctx, client)
+		if err != nil {
+			logger.FromContext(ctx).Error(err, "ignoring ImageDigestMirrorSets")
+		}
+		mirrors = append(mirrors, clusterMirrors...)
+	}
+	return mirrors
 }
 
 func getActivationKeyFromSecret(secret *corev1.Secret) (string, string, error) {
@@ -177,93 +309,93 @@ func getActivationKeyFromSecret(secret *corev1.Secret) (string, string, error) {
 	return activationKey, org, nil
 }
 
-// returns two strings, activationkey and org
-func (c *BaseComponent) GetRPMActivationKey(ctx context.Context, k8sClient client.Client) (string, string, error) {
+// credentialExpiryWarning is how far ahead an expiring SCM credential is logged
+const credentialExpiryWarning = 7 * 24 * time.Hour
 
-	defaultSecret := &corev1.Secret{}
-	if err := k8sClient.Get(ctx, types.NamespacedName{Namespace: c.Namespace, Name: "activation-key"}, defaultSecret); err != nil {
-		// If the Get() fails (secret doesn't exist), set defaultSecret to nil, so getActivationKeyFromSecret()
-		// can distinguish between "no secret" vs "invalid secret". An empty Secret object would pass
-		// the nil check but have empty metadata (including Name), resulting in confusing error messages.
-		defaultSecret = nil
+// ResolveSCMSecret returns the SCM secret that applies to the component and the decision path
+func (c *BaseComponent) ResolveSCMSecret(ctx context.Context, k8sClient client.Client) (*corev1.Secret, credentials.Decision, error) {
+	resolver := credentials.Resolver{
+		Client: k8sClient,
+		Kind:   credentials.KindSCM,
+		Accept: credentials.IsSCMSecret,
 	}
+	return resolver.Resolve(ctx, c.Namespace, c.Host, c.Repository)
+}
 
-	secretList := &corev1.SecretList{}
-	opts := client.ListOption(&client.MatchingLabels{
-		"appstudio.redhat.com/credentials": "rpm",
-		"appstudio.redhat.com/scm.host":    c.Host,
-	})
+// GetSCMCredential returns the credential of the component SCM secret. OAuth
+// access tokens are refreshed at tokenURL when needed.
+func (c *BaseComponent) GetSCMCredential(ctx context.Context, k8sClient client.Client, tokenURL string) (*credentials.SCMCredential, error) {
+	log := logger.FromContext(ctx)
 
-	// find secrets that have the following labels:
-	//	- "appstudio.redhat.com/credentials": "rpm"
-	//	- "appstudio.redhat.com/scm.host": <name of component host>
-	if err := k8sClient.List(ctx, secretList, client.InNamespace(c.Namespace), opts); err != nil {
-		return getActivationKeyFromSecret(defaultSecret)
+	secret, _, err := c.ResolveSCMSecret(ctx, k8sClient)
+	if err != nil {
+		return nil, err
 	}
-
-	// filtering to get Opaque secrets and data is not empty
-	secrets := bslices.Filter(secretList.Items, func(secret corev1.Secret) bool {
-		return secret.Type == corev1.SecretTypeOpaque && len(secret.Data) > 0
-	})
-	if len(secrets) == 0 {
-		return getActivationKeyFromSecret(defaultSecret)
+	cred, err := credentials.ParseSCMSecret(secret)
+	if err != nil {
+		return nil, err
 	}
-
-	// secrets only match with component's host
-	var hostOnlySecrets []corev1.Secret
-	// map of secret index and its best path intersections count, i.e. the count of path parts matched,
-	var potentialMatches = make(map[int]int, len(secrets))
-
-	for index, secret := range secrets {
-		repositoryLabel, exists := secret.Annotations["appstudio.redhat.com/scm.repository"]
-		if !exists || repositoryLabel == "" {
-			hostOnlySecrets = append(hostOnlySecrets, secret)
-			continue
-		}
-
-		secretRepositories := strings.Split(repositoryLabel, ",")
-		// trim possible prefix or suffix "/"
-		for i, repository := range secretRepositories {
-			secretRepositories[i] = strings.TrimPrefix(strings.TrimSuffix(repository, "/"), "/")
-		}
-
-		// this secret matches exactly the component's repository name
-		if slices.Contains(secretRepositories, c.Repository) {
-			return getActivationKeyFromSecret(&secret)
-		}
-
-		// no direct match, check for wildcard match, i.e. org/repo/* matches org/repo/foo, org/repo/bar, etc.
-		componentRepoParts := strings.Split(c.Repository, "/")
-
-		// find wildcard repositories
-		wildcardRepos := bslices.Filter(secretRepositories, func(s string) bool { return strings.HasSuffix(s, "*") })
-
-		for _, repo := range wildcardRepos {
-			i := bslices.Intersection(componentRepoParts, strings.Split(strings.TrimSuffix(repo, "*"), "/"))
-			if i > 0 && potentialMatches[index] < i {
-				// add whole secret index to potential matches
-				potentialMatches[index] = i
-			}
-		}
+	if err := credentials.RefreshOAuth(ctx, k8sClient, secret, cred, tokenURL, config.Get().OAuth.TokenMinValidity); err != nil {
+		return nil, err
 	}
 
-	if len(potentialMatches) == 0 {
-		if len(hostOnlySecrets) == 0 {
-			// no potential matches, no host matches, try default secret if it exists
-			return getActivationKeyFromSecret(defaultSecret)
+	if !cred.ExpiresAt.IsZero() {
+		mintmakermetrics.RecordSCMCredentialExpiration(c.Namespace, c.Host, cred.SecretName, string(cred.Type), cred.ExpiresAt)
+		// OAuth access tokens are short-lived by design and refreshed on demand
+		if cred.Type != credentials.CredentialTypeOAuth && time.Until(cred.ExpiresAt) < credentialExpiryWarning {
+			log.Info("SCM credential is about to expire", "namespace", c.Namespace, "secret", cred.SecretName, "expiresAt", cred.ExpiresAt)
 		}
-		// no potential matches, but we have host match secrets, return the first one
-		return getActivationKeyFromSecret(&hostOnlySecrets[0])
 	}
+	return cred, nil
+}
 
-	// some potential matches exist, find the best one
-	var bestIndex, bestCount int
-	for i, count := range potentialMatches {
-		if count > bestCount {
-			bestCount = count
-			bestIndex = i
+// ValidateSCMCredential is GetSCMCredential for pre-flight validation: a missing,
+// malformed, unrefreshable or expired credential yields a credentials.ValidationError
+func (c *BaseComponent) ValidateSCMCredential(ctx context.Context, k8sClient client.Client, tokenURL string) (*credentials.SCMCredential, error) {
+	secret, _, err := c.ResolveSCMSecret(ctx, k8sClient)
+	if errors.Is(err, credentials.ErrNoMatchingSecret) {
+		return nil, credentials.NewValidationError(credentials.ValidationReasonNotFound,
+			"no SCM secret for %s/%s in namespace %s", c.Host, c.Repository, c.Namespace)
+	} else if err != nil {
+		return nil, err
+	}
+	cred, err := credentials.ParseSCMSecret(secret)
+	if err != nil {
+		return nil, credentials.NewValidationError(credentials.ValidationReasonInvalid, "%v", err)
+	}
+	if err := credentials.RefreshOAuth(ctx, k8sClient, secret, cred, tokenURL, config.Get().OAuth.TokenMinValidity); err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) {
+			return nil, credentials.NewValidationError(credentials.ValidationReasonInvalid, "%v", err)
 		}
+		return nil, err
 	}
-	return getActivationKeyFromSecret(&secrets[bestIndex])
+	if err := credentials.CheckExpiry(cred.ExpiresAt, time.Now()); err != nil {
+		return nil, err
+	}
+	return cred, nil
+}
+
+// returns two strings, activationkey and org
+func (c *BaseComponent) GetRPMActivationKey(ctx context.Context, k8sClient client.Client) (string, string, error) {
+	secret, _ := c.resolveRPMActivationKeySecret(ctx, k8sClient)
+	return getActivationKeyFromSecret(secret)
+}
 
+// ExplainRPMActivationKey returns how the RPM activation key secret is chosen
+func (c *BaseComponent) ExplainRPMActivationKey(ctx context.Context, k8sClient client.Client) credentials.Decision {
+	_, decision := c.resolveRPMActivationKeySecret(ctx, k8sClient)
+	return decision
+}
+
+func (c *BaseComponent) resolveRPMActivationKeySecret(ctx context.Context, k8sClient client.Client) (*corev1.Secret, credentials.Decision) {
+	resolver := credentials.Resolver{
+		Client:        k8sClient,
+		Kind:          credentials.KindRPM,
+		Accept:        credentials.OfType(corev1.SecretTypeOpaque),
+		DefaultSecret: "activation-key",
+	}
+	// Errors are reflected in the decision, a nil secret is reported by getActivationKeyFromSecret()
+	secret, decision, _ := resolver.Resolve(ctx, c.Namespace, c.Host, c.Repository)
+	return secret, decision
 }
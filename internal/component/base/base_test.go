@@ -0,0 +1,143 @@
+package base
+
+import (
+	"context"
+	"encoding/base64"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/konflux-ci/mintmaker/internal/renovateconfig"
+)
+
+func dockerConfigSecret(dockerConfig string) *corev1.Secret {
+	return &corev1.Secret{
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(dockerConfig)},
+	}
+}
+
+func basicAuth(username, password string) string {
+	return base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+}
+
+func TestGetHostRules(t *testing.T) {
+	c := &BaseComponent{}
+
+	tests := []struct {
+		name      string
+		config    string
+		expected  []renovateconfig.HostRule
+		expectErr bool
+	}{
+		{
+			name:   "base64 auth",
+			config: `{"auths": {"quay.io": {"auth": "` + basicAuth("user", "pass:word") + `"}}}`,
+			expected: []renovateconfig.HostRule{
+				{MatchHost: "quay.io", HostType: "docker", Username: "user", Password: "pass:word"},
+			},
+		},
+		{
+			name:   "username and password fields",
+			config: `{"auths": {"registry.example.com": {"username": "user", "password": "secret"}}}`,
+			expected: []renovateconfig.HostRule{
+				{MatchHost: "registry.example.com", HostType: "docker", Username: "user", Password: "secret"},
+			},
+		},
+		{
+			name:   "registry token",
+			config: `{"auths": {"ghcr.io": {"auth": "` + basicAuth("user", "secret") + `", "registrytoken": "bearer"}}}`,
+			expected: []renovateconfig.HostRule{
+				{MatchHost: "ghcr.io", HostType: "docker", Token: "bearer"},
+			},
+		},
+		{
+			name:   "identity token is not a password",
+			config: `{"auths": {"example.azurecr.io": {"username": "00000000-0000-0000-0000-000000000000", "identitytoken": "refresh"}}}`,
+			expected: []renovateconfig.HostRule{
+				{MatchHost: "example.azurecr.io"},
+			},
+		},
+		{
+			name:   "identity token next to a password",
+			config: `{"auths": {"example.azurecr.io": {"auth": "` + basicAuth("user", "secret") + `", "identitytoken": "refresh"}}}`,
+			expected: []renovateconfig.HostRule{
+				{MatchHost: "example.azurecr.io", HostType: "docker", Username: "user", Password: "secret"},
+			},
+		},
+		{
+			name:   "entries without credentials only match their host",
+			config: `{"auths": {"quay.io/org": {"email": "user@example.com"}, "quay.io": {}}}`,
+			expected: []renovateconfig.HostRule{
+				{MatchHost: "quay.io"},
+				{MatchHost: "https://quay.io/v2/org"},
+			},
+		},
+		{
+			name:   "URL keys",
+			config: `{"auths": {"https://index.docker.io/v1/": {"auth": "` + basicAuth("hub", "secret") + `"}, "http://registry.local:5000": {"auth": "` + basicAuth("local", "secret") + `"}}}`,
+			expected: []renovateconfig.HostRule{
+				{MatchHost: "docker.io", HostType: "docker", Username: "hub", Password: "secret"},
+				{MatchHost: "registry.local:5000", HostType: "docker", Username: "local", Password: "secret"},
+			},
+		},
+		{
+			name: "path scoped auths from the least to the most specific",
+			config: `{"auths": {
+				"quay.io/org/repo": {"auth": "` + basicAuth("repo", "secret") + `"},
+				"quay.io/org": {"auth": "` + basicAuth("org", "secret") + `"},
+				"quay.io": {"auth": "` + basicAuth("registry", "secret") + `"},
+				"docker.io/library": {"auth": "` + basicAuth("library", "secret") + `"}
+			}}`,
+			expected: []renovateconfig.HostRule{
+				{MatchHost: "https://docker.io/v2/library", HostType: "docker", Username: "library", Password: "secret"},
+				{MatchHost: "quay.io", HostType: "docker", Username: "registry", Password: "secret"},
+				{MatchHost: "https://quay.io/v2/org", HostType: "docker", Username: "org", Password: "secret"},
+				{MatchHost: "https://quay.io/v2/org/repo", HostType: "docker", Username: "repo", Password: "secret"},
+			},
+		},
+		{
+			name: "invalid entries are skipped",
+			config: `{"auths": {
+				"quay.io": {"auth": "` + basicAuth("user", "secret") + `"},
+				"bad-base64.example.com": {"auth": "%%%"},
+				"no-delimiter.example.com": {"auth": "` + base64.StdEncoding.EncodeToString([]byte("user")) + `"},
+				"wrong-type.example.com": "auth"
+			}}`,
+			expected: []renovateconfig.HostRule{
+				{MatchHost: "quay.io", HostType: "docker", Username: "user", Password: "secret"},
+			},
+		},
+		{
+			name:      "invalid JSON",
+			config:    `{"auths": `,
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hostRules, err := c.GetHostRules(context.Background(), dockerConfigSecret(tc.config))
+			if tc.expectErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(hostRules, tc.expected) {
+				t.Errorf("expected host rules %+v, got %+v", tc.expected, hostRules)
+			}
+		})
+	}
+}
+
+func TestGetHostRulesWithoutDockerConfig(t *testing.T) {
+	c := &BaseComponent{}
+	if _, err := c.GetHostRules(context.Background(), &corev1.Secret{}); err == nil {
+		t.Error("expected an error for a secret without dockerconfigjson")
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/konflux-ci/mintmaker/internal/component/forgejo"
 	github "github.com/konflux-ci/mintmaker/internal/component/github"
 	gitlab "github.com/konflux-ci/mintmaker/internal/component/gitlab"
+	"github.com/konflux-ci/mintmaker/internal/credentials"
 	utils "github.com/konflux-ci/mintmaker/internal/utils"
 )
 
@@ -41,10 +42,13 @@ type GitComponent interface {
 	GetGitURL() string
 	GetRepository() string
 	GetToken() (string, error)
+	GetCredential() (*credentials.SCMCredential, error)
 	GetBranches() ([]string, error)
 	GetAPIEndpoint() string
 	GetRenovateConfig(*corev1.Secret, string) (string, error)
 	GetRPMActivationKey(context.Context, client.Client) (string, string, error)
+	ExplainCredentials() ([]credentials.Decision, error)
+	ValidateCredentials() (credentials.Validation, error)
 }
 
 func NewGitComponent(ctx context.Context, comp *appstudiov1alpha1.Component, client client.Client) (GitComponent, error) {
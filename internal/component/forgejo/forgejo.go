@@ -16,10 +16,10 @@ package forgejo
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
-	"slices"
 	"strings"
 
 	gitea "code.gitea.io/sdk/gitea"
@@ -29,7 +29,8 @@ import (
 	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
 
 	"github.com/konflux-ci/mintmaker/internal/component/base"
-	bslices "github.com/konflux-ci/mintmaker/internal/slices"
+	"github.com/konflux-ci/mintmaker/internal/credentials"
+	"github.com/konflux-ci/mintmaker/internal/renovateconfig"
 	"github.com/konflux-ci/mintmaker/internal/utils"
 )
 
@@ -65,6 +66,7 @@ func NewComponent(ctx context.Context, comp *appstudiov1alpha1.Component, k8sCli
 			Repository:    repository,
 			Versions:      versions,
 			OldCRDVersion: oldCRDVersion,
+			Presets:       renovateconfig.ParsePresetNames(comp.Annotations[renovateconfig.PresetsAnnotation]),
 		},
 		client: k8sClient,
 		ctx:    ctx,
@@ -104,85 +106,57 @@ func (c *Component) GetBranches() ([]string, error) {
 	return branches, nil
 }
 
-func (c *Component) lookupSecret() (*corev1.Secret, error) {
-
-	secretList := &corev1.SecretList{}
-	opts := client.ListOption(&client.MatchingLabels{
-		"appstudio.redhat.com/credentials": "scm",
-		"appstudio.redhat.com/scm.host":    c.Host,
-	})
+// GetCredential returns the SCM credential of the component, refreshing
+// OAuth access tokens when needed
+func (c *Component) GetCredential() (*credentials.SCMCredential, error) {
+	return c.GetSCMCredential(c.ctx, c.client, c.scheme()+"://"+c.Host+"/login/oauth/access_token")
+}
 
-	// find secrets that have the following labels:
-	//	- "appstudio.redhat.com/credentials": "scm"
-	//	- "appstudio.redhat.com/scm.host": <name of component host>
-	if err := c.client.List(c.ctx, secretList, client.InNamespace(c.Namespace), opts); err != nil {
-		return nil, fmt.Errorf("failed to list scm secrets in namespace %s: %w", c.Namespace, err)
+// ExplainCredentials returns how the SCM and RPM secrets of the component are chosen
+func (c *Component) ExplainCredentials() ([]credentials.Decision, error) {
+	_, scmDecision, err := c.ResolveSCMSecret(c.ctx, c.client)
+	if err != nil && !errors.Is(err, credentials.ErrNoMatchingSecret) {
+		return nil, err
 	}
+	return []credentials.Decision{scmDecision, c.ExplainRPMActivationKey(c.ctx, c.client)}, nil
+}
 
-	// filtering to get BasicAuth secrets and data is not empty
-	secrets := bslices.Filter(secretList.Items, func(secret corev1.Secret) bool {
-		return secret.Type == corev1.SecretTypeBasicAuth && len(secret.Data) > 0
-	})
-	if len(secrets) == 0 {
-		return nil, fmt.Errorf("no secrets available for git host %s", c.Host)
+// ValidateCredentials checks that the component credential is accepted, not
+// expired and grants push access to the repository. Forgejo doesn't report the
+// scopes of the token in use, so missing scopes show up as missing access.
+func (c *Component) ValidateCredentials() (credentials.Validation, error) {
+	cred, err := c.ValidateSCMCredential(c.ctx, c.client, c.scheme()+"://"+c.Host+"/login/oauth/access_token")
+	if err != nil {
+		return credentials.Validation{}, err
 	}
-
-	// secrets only match with component's host
-	var hostOnlySecrets []corev1.Secret
-	// map of secret index and its best path intersections count, i.e. the count of path parts matched,
-	var potentialMatches = make(map[int]int, len(secrets))
-
-	for index, secret := range secrets {
-		repositoryAnnotation, exists := secret.Annotations["appstudio.redhat.com/scm.repository"]
-		if !exists || repositoryAnnotation == "" {
-			hostOnlySecrets = append(hostOnlySecrets, secret)
-			continue
-		}
-
-		secretRepositories := strings.Split(repositoryAnnotation, ",")
-		// trim possible prefix or suffix "/"
-		for i, repository := range secretRepositories {
-			secretRepositories[i] = strings.TrimPrefix(strings.TrimSuffix(repository, "/"), "/")
-		}
-
-		// this secret matches exactly the component's repository name
-		if slices.Contains(secretRepositories, c.Repository) {
-			return &secret, nil
-		}
-
-		// no direct match, check for wildcard match, i.e. org/repo/* matches org/repo/foo, org/repo/bar, etc.
-		componentRepoParts := strings.Split(c.Repository, "/")
-
-		// find wildcard repositories
-		wildcardRepos := bslices.Filter(secretRepositories, func(s string) bool { return strings.HasSuffix(s, "*") })
-
-		for _, repo := range wildcardRepos {
-			i := bslices.Intersection(componentRepoParts, strings.Split(strings.TrimSuffix(repo, "*"), "/"))
-			if i > 0 && potentialMatches[index] < i {
-				// add whole secret index to potential matches
-				potentialMatches[index] = i
-			}
-		}
+	owner, repo, err := c.getOwnerAndRepo()
+	if err != nil {
+		return credentials.Validation{}, err
 	}
-
-	if len(potentialMatches) == 0 {
-		if len(hostOnlySecrets) == 0 {
-			// no potential matches, no host matches, nothing to return
-			return nil, fmt.Errorf("no secrets available for component")
-		}
-		// no potential matches, but we have host match secrets, return the first one
-		return &hostOnlySecrets[0], nil
+	giteaClient, err := c.newClient(cred.Token)
+	if err != nil {
+		return credentials.Validation{}, err
 	}
 
-	// some potential matches exist, find the best one
-	var bestIndex, bestCount int
-	for i, count := range potentialMatches {
-		if count > bestCount {
-			bestCount = count
-			bestIndex = i
+	repository, resp, err := giteaClient.GetRepo(owner, repo)
+	if err != nil {
+		if resp != nil {
+			switch resp.StatusCode {
+			case http.StatusUnauthorized:
+				return credentials.Validation{}, credentials.NewValidationError(credentials.ValidationReasonInvalid,
+					"token of secret %s was rejected by %s", cred.SecretName, c.Host)
+			case http.StatusForbidden, http.StatusNotFound:
+				return credentials.Validation{}, credentials.NewValidationError(credentials.ValidationReasonRepositoryAccess,
+					"repository %s is not accessible with secret %s", c.Repository, cred.SecretName)
+			}
 		}
+		return credentials.Validation{}, fmt.Errorf("failed to get repo: %w", err)
+	}
+	if repository.Permissions != nil && !repository.Permissions.Push {
+		return credentials.Validation{}, credentials.NewValidationError(credentials.ValidationReasonRepositoryAccess,
+			"secret %s can't push to repository %s", cred.SecretName, c.Repository)
 	}
-	return &secrets[bestIndex], nil
+	return credentials.Validation{ExpiresAt: cred.ExpiresAt}, nil
 }
 
 func (c *Component) scheme() string {
@@ -197,12 +171,11 @@ func (c *Component) scheme() string {
 }
 
 func (c *Component) GetToken() (string, error) {
-
-	secret, err := c.lookupSecret()
+	cred, err := c.GetCredential()
 	if err != nil {
 		return "", err
 	}
-	return string(secret.Data[corev1.BasicAuthPasswordKey]), nil
+	return cred.Token, nil
 }
 
 func (c *Component) GetAPIEndpoint() string {
@@ -231,37 +204,29 @@ func (c *Component) getDefaultBranch() (string, error) {
 }
 
 func (c *Component) GetRenovateConfig(registrySecret *corev1.Secret, currentBranch string) (string, error) {
-	baseConfig, err := c.GetRenovateBaseConfig(c.ctx, c.client)
+	builder, err := c.NewRenovateConfigBuilder(c.ctx, c.client, registrySecret)
 	if err != nil {
 		return "", err
 	}
 
-	// Add component-specific hostRules if registrySecret is provided
-	if registrySecret != nil {
-		hostRules, err := c.GetHostRules(c.ctx, registrySecret)
-		if err == nil && len(hostRules) > 0 {
-			baseConfig["hostRules"] = hostRules
-		}
-	}
-
-	baseConfig["platform"] = c.Platform
-	baseConfig["endpoint"] = c.GetAPIEndpoint()
 	// We don't need to set a username or gitAuthor for Forgejo; auth is via token.
-	baseConfig["username"] = ""
-	baseConfig["gitAuthor"] = ""
-
-	repo := map[string]interface{}{
-		"baseBranchPatterns": []string{currentBranch},
-		"repository":         c.Repository,
+	platformConfig := &renovateconfig.Config{
+		Platform:  c.Platform,
+		Endpoint:  c.GetAPIEndpoint(),
+		Username:  new(string),
+		GitAuthor: new(string),
 	}
-	baseConfig["repositories"] = []interface{}{repo}
-
-	updatedConfig, err := json.MarshalIndent(baseConfig, "", "  ")
-	if err != nil {
-		return "", fmt.Errorf("error marshaling updated Renovate config: %v", err)
+	// SSH deploy keys are used for cloning, the API token only for API calls
+	if cred, err := c.GetCredential(); err == nil && cred.Type == credentials.CredentialTypeSSH {
+		platformConfig.GitURL = "ssh"
 	}
 
-	return string(updatedConfig), nil
+	return builder.
+		With(renovateconfig.LayerPlatform, platformConfig).
+		With(renovateconfig.LayerRun, &renovateconfig.Config{
+			Repositories: []renovateconfig.Repository{{Repository: c.Repository, BaseBranchPatterns: []string{currentBranch}}},
+		}).
+		Build().ValidatedJSON()
 }
 
 func (c *Component) getClient() (*gitea.Client, error) {
@@ -269,7 +234,10 @@ func (c *Component) getClient() (*gitea.Client, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get Forgejo token: %w", err)
 	}
+	return c.newClient(token)
+}
 
+func (c *Component) newClient(token string) (*gitea.Client, error) {
 	baseURL := c.scheme() + "://" + c.Host
 	giteaClient, err := gitea.NewClient(baseURL, gitea.SetToken(token))
 	if err != nil {
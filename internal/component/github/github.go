@@ -17,6 +17,7 @@ package github
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -35,6 +36,8 @@ import (
 
 	"github.com/konflux-ci/mintmaker/internal/component/base"
 	"github.com/konflux-ci/mintmaker/internal/config"
+	"github.com/konflux-ci/mintmaker/internal/credentials"
+	"github.com/konflux-ci/mintmaker/internal/renovateconfig"
 	"github.com/konflux-ci/mintmaker/internal/utils"
 )
 
@@ -50,6 +53,9 @@ var (
 	ghAppSlug                   string
 )
 
+// errNotInstalled is returned when no GitHub App installation covers the repository
+var errNotInstalled = errors.New("not found in any GitHub App installation")
+
 type AppInstallation struct {
 	InstallationID int64
 	Repositories   []string
@@ -114,6 +120,7 @@ func NewComponent(ctx context.Context, comp *appstudiov1alpha1.Component, client
 			Repository:    repository,
 			Versions:      versions,
 			OldCRDVersion: oldCRDVersion,
+			Presets:       renovateconfig.ParsePresetNames(comp.Annotations[renovateconfig.PresetsAnnotation]),
 		},
 		AppID:         appID,
 		AppPrivateKey: appPrivateKey,
@@ -178,18 +185,118 @@ func (c *Component) getInstallationID() (int64, error) {
 		}
 	}
 	if !found {
-		return 0, fmt.Errorf("repository %s not found in any GitHub App installation", c.Repository)
+		return 0, fmt.Errorf("repository %s %w", c.Repository, errNotInstalled)
 	}
 	return installationID, nil
 }
 
+// ExplainCredentials returns how the SCM and RPM credentials of the component are chosen.
+// GitHub components always use a GitHub App installation token instead of a secret.
+func (c *Component) ExplainCredentials() ([]credentials.Decision, error) {
+	scmDecision := credentials.Decision{Kind: credentials.KindSCM}
+	installationID, err := c.getInstallationID()
+	if err != nil {
+		scmDecision.Stepf("repository %s is not covered by the GitHub App: %v", c.Repository, err)
+		scmDecision.Choose("", credentials.ReasonNoMatch)
+	} else {
+		scmDecision.Stepf("repository %s is covered by GitHub App installation %d", c.Repository, installationID)
+		scmDecision.Choose("pipelines-as-code-secret", credentials.ReasonGitHubApp)
+	}
+	return []credentials.Decision{scmDecision, c.ExplainRPMActivationKey(c.ctx, c.client)}, nil
+}
+
+// ValidateCredentials checks that a GitHub App installation covers the
+// repository and that a token can be minted for it. Installation tokens are
+// minted on demand, so they never report an expiry.
+func (c *Component) ValidateCredentials() (credentials.Validation, error) {
+	if _, err := c.getTokenInfo(); err != nil {
+		if errors.Is(err, errNotInstalled) {
+			return credentials.Validation{}, credentials.NewValidationError(credentials.ValidationReasonRepositoryAccess,
+				"repository %s %v", c.Repository, errNotInstalled)
+		}
+		var ghErr *github.ErrorResponse
+		if errors.As(err, &ghErr) && ghErr.Response != nil && ghErr.Response.StatusCode == http.StatusUnprocessableEntity {
+			// The installation doesn't grant the permissions requested for the token
+			return credentials.Validation{}, credentials.NewValidationError(credentials.ValidationReasonMissingScopes,
+				"GitHub App installation can't grant the permissions Renovate needs on %s: %v", c.Repository, ghErr.Message)
+		}
+		return credentials.Validation{}, err
+	}
+	return credentials.Validation{}, nil
+}
+
+// renovateTokenPermissions are the only permissions Renovate needs to maintain
+// dependency update pull requests and the dependency dashboard issue
+var renovateTokenPermissions = github.InstallationPermissions{
+	Checks:       github.Ptr("read"),
+	Contents:     github.Ptr("write"),
+	Issues:       github.Ptr("write"),
+	Metadata:     github.Ptr("read"),
+	PullRequests: github.Ptr("write"),
+	Statuses:     github.Ptr("write"),
+	Workflows:    github.Ptr("write"),
+}
+
+// installationTokenOptions restricts an installation token to the component's
+// repository and the permissions Renovate needs
+func (c *Component) installationTokenOptions() (*github.InstallationTokenOptions, error) {
+	_, repo, err := c.getOwnerAndRepo()
+	if err != nil {
+		return nil, err
+	}
+	permissions := renovateTokenPermissions
+	return &github.InstallationTokenOptions{
+		Repositories: []string{repo},
+		Permissions:  &permissions,
+	}, nil
+}
+
+// tokenCacheKey identifies a token by installation and by the scope it was
+// requested with, so tokens for different repositories are never shared
+func tokenCacheKey(installationID int64, opts *github.InstallationTokenOptions) (string, error) {
+	scope, err := json.Marshal(opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize token scope: %w", err)
+	}
+	return fmt.Sprintf("installation_%d_%s", installationID, scope), nil
+}
+
 func (c *Component) GetToken() (string, error) {
+	tokenInfo, err := c.getTokenInfo()
+	if err != nil {
+		return "", err
+	}
+	return tokenInfo.Token, nil
+}
+
+// GetCredential returns the GitHub App installation token as an SCM credential
+func (c *Component) GetCredential() (*credentials.SCMCredential, error) {
+	tokenInfo, err := c.getTokenInfo()
+	if err != nil {
+		return nil, err
+	}
+	return &credentials.SCMCredential{
+		Type:       credentials.CredentialTypeAccessToken,
+		SecretName: "pipelines-as-code-secret",
+		Token:      tokenInfo.Token,
+		ExpiresAt:  tokenInfo.ExpiresAt,
+	}, nil
+}
+
+func (c *Component) getTokenInfo() (TokenInfo, error) {
 	installationID, err := c.getInstallationID()
 	if err != nil {
-		return "", fmt.Errorf("failed to get installation ID: %w", err)
+		return TokenInfo{}, fmt.Errorf("failed to get installation ID: %w", err)
 	}
 
-	tokenKey := fmt.Sprintf("installation_%d", installationID)
+	opts, err := c.installationTokenOptions()
+	if err != nil {
+		return TokenInfo{}, fmt.Errorf("failed to get token scope: %w", err)
+	}
+	tokenKey, err := tokenCacheKey(installationID, opts)
+	if err != nil {
+		return TokenInfo{}, err
+	}
 	cfg := config.Get().GitHub
 	if ghAppInstallationTokenCache.entries == nil {
 		ghAppInstallationTokenCache.entries = make(map[string]TokenInfo)
@@ -197,28 +304,28 @@ func (c *Component) GetToken() (string, error) {
 
 	// when token exists and within the threshold, a valid token is returned
 	if tokenInfo, ok := ghAppInstallationTokenCache.Get(tokenKey); ok {
-		return tokenInfo.Token, nil
-	}
-	// when token doesn't exist or not within the threshold, we generate a new token and update the cache
-	itr, err := ghinstallation.New(
-		http.DefaultTransport,
-		c.AppID,
-		installationID,
-		c.AppPrivateKey,
-	)
+		return tokenInfo, nil
+	}
+	// when token doesn't exist or not within the threshold, we generate a new token
+	// scoped to the component repository and update the cache
+	itr, err := ghinstallation.NewAppsTransport(http.DefaultTransport, c.AppID, c.AppPrivateKey)
+	if err != nil {
+		return TokenInfo{}, fmt.Errorf("error creating app transport: %w", err)
+	}
+	appClient, err := github.NewClient(github.WithHTTPClient(&http.Client{Transport: itr}))
 	if err != nil {
-		return "", fmt.Errorf("error creating installation transport: %w", err)
+		return TokenInfo{}, fmt.Errorf("failed to create GitHub client: %w", err)
 	}
-	token, err := itr.Token(context.Background())
+	token, _, err := appClient.Apps.CreateInstallationToken(context.Background(), installationID, opts)
 	if err != nil {
-		return "", fmt.Errorf("error getting installation token: %w", err)
+		return TokenInfo{}, fmt.Errorf("error getting installation token: %w", err)
 	}
 	tokenInfo := TokenInfo{
-		Token: token, ExpiresAt: time.Now().Add(cfg.TokenTTL),
+		Token: token.GetToken(), ExpiresAt: time.Now().Add(cfg.TokenTTL),
 	}
 	ghAppInstallationTokenCache.Set(tokenKey, tokenInfo)
 
-	return tokenInfo.Token, nil
+	return tokenInfo, nil
 }
 
 func (c *Component) getAppInstallations() ([]AppInstallation, error) {
@@ -390,18 +497,10 @@ func (c *Component) getUserId(username string) (int64, error) {
 }
 
 func (c *Component) GetRenovateConfig(registrySecret *corev1.Secret, currentBranch string) (string, error) {
-	baseConfig, err := c.GetRenovateBaseConfig(c.ctx, c.client)
+	builder, err := c.NewRenovateConfigBuilder(c.ctx, c.client, registrySecret)
 	if err != nil {
 		return "", err
 	}
-
-	// Add component-specific hostRules if registrySecret is provided
-	if registrySecret != nil {
-		hostRules, err := c.GetHostRules(c.ctx, registrySecret)
-		if err == nil && len(hostRules) > 0 {
-			baseConfig["hostRules"] = hostRules
-		}
-	}
 	appSlug, err := c.getAppSlug()
 	if err != nil {
 		return "", err
@@ -411,23 +510,20 @@ func (c *Component) GetRenovateConfig(registrySecret *corev1.Secret, currentBran
 		return "", err
 	}
 
-	baseConfig["gitAuthor"] = fmt.Sprintf("%s <%d+%s[bot]@users.noreply.github.com>", appSlug, botId, appSlug)
-	baseConfig["username"] = fmt.Sprintf("%s[bot]", appSlug)
-	baseConfig["platform"] = c.Platform
-	baseConfig["endpoint"] = c.GetAPIEndpoint()
-
-	// TODO: perhaps in the future let's validate all these values
-
-	repo := map[string]interface{}{
-		"baseBranchPatterns": []string{currentBranch},
-		"repository":         c.Repository,
-	}
-	baseConfig["repositories"] = []interface{}{repo}
-	updatedConfig, err := json.MarshalIndent(baseConfig, "", "  ")
-	if err != nil {
-		return "", err
-	}
-	return string(updatedConfig), nil
+	gitAuthor := fmt.Sprintf("%s <%d+%s[bot]@users.noreply.github.com>", appSlug, botId, appSlug)
+	username := fmt.Sprintf("%s[bot]", appSlug)
+
+	return builder.
+		With(renovateconfig.LayerPlatform, &renovateconfig.Config{
+			Platform:  c.Platform,
+			Endpoint:  c.GetAPIEndpoint(),
+			Username:  &username,
+			GitAuthor: &gitAuthor,
+		}).
+		With(renovateconfig.LayerRun, &renovateconfig.Config{
+			Repositories: []renovateconfig.Repository{{Repository: c.Repository, BaseBranchPatterns: []string{currentBranch}}},
+		}).
+		Build().ValidatedJSON()
 }
 
 func (c *Component) getClient() (*github.Client, error) {
@@ -0,0 +1,70 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"github.com/google/go-github/v90/github"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/mintmaker/internal/component/base"
+)
+
+var _ = Describe("Installation token scope", func() {
+	newComponent := func(repository string) *Component {
+		return &Component{BaseComponent: base.BaseComponent{Repository: repository}}
+	}
+
+	It("should restrict the token to the component repository", func() {
+		opts, err := newComponent("konflux-ci/mintmaker").installationTokenOptions()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(opts.Repositories).To(Equal([]string{"mintmaker"}))
+		Expect(opts.Permissions.GetContents()).To(Equal("write"))
+		Expect(opts.Permissions.GetPullRequests()).To(Equal("write"))
+		Expect(opts.Permissions.Administration).To(BeNil())
+	})
+
+	It("should fail for an invalid repository", func() {
+		_, err := newComponent("mintmaker").installationTokenOptions()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should not share the permission set between components", func() {
+		opts, err := newComponent("konflux-ci/mintmaker").installationTokenOptions()
+		Expect(err).ToNot(HaveOccurred())
+		opts.Permissions.Administration = github.Ptr("write")
+		Expect(renovateTokenPermissions.Administration).To(BeNil())
+	})
+
+	It("should use distinct cache keys per repository within an installation", func() {
+		first, err := newComponent("konflux-ci/mintmaker").installationTokenOptions()
+		Expect(err).ToNot(HaveOccurred())
+		second, err := newComponent("konflux-ci/build-service").installationTokenOptions()
+		Expect(err).ToNot(HaveOccurred())
+
+		firstKey, err := tokenCacheKey(1, first)
+		Expect(err).ToNot(HaveOccurred())
+		secondKey, err := tokenCacheKey(1, second)
+		Expect(err).ToNot(HaveOccurred())
+		otherInstallationKey, err := tokenCacheKey(2, first)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(firstKey).ToNot(Equal(secondKey))
+		Expect(firstKey).ToNot(Equal(otherInstallationKey))
+		sameKey, err := tokenCacheKey(1, first)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sameKey).To(Equal(firstKey))
+	})
+})
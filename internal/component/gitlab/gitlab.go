@@ -16,11 +16,11 @@ package gitlab
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
-	"slices"
-	"strings"
+	"time"
 
 	gitlab "gitlab.com/gitlab-org/api/client-go/v2"
 	corev1 "k8s.io/api/core/v1"
@@ -29,7 +29,8 @@ import (
 	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
 
 	"github.com/konflux-ci/mintmaker/internal/component/base"
-	bslices "github.com/konflux-ci/mintmaker/internal/slices"
+	"github.com/konflux-ci/mintmaker/internal/credentials"
+	"github.com/konflux-ci/mintmaker/internal/renovateconfig"
 	"github.com/konflux-ci/mintmaker/internal/utils"
 )
 
@@ -72,6 +73,7 @@ func NewComponent(ctx context.Context, comp *appstudiov1alpha1.Component, client
 			Repository:    repository,
 			Versions:      versions,
 			OldCRDVersion: oldCRDVersion,
+			Presets:       renovateconfig.ParsePresetNames(comp.Annotations[renovateconfig.PresetsAnnotation]),
 		},
 		client: client,
 		ctx:    ctx,
@@ -107,94 +109,109 @@ func (c *Component) GetBranches() ([]string, error) {
 	return branches, nil
 }
 
-func (c *Component) lookupSecret() (*corev1.Secret, error) {
-
-	secretList := &corev1.SecretList{}
-	opts := client.ListOption(&client.MatchingLabels{
-		"appstudio.redhat.com/credentials": "scm",
-		"appstudio.redhat.com/scm.host":    c.Host,
-	})
-
-	// find secrets that have the following labels:
-	//	- "appstudio.redhat.com/credentials": "scm"
-	//	- "appstudio.redhat.com/scm.host": <name of component host>
-	if err := c.client.List(c.ctx, secretList, client.InNamespace(c.Namespace), opts); err != nil {
-		return nil, fmt.Errorf("failed to list scm secrets in namespace %s: %w", c.Namespace, err)
+// GetCredential returns the SCM credential of the component, refreshing
+// OAuth access tokens when needed
+func (c *Component) GetCredential() (*credentials.SCMCredential, error) {
+	baseURL, err := c.baseURL()
+	if err != nil {
+		return nil, err
 	}
+	return c.GetSCMCredential(c.ctx, c.client, baseURL+"/oauth/token")
+}
 
-	// filtering to get BasicAuth secrets and data is not empty
-	secrets := bslices.Filter(secretList.Items, func(secret corev1.Secret) bool {
-		return secret.Type == corev1.SecretTypeBasicAuth && len(secret.Data) > 0
-	})
-	if len(secrets) == 0 {
-		return nil, fmt.Errorf("no secrets available for git host %s", c.Host)
+// ExplainCredentials returns how the SCM and RPM secrets of the component are chosen
+func (c *Component) ExplainCredentials() ([]credentials.Decision, error) {
+	_, scmDecision, err := c.ResolveSCMSecret(c.ctx, c.client)
+	if err != nil && !errors.Is(err, credentials.ErrNoMatchingSecret) {
+		return nil, err
 	}
+	return []credentials.Decision{scmDecision, c.ExplainRPMActivationKey(c.ctx, c.client)}, nil
+}
 
-	// secrets only match with component's host
-	var hostOnlySecrets []corev1.Secret
-	// map of secret index and its best path intersections count, i.e. the count of path parts matched,
-	var potentialMatches = make(map[int]int, len(secrets))
+// renovateScopes are the token scopes Renovate needs to push branches and open merge requests
+var renovateScopes = []string{"api"}
 
-	for index, secret := range secrets {
-		repositoryAnnotation, exists := secret.Annotations["appstudio.redhat.com/scm.repository"]
-		if !exists || repositoryAnnotation == "" {
-			hostOnlySecrets = append(hostOnlySecrets, secret)
-			continue
-		}
+// ValidateCredentials checks that the component credential is active, not
+// expired, has the scopes Renovate needs and grants push access to the project
+func (c *Component) ValidateCredentials() (credentials.Validation, error) {
+	baseURL, err := c.baseURL()
+	if err != nil {
+		return credentials.Validation{}, err
+	}
+	cred, err := c.ValidateSCMCredential(c.ctx, c.client, baseURL+"/oauth/token")
+	if err != nil {
+		return credentials.Validation{}, err
+	}
+	client, err := c.newClient(cred)
+	if err != nil {
+		return credentials.Validation{}, err
+	}
+	validation := credentials.Validation{ExpiresAt: cred.ExpiresAt}
 
-		secretRepositories := strings.Split(repositoryAnnotation, ",")
-		// trim possible prefix or suffix "/"
-		for i, repository := range secretRepositories {
-			secretRepositories[i] = strings.TrimPrefix(strings.TrimSuffix(repository, "/"), "/")
+	// OAuth applications have no personal access token to inspect
+	if cred.Type != credentials.CredentialTypeOAuth {
+		token, resp, err := client.PersonalAccessTokens.GetSinglePersonalAccessToken()
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+				return credentials.Validation{}, credentials.NewValidationError(credentials.ValidationReasonInvalid,
+					"token of secret %s was rejected by %s", cred.SecretName, c.Host)
+			}
+			return credentials.Validation{}, fmt.Errorf("failed to get GitLab token details: %w", err)
 		}
-
-		// this secret matches exactly the component's repository name
-		if slices.Contains(secretRepositories, c.Repository) {
-			return &secret, nil
+		if token.ExpiresAt != nil {
+			validation.ExpiresAt = time.Time(*token.ExpiresAt)
 		}
-
-		// no direct match, check for wildcard match, i.e. org/repo/* matches org/repo/foo, org/repo/bar, etc.
-		componentRepoParts := strings.Split(c.Repository, "/")
-
-		// find wildcard repositories
-		wildcardRepos := bslices.Filter(secretRepositories, func(s string) bool { return strings.HasSuffix(s, "*") })
-
-		for _, repo := range wildcardRepos {
-			i := bslices.Intersection(componentRepoParts, strings.Split(strings.TrimSuffix(repo, "*"), "/"))
-			if i > 0 && potentialMatches[index] < i {
-				// add whole secret index to potential matches
-				potentialMatches[index] = i
-			}
+		if err := credentials.CheckExpiry(validation.ExpiresAt, time.Now()); err != nil {
+			return credentials.Validation{}, err
+		}
+		if token.Revoked || !token.Active {
+			return credentials.Validation{}, credentials.NewValidationError(credentials.ValidationReasonInvalid,
+				"token of secret %s is revoked or inactive", cred.SecretName)
+		}
+		if err := credentials.CheckScopes(token.Scopes, renovateScopes); err != nil {
+			return credentials.Validation{}, err
 		}
+		validation.Scopes = token.Scopes
 	}
 
-	if len(potentialMatches) == 0 {
-		if len(hostOnlySecrets) == 0 {
-			// no potential matches, no host matches, nothing to return
-			return nil, fmt.Errorf("no secrets available for component")
+	project, resp, err := client.Projects.GetProject(c.Repository, nil)
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden) {
+			return credentials.Validation{}, credentials.NewValidationError(credentials.ValidationReasonRepositoryAccess,
+				"project %s is not accessible with secret %s", c.Repository, cred.SecretName)
 		}
-		// no potential matches, but we have host match secrets, return the first one
-		return &hostOnlySecrets[0], nil
+		return credentials.Validation{}, fmt.Errorf("failed to get GitLab project: %w", err)
+	}
+	if level, ok := accessLevel(project); ok && level < gitlab.DeveloperPermissions {
+		return credentials.Validation{}, credentials.NewValidationError(credentials.ValidationReasonRepositoryAccess,
+			"secret %s has access level %d on project %s, pushing branches requires %d",
+			cred.SecretName, level, c.Repository, gitlab.DeveloperPermissions)
 	}
+	return validation, nil
+}
 
-	// some potential matches exist, find the best one
-	var bestIndex, bestCount int
-	for i, count := range potentialMatches {
-		if count > bestCount {
-			bestCount = count
-			bestIndex = i
-		}
+// accessLevel returns the highest project or group access level of the token user
+func accessLevel(project *gitlab.Project) (gitlab.AccessLevelValue, bool) {
+	if project.Permissions == nil {
+		return 0, false
+	}
+	var level gitlab.AccessLevelValue
+	found := false
+	if access := project.Permissions.ProjectAccess; access != nil {
+		level, found = max(level, access.AccessLevel), true
+	}
+	if access := project.Permissions.GroupAccess; access != nil {
+		level, found = max(level, access.AccessLevel), true
 	}
-	return &secrets[bestIndex], nil
+	return level, found
 }
 
 func (c *Component) GetToken() (string, error) {
-
-	secret, err := c.lookupSecret()
+	cred, err := c.GetCredential()
 	if err != nil {
 		return "", err
 	}
-	return string(secret.Data[corev1.BasicAuthPasswordKey]), nil
+	return cred.Token, nil
 }
 
 func (c *Component) GetAPIEndpoint() string {
@@ -219,52 +236,58 @@ func (c *Component) getDefaultBranch() (string, error) {
 }
 
 func (c *Component) GetRenovateConfig(registrySecret *corev1.Secret, currentBranch string) (string, error) {
-	baseConfig, err := c.GetRenovateBaseConfig(c.ctx, c.client)
+	builder, err := c.NewRenovateConfigBuilder(c.ctx, c.client, registrySecret)
 	if err != nil {
 		return "", err
 	}
 
-	// Add component-specific hostRules if registrySecret is provided
-	if registrySecret != nil {
-		hostRules, err := c.GetHostRules(c.ctx, registrySecret)
-		if err == nil && len(hostRules) > 0 {
-			baseConfig["hostRules"] = hostRules
-		}
-	}
-
-	baseConfig["platform"] = c.Platform
-	baseConfig["endpoint"] = c.GetAPIEndpoint()
 	// We don't need to set a username or gitAuthor for gitlab, since this is tight to a token
-	baseConfig["username"] = ""
-	baseConfig["gitAuthor"] = ""
-
-	// TODO: perhaps in the future let's validate all these values
-
-	repo := map[string]interface{}{
-		"baseBranchPatterns": []string{currentBranch},
-		"repository":         c.Repository,
+	platformConfig := &renovateconfig.Config{
+		Platform:  c.Platform,
+		Endpoint:  c.GetAPIEndpoint(),
+		Username:  new(string),
+		GitAuthor: new(string),
+	}
+	// SSH deploy keys are used for cloning, the API token only for API calls
+	if cred, err := c.GetCredential(); err == nil && cred.Type == credentials.CredentialTypeSSH {
+		platformConfig.GitURL = "ssh"
 	}
-	baseConfig["repositories"] = []interface{}{repo}
 
-	updatedConfig, err := json.MarshalIndent(baseConfig, "", "  ")
+	return builder.
+		With(renovateconfig.LayerPlatform, platformConfig).
+		With(renovateconfig.LayerRun, &renovateconfig.Config{
+			Repositories: []renovateconfig.Repository{{Repository: c.Repository, BaseBranchPatterns: []string{currentBranch}}},
+		}).
+		Build().ValidatedJSON()
+}
+
+func (c *Component) baseURL() (string, error) {
+	u, err := url.Parse(c.GitURL)
 	if err != nil {
-		return "", fmt.Errorf("error marshaling updated Renovate config: %v", err)
+		return "", fmt.Errorf("failed to parse git url: %w", err)
 	}
-
-	return string(updatedConfig), nil
+	return u.Scheme + "://" + c.Host, nil
 }
 
 func (c *Component) getClient() (*gitlab.Client, error) {
-	token, err := c.GetToken()
+	cred, err := c.GetCredential()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get GitLab token: %w", err)
 	}
-	u, err := url.Parse(c.GitURL)
+	return c.newClient(cred)
+}
+
+func (c *Component) newClient(cred *credentials.SCMCredential) (*gitlab.Client, error) {
+	baseUrl, err := c.baseURL()
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse git url: %w", err)
+		return nil, err
+	}
+	newClient := gitlab.NewClient
+	if cred.Type == credentials.CredentialTypeOAuth {
+		// OAuth access tokens are sent as bearer tokens instead of private tokens
+		newClient = gitlab.NewOAuthClient
 	}
-	baseUrl := u.Scheme + "://" + c.Host
-	client, err := gitlab.NewClient(token, gitlab.WithBaseURL(baseUrl))
+	client, err := newClient(cred.Token, gitlab.WithBaseURL(baseUrl))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GitLab client: %w", err)
 	}
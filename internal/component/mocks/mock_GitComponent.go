@@ -7,6 +7,8 @@ import (
 
 	context "context"
 
+	credentials "github.com/konflux-ci/mintmaker/internal/credentials"
+
 	mock "github.com/stretchr/testify/mock"
 
 	v1 "k8s.io/api/core/v1"
@@ -25,6 +27,63 @@ func (_m *MockGitComponent) EXPECT() *MockGitComponent_Expecter {
 	return &MockGitComponent_Expecter{mock: &_m.Mock}
 }
 
+// ExplainCredentials provides a mock function with no fields
+func (_m *MockGitComponent) ExplainCredentials() ([]credentials.Decision, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExplainCredentials")
+	}
+
+	var r0 []credentials.Decision
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]credentials.Decision, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []credentials.Decision); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]credentials.Decision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockGitComponent_ExplainCredentials_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExplainCredentials'
+type MockGitComponent_ExplainCredentials_Call struct {
+	*mock.Call
+}
+
+// ExplainCredentials is a helper method to define mock.On call
+func (_e *MockGitComponent_Expecter) ExplainCredentials() *MockGitComponent_ExplainCredentials_Call {
+	return &MockGitComponent_ExplainCredentials_Call{Call: _e.mock.On("ExplainCredentials")}
+}
+
+func (_c *MockGitComponent_ExplainCredentials_Call) Run(run func()) *MockGitComponent_ExplainCredentials_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockGitComponent_ExplainCredentials_Call) Return(_a0 []credentials.Decision, _a1 error) *MockGitComponent_ExplainCredentials_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockGitComponent_ExplainCredentials_Call) RunAndReturn(run func() ([]credentials.Decision, error)) *MockGitComponent_ExplainCredentials_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetAPIEndpoint provides a mock function with no fields
 func (_m *MockGitComponent) GetAPIEndpoint() string {
 	ret := _m.Called()
@@ -172,6 +231,63 @@ func (_c *MockGitComponent_GetBranches_Call) RunAndReturn(run func() ([]string,
 	return _c
 }
 
+// GetCredential provides a mock function with no fields
+func (_m *MockGitComponent) GetCredential() (*credentials.SCMCredential, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCredential")
+	}
+
+	var r0 *credentials.SCMCredential
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (*credentials.SCMCredential, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *credentials.SCMCredential); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*credentials.SCMCredential)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockGitComponent_GetCredential_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCredential'
+type MockGitComponent_GetCredential_Call struct {
+	*mock.Call
+}
+
+// GetCredential is a helper method to define mock.On call
+func (_e *MockGitComponent_Expecter) GetCredential() *MockGitComponent_GetCredential_Call {
+	return &MockGitComponent_GetCredential_Call{Call: _e.mock.On("GetCredential")}
+}
+
+func (_c *MockGitComponent_GetCredential_Call) Run(run func()) *MockGitComponent_GetCredential_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockGitComponent_GetCredential_Call) Return(_a0 *credentials.SCMCredential, _a1 error) *MockGitComponent_GetCredential_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockGitComponent_GetCredential_Call) RunAndReturn(run func() (*credentials.SCMCredential, error)) *MockGitComponent_GetCredential_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetGitURL provides a mock function with no fields
 func (_m *MockGitComponent) GetGitURL() string {
 	ret := _m.Called()
@@ -618,6 +734,61 @@ func (_c *MockGitComponent_GetToken_Call) RunAndReturn(run func() (string, error
 	return _c
 }
 
+// ValidateCredentials provides a mock function with no fields
+func (_m *MockGitComponent) ValidateCredentials() (credentials.Validation, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ValidateCredentials")
+	}
+
+	var r0 credentials.Validation
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (credentials.Validation, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() credentials.Validation); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(credentials.Validation)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockGitComponent_ValidateCredentials_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ValidateCredentials'
+type MockGitComponent_ValidateCredentials_Call struct {
+	*mock.Call
+}
+
+// ValidateCredentials is a helper method to define mock.On call
+func (_e *MockGitComponent_Expecter) ValidateCredentials() *MockGitComponent_ValidateCredentials_Call {
+	return &MockGitComponent_ValidateCredentials_Call{Call: _e.mock.On("ValidateCredentials")}
+}
+
+func (_c *MockGitComponent_ValidateCredentials_Call) Run(run func()) *MockGitComponent_ValidateCredentials_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockGitComponent_ValidateCredentials_Call) Return(_a0 credentials.Validation, _a1 error) *MockGitComponent_ValidateCredentials_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockGitComponent_ValidateCredentials_Call) RunAndReturn(run func() (credentials.Validation, error)) *MockGitComponent_ValidateCredentials_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockGitComponent creates a new instance of MockGitComponent. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockGitComponent(t interface {
@@ -25,9 +25,56 @@
 //	    "token-ttl": "60m",
 //	    "token-min-validity": "30m"
 //	  },
+//	  "oauth": {
+//	    "token-min-validity": "1h"
+//	  },
 //	  "kite": {
 //	    "enabled": true,
 //	    "api-url": "https://kite.example.com"
+//	  },
+//	  "token-broker": {
+//	    "enabled": true,
+//	    "bind-address": ":8090",
+//	    "url": "https://mintmaker-token-broker.mintmaker.svc:8090",
+//	    "audience": "mintmaker-token-broker",
+//	    "cert-file": "/etc/mintmaker/tls/tls.crt",
+//	    "key-file": "/etc/mintmaker/tls/tls.key"
+//	  },
+//	  "renovate-config": {
+//	    "refresh-interval": "5m",
+//	    "file-format": "json",
+//	    "namespace-options": ["schedule", "automerge", "labels", "prFooter"]
+//	  },
+//	  "registry-mirrors": {
+//	    "mirrors": [
+//	      {"source": "quay.io", "mirrors": ["mirror.example.com/quay.io"]}
+//	    ],
+//	    "image-digest-mirror-sets": true
+//	  },
+//	  "presets": {
+//	    "enabled": true,
+//	    "bind-address": ":8091",
+//	    "url": "https://mintmaker-presets.mintmaker.svc:8091",
+//	    "cert-file": "/etc/mintmaker/presets-tls/tls.crt",
+//	    "key-file": "/etc/mintmaker/presets-tls/tls.key"
+//	  },
+//	  "pipeline-run-template": {
+//	    "config-map": "renovate-pipeline-v2",
+//	    "refresh-interval": "5m"
+//	  },
+//	  "pipeline-run-retry": {
+//	    "max-retries": 2,
+//	    "backoff": "2m",
+//	    "max-backoff": "30m",
+//	    "size-up-on-oom": true
+//	  },
+//	  "pod-scheduling": {
+//	    "node-selector": {"node-role.kubernetes.io/renovate": ""},
+//	    "tolerations": [
+//	      {"key": "renovate", "operator": "Exists", "effect": "NoSchedule"}
+//	    ],
+//	    "priority-class-name": "renovate",
+//	    "runtime-class-name": "kata"
 //	  }
 //	}
 //
@@ -43,24 +90,141 @@
 //   - At 20:25 (35m remaining > 30m min): token is usable
 //   - At 20:35 (25m remaining < 30m min): token needs renewal
 //
+// OAuth Configuration:
+//
+// Access tokens of OAuth SCM secrets (GitLab, Forgejo) are refreshed by the
+// controller and the token broker and written back to the secret.
+//
+//   - token-min-validity: The minimum remaining validity of a stored access
+//     token for it to be reused. Defaults to the PipelineRun timeout, 1h, so
+//     a token handed to a PipelineRun outlives it. Must be less than the
+//     access token lifetime of the provider, otherwise every use refreshes.
+//
 // Kite Configuration:
 //
 // Kite integration reports issues to Kite after analyzing Renovate logs.
 // It is disabled by default.
 //
 //   - enabled: Set to true to enable Kite integration. When enabled, a
-//     log-analyzer step is added to the pipelinerun. Defaults to false.
+//     log-analyzer task is added to the pipelinerun. Defaults to false.
 //   - api-url: The URL of the Kite API endpoint. Can also be set via
 //     KITE_API_URL environment variable (config file takes precedence).
+//
+// Token Broker Configuration:
+//
+// The token broker is an HTTP(S) endpoint served by the manager. Renovate
+// pods present their projected ServiceAccount token and receive a freshly
+// minted Git token, so the token is never stored in a Secret. It is
+// disabled by default.
+//
+//   - enabled: Set to true to serve the broker and make PipelineRuns fetch
+//     their token from it. Defaults to false.
+//   - bind-address: The address the broker listens on. Defaults to ":8090".
+//   - url: The in-cluster URL Renovate pods use to reach the broker.
+//     Required when enabled.
+//   - audience: The audience of the projected ServiceAccount token.
+//     Defaults to "mintmaker-token-broker".
+//   - cert-file, key-file: Optional TLS certificate and key. When both are
+//     set the broker serves HTTPS, otherwise plain HTTP.
+//
+// Renovate Config Configuration:
+//
+// The Renovate base config is read from the renovate-config ConfigMap.
+//
+//   - refresh-interval: How long the base config is used before the ConfigMap
+//     is read again. Invalid updates are rejected and the last good config is
+//     kept. Defaults to 5m.
+//   - namespace-options: Top level Renovate options tenants may override in a
+//     ConfigMap labelled mintmaker.appstudio.redhat.com/renovate-config in the
+//     component namespace. Defaults to a list of scheduling, automerge, PR and
+//     labelling options.
+//   - file-format: How the generated config is handed to Renovate, "json"
+//     for a config.json file or "js" for the legacy config.js module.
+//     Defaults to "json".
+//
+// Registry Mirrors Configuration:
+//
+// Clusters that reach registries only through mirrors rewrite image
+// references with Renovate's registryAliases. The credentials of a source
+// registry are reused for its mirror unless the mirror has its own.
+//
+//   - mirrors: Sources, a registry or a repository prefix, and their mirrors
+//     in order of preference. Only the first mirror is used.
+//   - image-digest-mirror-sets: Set to true to add the mirrors of the
+//     cluster's ImageDigestMirrorSets after the configured ones. Defaults to
+//     false.
+//
+// Presets Configuration:
+//
+// The preset library serves the presets of ConfigMaps labelled
+// mintmaker.appstudio.redhat.com/renovate-preset over HTTP(S). Components opt
+// into presets with the mintmaker.appstudio.redhat.com/renovate-presets
+// annotation. It is disabled by default.
+//
+//   - enabled: Set to true to serve the presets. Components requesting
+//     presets are skipped while disabled.
+//   - bind-address: The address the preset server listens on. Defaults to
+//     ":8091".
+//   - url: The in-cluster URL Renovate pods fetch presets from. Required
+//     when enabled.
+//   - cert-file, key-file: Optional TLS certificate and key. When both are
+//     set the server serves HTTPS, otherwise plain HTTP. Renovate pods trust
+//     the OpenShift service CA for it.
+//
+// PipelineRun Template Configuration:
+//
+// The PipelineSpec of Renovate PipelineRuns, on top of which MintMaker adds
+// its mounts, labels and Renovate config. Without a source the built-in spec
+// is used. Templates are validated when read; an invalid update is rejected
+// and the last good template is kept.
+//
+//   - config-map: ConfigMap in the mintmaker namespace holding the
+//     PipelineSpec as YAML in its pipeline.yaml key. Versions are rolled out
+//     by pointing to a new ConfigMap.
+//   - pipeline: Tekton Pipeline in the mintmaker namespace whose spec is
+//     embedded in PipelineRuns. Mutually exclusive with config-map.
+//   - refresh-interval: How long a template is used before it is read
+//     again. Defaults to 5m.
+//
+// PipelineRun Retry Configuration:
+//
+// PipelineRuns failing for transient reasons (OOM killed or evicted pods,
+// image pulls, pod creation) are created again after a backoff, as long as
+// no newer PipelineRun exists for the repository and branch.
+//
+//   - max-retries: How often a PipelineRun is retried, 0 disables retries.
+//     Defaults to 2.
+//   - backoff: The wait after the first failure, doubled for every further
+//     retry. Defaults to 2m.
+//   - max-backoff: The longest wait between retries. Defaults to 30m.
+//   - size-up-on-oom: Set to false to retry OOM killed runs with the same
+//     size profile instead of the next larger one. Defaults to true.
+//
+// Pod Scheduling Configuration:
+//
+// Where the pods of Renovate PipelineRuns are scheduled, set as the pod
+// template of their TaskRuns. A DependencyUpdateCheck overrides each field in
+// its spec.podScheduling. Nothing is set by default.
+//
+//   - node-selector: Node labels the pods must be scheduled on.
+//   - tolerations: Tolerations of the pods, in the Kubernetes format.
+//   - affinity: Node affinity and pod (anti-)affinity, in the Kubernetes
+//     format.
+//   - priority-class-name: PriorityClass of the pods.
+//   - topology-spread-constraints: How the pods are spread across topology
+//     domains, in the Kubernetes format.
+//   - runtime-class-name: RuntimeClass of the pods.
 package config
 
 import (
 	"encoding/json"
 	"os"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -69,6 +233,32 @@ const (
 	configPathEnvVar        = "MINTMAKER_CONFIG_PATH"
 	defaultTokenTTL         = 60 * time.Minute
 	defaultTokenMinValidity = 30 * time.Minute
+
+	// DefaultPipelineRunTimeout is the timeout of Renovate PipelineRuns
+	DefaultPipelineRunTimeout = time.Hour
+
+	defaultOAuthTokenMinValidity = DefaultPipelineRunTimeout
+
+	defaultTokenBrokerBindAddress = ":8090"
+	defaultTokenBrokerAudience    = "mintmaker-token-broker"
+
+	defaultRenovateConfigRefreshInterval = 5 * time.Minute
+
+	defaultPresetsBindAddress = ":8091"
+
+	defaultPipelineRunTemplateRefreshInterval = 5 * time.Minute
+
+	defaultPipelineRunMaxRetries      = 2
+	defaultPipelineRunRetryBackoff    = 2 * time.Minute
+	defaultPipelineRunRetryMaxBackoff = 30 * time.Minute
+)
+
+const (
+	// RenovateConfigFormatJSON writes the generated Renovate config as JSON
+	RenovateConfigFormatJSON = "json"
+	// RenovateConfigFormatJS writes the generated Renovate config as a
+	// JavaScript module, as MintMaker did before
+	RenovateConfigFormatJS = "js"
 )
 
 // GitHubConfig holds GitHub-related configuration.
@@ -88,10 +278,17 @@ type GitHubConfig struct {
 	TokenMinValidity time.Duration
 }
 
+// OAuthConfig holds configuration of OAuth SCM credentials.
+type OAuthConfig struct {
+	// TokenMinValidity is how long a stored access token must stay valid to
+	// be reused instead of refreshed.
+	TokenMinValidity time.Duration
+}
+
 // KiteConfig holds Kite-related configuration.
 type KiteConfig struct {
 	// Enabled controls whether Kite integration is active.
-	// When enabled, a log-analyzer step is added to the pipeline to send
+	// When enabled, a log-analyzer task is added to the pipeline to send
 	// Renovate logs to the Kite API. Defaults to false.
 	Enabled bool
 
@@ -99,10 +296,126 @@ type KiteConfig struct {
 	APIURL string
 }
 
+// TokenBrokerConfig holds configuration of the in-cluster token broker.
+type TokenBrokerConfig struct {
+	// Enabled controls whether the broker is served and whether PipelineRuns
+	// fetch their Git token from it instead of a per-run Secret.
+	Enabled bool
+
+	// BindAddress is the address the broker listens on.
+	BindAddress string
+
+	// URL is the in-cluster URL of the broker, used by Renovate pods.
+	URL string
+
+	// Audience is the audience requested for the projected ServiceAccount
+	// token and checked by the broker with a TokenReview.
+	Audience string
+
+	// CertFile and KeyFile enable HTTPS when both are set.
+	CertFile string
+	KeyFile  string
+}
+
+// RenovateConfigConfig holds configuration of the Renovate base config.
+type RenovateConfigConfig struct {
+	// RefreshInterval is how long the base config is used before the
+	// renovate-config ConfigMap is read again.
+	RefreshInterval time.Duration
+
+	// NamespaceOptions are the top level options namespace ConfigMaps may
+	// override. Nil means the default list of the renovateconfig package.
+	NamespaceOptions []string
+
+	// FileFormat is RenovateConfigFormatJSON or RenovateConfigFormatJS, empty
+	// means RenovateConfigFormatJSON.
+	FileFormat string
+}
+
+// RegistryMirror maps a registry or repository prefix to its mirrors.
+type RegistryMirror struct {
+	Source  string   `json:"source"`
+	Mirrors []string `json:"mirrors"`
+}
+
+// RegistryMirrorsConfig holds configuration of registry mirror rewriting.
+type RegistryMirrorsConfig struct {
+	// Mirrors are applied before the ones of ImageDigestMirrorSets.
+	Mirrors []RegistryMirror
+
+	// ImageDigestMirrorSets controls whether the cluster's
+	// ImageDigestMirrorSets are read.
+	ImageDigestMirrorSets bool
+}
+
+// PresetsConfig holds configuration of the preset library.
+type PresetsConfig struct {
+	// Enabled controls whether presets are served and may be used by
+	// components.
+	Enabled bool
+
+	// BindAddress is the address the preset server listens on.
+	BindAddress string
+
+	// URL is the in-cluster URL of the preset server, used by Renovate pods.
+	URL string
+
+	// CertFile and KeyFile enable HTTPS when both are set.
+	CertFile string
+	KeyFile  string
+}
+
+// PipelineRunTemplateConfig holds where the PipelineRun template is read from.
+type PipelineRunTemplateConfig struct {
+	// ConfigMap holds the template, empty when not read from a ConfigMap.
+	ConfigMap string
+
+	// Pipeline is the Tekton Pipeline holding the template, empty when not
+	// read from a Pipeline.
+	Pipeline string
+
+	// RefreshInterval is how long a template is used before it is read again.
+	RefreshInterval time.Duration
+}
+
+// PipelineRunRetryConfig holds configuration of PipelineRun retries.
+type PipelineRunRetryConfig struct {
+	// MaxRetries is how often a PipelineRun is retried, 0 disables retries.
+	MaxRetries int
+
+	// Backoff is the wait before the first retry, doubled for every further
+	// retry up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// SizeUpOnOOM controls whether OOM killed runs are retried with the next
+	// larger size profile.
+	SizeUpOnOOM bool
+}
+
+// PodSchedulingConfig holds where the pods of Renovate PipelineRuns are
+// scheduled. Kubernetes types keep their own JSON field names.
+type PodSchedulingConfig struct {
+	NodeSelector              map[string]string                 `json:"node-selector"`
+	Tolerations               []corev1.Toleration               `json:"tolerations"`
+	Affinity                  *corev1.Affinity                  `json:"affinity"`
+	PriorityClassName         string                            `json:"priority-class-name"`
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topology-spread-constraints"`
+	RuntimeClassName          string                            `json:"runtime-class-name"`
+}
+
 // Config holds all controller configuration.
 type Config struct {
-	GitHub GitHubConfig
-	Kite   KiteConfig
+	GitHub              GitHubConfig
+	OAuth               OAuthConfig
+	Kite                KiteConfig
+	TokenBroker         TokenBrokerConfig
+	RenovateConfig      RenovateConfigConfig
+	RegistryMirrors     RegistryMirrorsConfig
+	Presets             PresetsConfig
+	PipelineRunTemplate PipelineRunTemplateConfig
+	PipelineRunRetry    PipelineRunRetryConfig
+	PodScheduling       PodSchedulingConfig
 }
 
 // fileConfig represents the JSON structure of the config file.
@@ -111,10 +424,49 @@ type fileConfig struct {
 		TokenTTL         string `json:"token-ttl"`
 		TokenMinValidity string `json:"token-min-validity"`
 	} `json:"github"`
+	OAuth struct {
+		TokenMinValidity string `json:"token-min-validity"`
+	} `json:"oauth"`
 	Kite struct {
 		Enabled bool   `json:"enabled"`
 		APIURL  string `json:"api-url"`
 	} `json:"kite"`
+	TokenBroker struct {
+		Enabled     bool   `json:"enabled"`
+		BindAddress string `json:"bind-address"`
+		URL         string `json:"url"`
+		Audience    string `json:"audience"`
+		CertFile    string `json:"cert-file"`
+		KeyFile     string `json:"key-file"`
+	} `json:"token-broker"`
+	RenovateConfig struct {
+		RefreshInterval  string   `json:"refresh-interval"`
+		NamespaceOptions []string `json:"namespace-options"`
+		FileFormat       string   `json:"file-format"`
+	} `json:"renovate-config"`
+	RegistryMirrors struct {
+		Mirrors               []RegistryMirror `json:"mirrors"`
+		ImageDigestMirrorSets bool             `json:"image-digest-mirror-sets"`
+	} `json:"registry-mirrors"`
+	Presets struct {
+		Enabled     bool   `json:"enabled"`
+		BindAddress string `json:"bind-address"`
+		URL         string `json:"url"`
+		CertFile    string `json:"cert-file"`
+		KeyFile     string `json:"key-file"`
+	} `json:"presets"`
+	PipelineRunTemplate struct {
+		ConfigMap       string `json:"config-map"`
+		Pipeline        string `json:"pipeline"`
+		RefreshInterval string `json:"refresh-interval"`
+	} `json:"pipeline-run-template"`
+	PipelineRunRetry struct {
+		MaxRetries  *int   `json:"max-retries"`
+		Backoff     string `json:"backoff"`
+		MaxBackoff  string `json:"max-backoff"`
+		SizeUpOnOOM *bool  `json:"size-up-on-oom"`
+	} `json:"pipeline-run-retry"`
+	PodScheduling PodSchedulingConfig `json:"pod-scheduling"`
 }
 
 var (
@@ -129,10 +481,34 @@ func defaultConfig() *Config {
 			TokenTTL:         defaultTokenTTL,
 			TokenMinValidity: defaultTokenMinValidity,
 		},
+		OAuth: OAuthConfig{
+			TokenMinValidity: defaultOAuthTokenMinValidity,
+		},
 		Kite: KiteConfig{
 			Enabled: false,
 			APIURL:  os.Getenv("KITE_API_URL"),
 		},
+		TokenBroker: TokenBrokerConfig{
+			Enabled:     false,
+			BindAddress: defaultTokenBrokerBindAddress,
+			Audience:    defaultTokenBrokerAudience,
+		},
+		RenovateConfig: RenovateConfigConfig{
+			RefreshInterval: defaultRenovateConfigRefreshInterval,
+			FileFormat:      RenovateConfigFormatJSON,
+		},
+		Presets: PresetsConfig{
+			BindAddress: defaultPresetsBindAddress,
+		},
+		PipelineRunTemplate: PipelineRunTemplateConfig{
+			RefreshInterval: defaultPipelineRunTemplateRefreshInterval,
+		},
+		PipelineRunRetry: PipelineRunRetryConfig{
+			MaxRetries:  defaultPipelineRunMaxRetries,
+			Backoff:     defaultPipelineRunRetryBackoff,
+			MaxBackoff:  defaultPipelineRunRetryMaxBackoff,
+			SizeUpOnOOM: true,
+		},
 	}
 }
 
@@ -165,13 +541,11 @@ func parse(data []byte, log logr.Logger) *Config {
 	cfg := defaultConfig()
 
 	// GitHub token config
-	if ttl, err := time.ParseDuration(fc.GitHub.TokenTTL); err == nil && ttl > 0 {
-		cfg.GitHub.TokenTTL = ttl
-	}
+	parseDuration(log, "github token-ttl", fc.GitHub.TokenTTL, &cfg.GitHub.TokenTTL)
+	parseDuration(log, "github token-min-validity", fc.GitHub.TokenMinValidity, &cfg.GitHub.TokenMinValidity)
 
-	if minValidity, err := time.ParseDuration(fc.GitHub.TokenMinValidity); err == nil && minValidity > 0 {
-		cfg.GitHub.TokenMinValidity = minValidity
-	}
+	// OAuth SCM credentials
+	parseDuration(log, "oauth token-min-validity", fc.OAuth.TokenMinValidity, &cfg.OAuth.TokenMinValidity)
 
 	// Kite config: file takes precedence over env var
 	cfg.Kite.Enabled = fc.Kite.Enabled
@@ -179,12 +553,77 @@ func parse(data []byte, log logr.Logger) *Config {
 		cfg.Kite.APIURL = fc.Kite.APIURL
 	}
 
+	// Token broker config
+	cfg.TokenBroker.Enabled = fc.TokenBroker.Enabled
+	if fc.TokenBroker.BindAddress != "" {
+		cfg.TokenBroker.BindAddress = fc.TokenBroker.BindAddress
+	}
+	if fc.TokenBroker.Audience != "" {
+		cfg.TokenBroker.Audience = fc.TokenBroker.Audience
+	}
+	cfg.TokenBroker.URL = fc.TokenBroker.URL
+	cfg.TokenBroker.CertFile = fc.TokenBroker.CertFile
+	cfg.TokenBroker.KeyFile = fc.TokenBroker.KeyFile
+
+	// Renovate base config
+	parseDuration(log, "renovate-config refresh-interval", fc.RenovateConfig.RefreshInterval, &cfg.RenovateConfig.RefreshInterval)
+	cfg.RenovateConfig.NamespaceOptions = fc.RenovateConfig.NamespaceOptions
+	if fc.RenovateConfig.FileFormat != "" {
+		cfg.RenovateConfig.FileFormat = fc.RenovateConfig.FileFormat
+	}
+
+	// Registry mirrors
+	cfg.RegistryMirrors.Mirrors = fc.RegistryMirrors.Mirrors
+	cfg.RegistryMirrors.ImageDigestMirrorSets = fc.RegistryMirrors.ImageDigestMirrorSets
+
+	// Preset library
+	cfg.Presets.Enabled = fc.Presets.Enabled
+	if fc.Presets.BindAddress != "" {
+		cfg.Presets.BindAddress = fc.Presets.BindAddress
+	}
+	cfg.Presets.URL = fc.Presets.URL
+	cfg.Presets.CertFile = fc.Presets.CertFile
+	cfg.Presets.KeyFile = fc.Presets.KeyFile
+
+	// PipelineRun template
+	cfg.PipelineRunTemplate.ConfigMap = fc.PipelineRunTemplate.ConfigMap
+	cfg.PipelineRunTemplate.Pipeline = fc.PipelineRunTemplate.Pipeline
+	parseDuration(log, "pipeline-run-template refresh-interval", fc.PipelineRunTemplate.RefreshInterval, &cfg.PipelineRunTemplate.RefreshInterval)
+
+	// PipelineRun retries
+	if fc.PipelineRunRetry.MaxRetries != nil {
+		cfg.PipelineRunRetry.MaxRetries = *fc.PipelineRunRetry.MaxRetries
+	}
+	parseDuration(log, "pipeline-run-retry backoff", fc.PipelineRunRetry.Backoff, &cfg.PipelineRunRetry.Backoff)
+	parseDuration(log, "pipeline-run-retry max-backoff", fc.PipelineRunRetry.MaxBackoff, &cfg.PipelineRunRetry.MaxBackoff)
+	if fc.PipelineRunRetry.SizeUpOnOOM != nil {
+		cfg.PipelineRunRetry.SizeUpOnOOM = *fc.PipelineRunRetry.SizeUpOnOOM
+	}
+
+	// Pod scheduling
+	cfg.PodScheduling = fc.PodScheduling
+
 	if err := cfg.validate(log); err != nil {
 		return defaultConfig()
 	}
 	return cfg
 }
 
+// parseDuration sets field to the given value when it is a positive duration.
+// Invalid values are logged and the field keeps its default.
+func parseDuration(log logr.Logger, option, value string, field *time.Duration) {
+	if value == "" {
+		return
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Info("invalid config: "+option+" must be a positive duration, using the default",
+			"value", value, "default", *field)
+		return
+	}
+	*field = duration
+}
+
 // validate checks that the configuration values are valid.
 func (c *Config) validate(log logr.Logger) error {
 	if c.GitHub.TokenMinValidity >= c.GitHub.TokenTTL {
@@ -193,6 +632,57 @@ func (c *Config) validate(log logr.Logger) error {
 			"token-min-validity", c.GitHub.TokenMinValidity)
 		return errInvalidConfig
 	}
+	if c.TokenBroker.Enabled && c.TokenBroker.URL == "" {
+		log.Info("invalid config: token-broker url is required when the broker is enabled, using defaults")
+		return errInvalidConfig
+	}
+	if (c.TokenBroker.CertFile == "") != (c.TokenBroker.KeyFile == "") {
+		log.Info("invalid config: token-broker cert-file and key-file must be set together, using defaults",
+			"cert-file", c.TokenBroker.CertFile,
+			"key-file", c.TokenBroker.KeyFile)
+		return errInvalidConfig
+	}
+	switch c.RenovateConfig.FileFormat {
+	case "", RenovateConfigFormatJSON, RenovateConfigFormatJS:
+	default:
+		log.Info("invalid config: renovate-config file-format must be json or js, using defaults",
+			"file-format", c.RenovateConfig.FileFormat)
+		return errInvalidConfig
+	}
+	for _, mirror := range c.RegistryMirrors.Mirrors {
+		if mirror.Source == "" || len(mirror.Mirrors) == 0 || slices.Contains(mirror.Mirrors, "") {
+			log.Info("invalid config: registry-mirrors need a source and at least one mirror, using defaults",
+				"source", mirror.Source, "mirrors", mirror.Mirrors)
+			return errInvalidConfig
+		}
+	}
+	if c.Presets.Enabled && c.Presets.URL == "" {
+		log.Info("invalid config: presets url is required when presets are enabled, using defaults")
+		return errInvalidConfig
+	}
+	if (c.Presets.CertFile == "") != (c.Presets.KeyFile == "") {
+		log.Info("invalid config: presets cert-file and key-file must be set together, using defaults",
+			"cert-file", c.Presets.CertFile,
+			"key-file", c.Presets.KeyFile)
+		return errInvalidConfig
+	}
+	if c.PipelineRunTemplate.ConfigMap != "" && c.PipelineRunTemplate.Pipeline != "" {
+		log.Info("invalid config: pipeline-run-template config-map and pipeline are mutually exclusive, using defaults",
+			"config-map", c.PipelineRunTemplate.ConfigMap,
+			"pipeline", c.PipelineRunTemplate.Pipeline)
+		return errInvalidConfig
+	}
+	if c.PipelineRunRetry.MaxRetries < 0 {
+		log.Info("invalid config: pipeline-run-retry max-retries must not be negative, using defaults",
+			"max-retries", c.PipelineRunRetry.MaxRetries)
+		return errInvalidConfig
+	}
+	if c.PipelineRunRetry.MaxBackoff < c.PipelineRunRetry.Backoff {
+		log.Info("invalid config: pipeline-run-retry max-backoff must not be less than backoff, using defaults",
+			"backoff", c.PipelineRunRetry.Backoff,
+			"max-backoff", c.PipelineRunRetry.MaxBackoff)
+		return errInvalidConfig
+	}
 	return nil
 }
 
@@ -1,10 +1,14 @@
 package config
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	corev1 "k8s.io/api/core/v1"
 )
 
 func TestParse(t *testing.T) {
@@ -146,6 +150,218 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestParseOAuth(t *testing.T) {
+	log := logr.Discard()
+
+	cfg := parse([]byte(`{}`), log)
+	if cfg.OAuth.TokenMinValidity != DefaultPipelineRunTimeout {
+		t.Errorf("OAuth.TokenMinValidity: expected the PipelineRun timeout %v, got %v", DefaultPipelineRunTimeout, cfg.OAuth.TokenMinValidity)
+	}
+
+	cfg = parse([]byte(`{"oauth": {"token-min-validity": "90m"}}`), log)
+	if cfg.OAuth.TokenMinValidity != 90*time.Minute {
+		t.Errorf("OAuth.TokenMinValidity: expected 90m, got %v", cfg.OAuth.TokenMinValidity)
+	}
+}
+
+func TestParseTokenBroker(t *testing.T) {
+	log := logr.Discard()
+
+	cfg := parse([]byte(`{
+		"token-broker": {
+			"enabled": true,
+			"url": "https://mintmaker-token-broker.mintmaker.svc:8090"
+		}
+	}`), log)
+
+	if !cfg.TokenBroker.Enabled {
+		t.Error("expected TokenBroker.Enabled to be true")
+	}
+	if cfg.TokenBroker.URL != "https://mintmaker-token-broker.mintmaker.svc:8090" {
+		t.Errorf("TokenBroker.URL: got %q", cfg.TokenBroker.URL)
+	}
+	if cfg.TokenBroker.BindAddress != defaultTokenBrokerBindAddress {
+		t.Errorf("TokenBroker.BindAddress: expected %q, got %q", defaultTokenBrokerBindAddress, cfg.TokenBroker.BindAddress)
+	}
+	if cfg.TokenBroker.Audience != defaultTokenBrokerAudience {
+		t.Errorf("TokenBroker.Audience: expected %q, got %q", defaultTokenBrokerAudience, cfg.TokenBroker.Audience)
+	}
+
+	// Enabling the broker without a URL is invalid and falls back to defaults
+	cfg = parse([]byte(`{"token-broker": {"enabled": true}}`), log)
+	if cfg.TokenBroker.Enabled {
+		t.Error("expected TokenBroker.Enabled to be false after invalid config")
+	}
+}
+
+func TestParseRenovateConfig(t *testing.T) {
+	log := logr.Discard()
+
+	cfg := parse([]byte(`{"renovate-config": {"refresh-interval": "90s"}}`), log)
+	if cfg.RenovateConfig.RefreshInterval != 90*time.Second {
+		t.Errorf("RenovateConfig.RefreshInterval: expected 90s, got %v", cfg.RenovateConfig.RefreshInterval)
+	}
+
+	if cfg.RenovateConfig.NamespaceOptions != nil {
+		t.Errorf("RenovateConfig.NamespaceOptions: expected nil by default, got %v", cfg.RenovateConfig.NamespaceOptions)
+	}
+
+	cfg = parse([]byte(`{"renovate-config": {"namespace-options": ["schedule", "labels"]}}`), log)
+	if len(cfg.RenovateConfig.NamespaceOptions) != 2 || cfg.RenovateConfig.NamespaceOptions[1] != "labels" {
+		t.Errorf("RenovateConfig.NamespaceOptions: got %v", cfg.RenovateConfig.NamespaceOptions)
+	}
+
+	if cfg.RenovateConfig.FileFormat != RenovateConfigFormatJSON {
+		t.Errorf("RenovateConfig.FileFormat: expected json by default, got %q", cfg.RenovateConfig.FileFormat)
+	}
+	cfg = parse([]byte(`{"renovate-config": {"file-format": "js"}}`), log)
+	if cfg.RenovateConfig.FileFormat != RenovateConfigFormatJS {
+		t.Errorf("RenovateConfig.FileFormat: expected js, got %q", cfg.RenovateConfig.FileFormat)
+	}
+	cfg = parse([]byte(`{"renovate-config": {"file-format": "yaml", "refresh-interval": "90s"}}`), log)
+	if cfg.RenovateConfig.FileFormat != RenovateConfigFormatJSON || cfg.RenovateConfig.RefreshInterval != defaultRenovateConfigRefreshInterval {
+		t.Errorf("RenovateConfig: expected defaults for an unknown file format, got %+v", cfg.RenovateConfig)
+	}
+
+	// Unparsable and non-positive intervals are logged and keep the default
+	for _, interval := range []string{"soon", "0s", "-1m"} {
+		var logged []string
+		capture := funcr.New(func(prefix, args string) { logged = append(logged, args) }, funcr.Options{})
+		cfg = parse([]byte(`{"renovate-config": {"refresh-interval": "`+interval+`"}}`), capture)
+		if cfg.RenovateConfig.RefreshInterval != defaultRenovateConfigRefreshInterval {
+			t.Errorf("RenovateConfig.RefreshInterval for %q: expected default, got %v", interval, cfg.RenovateConfig.RefreshInterval)
+		}
+		if len(logged) != 1 || !strings.Contains(logged[0], "renovate-config refresh-interval must be a positive duration") {
+			t.Errorf("RenovateConfig.RefreshInterval for %q: expected the invalid value to be logged, got %v", interval, logged)
+		}
+	}
+}
+
+func TestParseRegistryMirrors(t *testing.T) {
+	log := logr.Discard()
+
+	cfg := parse([]byte(`{"registry-mirrors": {
+		"mirrors": [{"source": "quay.io", "mirrors": ["mirror.example.com/quay.io"]}],
+		"image-digest-mirror-sets": true
+	}}`), log)
+	if len(cfg.RegistryMirrors.Mirrors) != 1 || cfg.RegistryMirrors.Mirrors[0].Mirrors[0] != "mirror.example.com/quay.io" {
+		t.Errorf("RegistryMirrors.Mirrors: got %+v", cfg.RegistryMirrors.Mirrors)
+	}
+	if !cfg.RegistryMirrors.ImageDigestMirrorSets {
+		t.Error("expected RegistryMirrors.ImageDigestMirrorSets to be true")
+	}
+
+	// Mirrors without a source or targets are invalid and fall back to defaults
+	for _, mirrors := range []string{`[{"mirrors": ["mirror.example.com"]}]`, `[{"source": "quay.io"}]`, `[{"source": "quay.io", "mirrors": [""]}]`} {
+		cfg = parse([]byte(`{"registry-mirrors": {"mirrors": `+mirrors+`, "image-digest-mirror-sets": true}}`), log)
+		if cfg.RegistryMirrors.Mirrors != nil || cfg.RegistryMirrors.ImageDigestMirrorSets {
+			t.Errorf("expected defaults for mirrors %s, got %+v", mirrors, cfg.RegistryMirrors)
+		}
+	}
+}
+
+func TestParsePresets(t *testing.T) {
+	log := logr.Discard()
+
+	cfg := parse([]byte(`{"presets": {"enabled": true, "url": "http://mintmaker-presets.mintmaker.svc:8091"}}`), log)
+	if !cfg.Presets.Enabled || cfg.Presets.URL != "http://mintmaker-presets.mintmaker.svc:8091" {
+		t.Errorf("Presets: got %+v", cfg.Presets)
+	}
+	if cfg.Presets.BindAddress != defaultPresetsBindAddress {
+		t.Errorf("Presets.BindAddress: expected %q, got %q", defaultPresetsBindAddress, cfg.Presets.BindAddress)
+	}
+
+	// Enabling presets without a URL is invalid and falls back to defaults
+	cfg = parse([]byte(`{"presets": {"enabled": true}}`), log)
+	if cfg.Presets.Enabled {
+		t.Error("expected Presets.Enabled to be false after invalid config")
+	}
+
+	cfg = parse([]byte(`{"presets": {"enabled": true, "url": "https://mintmaker-presets.mintmaker.svc:8091", "cert-file": "/tls/tls.crt", "key-file": "/tls/tls.key"}}`), log)
+	if cfg.Presets.CertFile != "/tls/tls.crt" || cfg.Presets.KeyFile != "/tls/tls.key" {
+		t.Errorf("Presets TLS: got %+v", cfg.Presets)
+	}
+
+	// A certificate without a key is invalid and falls back to defaults
+	cfg = parse([]byte(`{"presets": {"enabled": true, "url": "https://mintmaker-presets.mintmaker.svc:8091", "cert-file": "/tls/tls.crt"}}`), log)
+	if cfg.Presets.Enabled || cfg.Presets.CertFile != "" {
+		t.Errorf("expected default presets config after invalid config, got %+v", cfg.Presets)
+	}
+}
+
+func TestParsePipelineRunTemplate(t *testing.T) {
+	log := logr.Discard()
+
+	cfg := parse([]byte(`{"pipeline-run-template": {"config-map": "renovate-pipeline-v2", "refresh-interval": "1m"}}`), log)
+	if cfg.PipelineRunTemplate.ConfigMap != "renovate-pipeline-v2" || cfg.PipelineRunTemplate.RefreshInterval != time.Minute {
+		t.Errorf("PipelineRunTemplate: got %+v", cfg.PipelineRunTemplate)
+	}
+
+	cfg = parse([]byte(`{}`), log)
+	if cfg.PipelineRunTemplate.RefreshInterval != defaultPipelineRunTemplateRefreshInterval {
+		t.Errorf("PipelineRunTemplate.RefreshInterval: expected %v, got %v",
+			defaultPipelineRunTemplateRefreshInterval, cfg.PipelineRunTemplate.RefreshInterval)
+	}
+
+	// A ConfigMap and a Pipeline together are invalid and fall back to defaults
+	cfg = parse([]byte(`{"pipeline-run-template": {"config-map": "renovate-pipeline-v2", "pipeline": "renovate"}}`), log)
+	if cfg.PipelineRunTemplate.ConfigMap != "" || cfg.PipelineRunTemplate.Pipeline != "" {
+		t.Errorf("expected no PipelineRun template after invalid config, got %+v", cfg.PipelineRunTemplate)
+	}
+}
+
+func TestParsePipelineRunRetry(t *testing.T) {
+	log := logr.Discard()
+
+	cfg := parse([]byte(`{}`), log)
+	expected := PipelineRunRetryConfig{
+		MaxRetries:  defaultPipelineRunMaxRetries,
+		Backoff:     defaultPipelineRunRetryBackoff,
+		MaxBackoff:  defaultPipelineRunRetryMaxBackoff,
+		SizeUpOnOOM: true,
+	}
+	if cfg.PipelineRunRetry != expected {
+		t.Errorf("PipelineRunRetry: expected %+v, got %+v", expected, cfg.PipelineRunRetry)
+	}
+
+	cfg = parse([]byte(`{"pipeline-run-retry": {"max-retries": 0, "backoff": "1m", "max-backoff": "5m", "size-up-on-oom": false}}`), log)
+	expected = PipelineRunRetryConfig{MaxRetries: 0, Backoff: time.Minute, MaxBackoff: 5 * time.Minute}
+	if cfg.PipelineRunRetry != expected {
+		t.Errorf("PipelineRunRetry: expected %+v, got %+v", expected, cfg.PipelineRunRetry)
+	}
+
+	// A max-backoff below the backoff is invalid and falls back to defaults
+	cfg = parse([]byte(`{"pipeline-run-retry": {"max-retries": 5, "backoff": "10m", "max-backoff": "5m"}}`), log)
+	if cfg.PipelineRunRetry.MaxRetries != defaultPipelineRunMaxRetries {
+		t.Errorf("expected default PipelineRunRetry after invalid config, got %+v", cfg.PipelineRunRetry)
+	}
+}
+
+func TestParsePodScheduling(t *testing.T) {
+	log := logr.Discard()
+
+	cfg := parse([]byte(`{}`), log)
+	if !reflect.DeepEqual(cfg.PodScheduling, PodSchedulingConfig{}) {
+		t.Errorf("PodScheduling: expected nothing by default, got %+v", cfg.PodScheduling)
+	}
+
+	cfg = parse([]byte(`{"pod-scheduling": {
+		"node-selector": {"node-role.kubernetes.io/renovate": ""},
+		"tolerations": [{"key": "renovate", "operator": "Exists", "effect": "NoSchedule"}],
+		"priority-class-name": "renovate",
+		"runtime-class-name": "kata"
+	}}`), log)
+	expected := PodSchedulingConfig{
+		NodeSelector:      map[string]string{"node-role.kubernetes.io/renovate": ""},
+		Tolerations:       []corev1.Toleration{{Key: "renovate", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
+		PriorityClassName: "renovate",
+		RuntimeClassName:  "kata",
+	}
+	if !reflect.DeepEqual(cfg.PodScheduling, expected) {
+		t.Errorf("PodScheduling: expected %+v, got %+v", expected, cfg.PodScheduling)
+	}
+}
+
 func TestDefaultConfig(t *testing.T) {
 	cfg := defaultConfig()
 
@@ -198,6 +414,32 @@ func TestValidate(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "token broker enabled without url",
+			cfg: Config{
+				GitHub: GitHubConfig{
+					TokenTTL:         60 * time.Minute,
+					TokenMinValidity: 30 * time.Minute,
+				},
+				TokenBroker: TokenBrokerConfig{Enabled: true},
+			},
+			expectError: true,
+		},
+		{
+			name: "token broker cert without key",
+			cfg: Config{
+				GitHub: GitHubConfig{
+					TokenTTL:         60 * time.Minute,
+					TokenMinValidity: 30 * time.Minute,
+				},
+				TokenBroker: TokenBrokerConfig{
+					Enabled:  true,
+					URL:      "https://broker.example.com",
+					CertFile: "/etc/tls/tls.crt",
+				},
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tc := range tests {
@@ -29,9 +29,33 @@ const (
 	// Using hash because k8s label value doesn't support `/` (in repo path)
 	MintMakerRepoBranchHashLabel = "mintmaker.appstudio.redhat.com/repo-branch-hash"
 
+	// Labels set on MintMaker PipelineRuns (and propagated to their pods)
+	// identifying the Konflux Component and git host a run belongs to
+	MintMakerComponentNameLabel      = "mintmaker.appstudio.redhat.com/component"
+	MintMakerComponentNamespaceLabel = "mintmaker.appstudio.redhat.com/namespace"
+	MintMakerGitHostLabel            = "mintmaker.appstudio.redhat.com/git-host"
+
+	// Retry lineage labels set on retried PipelineRuns: the first PipelineRun,
+	// the failed one retried, the attempt number and the failure reason
+	MintMakerRetryRootLabel    = "mintmaker.appstudio.redhat.com/retry-root"
+	MintMakerRetryParentLabel  = "mintmaker.appstudio.redhat.com/retry-parent"
+	MintMakerRetryAttemptLabel = "mintmaker.appstudio.redhat.com/retry-attempt"
+	MintMakerRetryReasonLabel  = "mintmaker.appstudio.redhat.com/retry-reason"
+
+	// Annotation set on finished PipelineRuns once their results are recorded
+	MintMakerResultsCollectedAnnotation = "mintmaker.appstudio.redhat.com/results-collected"
+	// Label of the ConfigMaps holding the run report of a PipelineRun, under
+	// the RunReportConfigMapKey key
+	MintMakerRunReportLabel = "mintmaker.appstudio.redhat.com/run-report"
+	RunReportConfigMapKey   = "report.json"
+
 	RenovateImageEnvName    = "RENOVATE_IMAGE"
 	DefaultRenovateImageURL = "quay.io/konflux-ci/mintmaker-renovate-image:latest"
 
 	LeakTKImageEnvName    = "LEAKTK_IMAGE"
 	DefaultLeakTKImageURL = "quay.io/leaktk/leaktk:latest"
+
+	// The MintMaker image, running the steps built from this repository
+	MintMakerImageEnvName    = "MINTMAKER_IMAGE"
+	DefaultMintMakerImageURL = "quay.io/konflux-ci/mintmaker:latest"
 )
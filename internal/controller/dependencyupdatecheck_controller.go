@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"slices"
 	"time"
 
@@ -26,6 +27,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -33,6 +36,7 @@ import (
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/pod"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 
 	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
@@ -41,18 +45,31 @@ import (
 	"github.com/konflux-ci/mintmaker/internal/component"
 	"github.com/konflux-ci/mintmaker/internal/config"
 	mmconst "github.com/konflux-ci/mintmaker/internal/constant"
+	"github.com/konflux-ci/mintmaker/internal/credentials"
 	mintmakermetrics "github.com/konflux-ci/mintmaker/internal/metrics"
+	"github.com/konflux-ci/mintmaker/internal/renovateconfig"
 	"github.com/konflux-ci/mintmaker/internal/tekton"
 	"github.com/konflux-ci/mintmaker/internal/utils"
 )
 
 const InternalSecretLabelName = "appstudio.redhat.com/internal"
 
+// InvalidRenovateConfigReason is the reason of components skipped because
+// their generated Renovate config doesn't match the Renovate schema
+const InvalidRenovateConfigReason = "InvalidRenovateConfig"
+
 // DependencyUpdateCheckReconciler reconciles a DependencyUpdateCheck object
 type DependencyUpdateCheckReconciler struct {
 	Client          client.Client
 	Scheme          *runtime.Scheme
 	NewGitComponent component.GitComponentFactory
+	// Recorder emits events on the DependencyUpdateCheck, set up from the
+	// manager when nil
+	Recorder events.EventRecorder
+	// PipelineTemplates provides the PipelineSpec of PipelineRuns, set up
+	// from the operator config by SetupWithManager. The built-in spec is used
+	// when nil.
+	PipelineTemplates *tekton.TemplateLoader
 }
 
 func NewDependencyUpdateCheckReconciler(client client.Client, scheme *runtime.Scheme, newGitComponent component.GitComponentFactory) *DependencyUpdateCheckReconciler {
@@ -184,6 +201,121 @@ func (r *DependencyUpdateCheckReconciler) hasActivePipelineRun(ctx context.Conte
 	return false, nil
 }
 
+// podTemplate returns the pod template of the PipelineRuns of a
+// DependencyUpdateCheck: the scheduling of the MintMaker config, with the
+// fields set in the DependencyUpdateCheck replacing it. Returns nil when
+// nothing is set.
+func podTemplate(global config.PodSchedulingConfig, override *mmv1alpha1.PodSchedulingSpec) *pod.Template {
+	scheduling := mmv1alpha1.PodSchedulingSpec{
+		NodeSelector:              global.NodeSelector,
+		Tolerations:               global.Tolerations,
+		Affinity:                  global.Affinity,
+		PriorityClassName:         global.PriorityClassName,
+		TopologySpreadConstraints: global.TopologySpreadConstraints,
+		RuntimeClassName:          global.RuntimeClassName,
+	}
+	if override != nil {
+		if override.NodeSelector != nil {
+			scheduling.NodeSelector = override.NodeSelector
+		}
+		if override.Tolerations != nil {
+			scheduling.Tolerations = override.Tolerations
+		}
+		if override.Affinity != nil {
+			scheduling.Affinity = override.Affinity
+		}
+		if override.PriorityClassName != "" {
+			scheduling.PriorityClassName = override.PriorityClassName
+		}
+		if override.TopologySpreadConstraints != nil {
+			scheduling.TopologySpreadConstraints = override.TopologySpreadConstraints
+		}
+		if override.RuntimeClassName != "" {
+			scheduling.RuntimeClassName = override.RuntimeClassName
+		}
+	}
+
+	template := &pod.Template{
+		NodeSelector:              scheduling.NodeSelector,
+		Tolerations:               scheduling.Tolerations,
+		Affinity:                  scheduling.Affinity,
+		TopologySpreadConstraints: scheduling.TopologySpreadConstraints,
+	}
+	if scheduling.PriorityClassName != "" {
+		template.PriorityClassName = &scheduling.PriorityClassName
+	}
+	if scheduling.RuntimeClassName != "" {
+		template.RuntimeClassName = &scheduling.RuntimeClassName
+	}
+	if reflect.DeepEqual(template, &pod.Template{}) {
+		return nil
+	}
+	return template
+}
+
+// sizeProfile returns the size profile for a repo+branch: the one selected by
+// the Component annotation, otherwise the one fitting the peak memory of the
+// previous run. Returns nil to keep the resources of the template.
+func (r *DependencyUpdateCheckReconciler) sizeProfile(ctx context.Context, appComp *appstudiov1alpha1.Component, host, repository, branch string) *tekton.SizeProfile {
+	log := ctrllog.FromContext(ctx)
+
+	if name := appComp.Annotations[tekton.SizeProfileAnnotation]; name != "" && name != tekton.SizeProfileAuto {
+		if profile, ok := tekton.LookupSizeProfile(name); ok {
+			return &profile
+		}
+		log.Info("ignoring unknown size profile, picking one from the previous run", "sizeProfile", name)
+	}
+
+	peak, found, err := r.previousPeakMemory(ctx, host, repository, branch)
+	if err != nil {
+		log.Error(err, "failed to read the peak memory of the previous PipelineRun")
+		return nil
+	}
+	if !found {
+		return nil
+	}
+	profile := tekton.SizeProfileForPeakMemory(peak)
+	log.Info("picked size profile from the previous run", "sizeProfile", profile.Name, "peakMemory", peak)
+	return &profile
+}
+
+// previousPeakMemory returns the peak memory of the renovate step of the
+// latest completed PipelineRun for a repo+branch, read from its TaskRun pod
+func (r *DependencyUpdateCheckReconciler) previousPeakMemory(ctx context.Context, host, repository, branch string) (int64, bool, error) {
+	pipelineRuns := &tektonv1.PipelineRunList{}
+	if err := r.Client.List(ctx, pipelineRuns,
+		client.InNamespace(mmconst.MintMakerNamespaceName),
+		client.MatchingLabels{mmconst.MintMakerRepoBranchHashLabel: utils.RepoBranchHash(host, repository, branch)},
+	); err != nil {
+		return 0, false, err
+	}
+
+	var previous *tektonv1.PipelineRun
+	for i := range pipelineRuns.Items {
+		pr := &pipelineRuns.Items[i]
+		if pipelineRunCompleted(pr) && (previous == nil || previous.CreationTimestamp.Before(&pr.CreationTimestamp)) {
+			previous = pr
+		}
+	}
+	if previous == nil {
+		return 0, false, nil
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.Client.List(ctx, pods,
+		client.InNamespace(mmconst.MintMakerNamespaceName),
+		client.MatchingLabels{"tekton.dev/pipelineRun": previous.Name},
+	); err != nil {
+		return 0, false, err
+	}
+	for i := range pods.Items {
+		if peak, ok := tekton.PeakMemory(&pods.Items[i]); ok {
+			return peak, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
 // pipelineRunCompleted returns true if the PipelineRun has reached a terminal state
 // (succeeded, failed, or cancelled).
 func pipelineRunCompleted(pr *tektonv1.PipelineRun) bool {
@@ -199,10 +331,19 @@ func pipelineRunCompleted(pr *tektonv1.PipelineRun) bool {
 }
 
 // createPipelineRun creates and returns a new PipelineRun
-func (r *DependencyUpdateCheckReconciler) createPipelineRun(ctx context.Context, name string, comp component.GitComponent, currentBranch string, kiteSecretName string) (*tektonv1.PipelineRun, error) {
+func (r *DependencyUpdateCheckReconciler) createPipelineRun(ctx context.Context, name string, comp component.GitComponent, currentBranch string, kiteSecretName string, sizeProfile *tekton.SizeProfile, podTemplate *pod.Template) (*tektonv1.PipelineRun, error) {
 
 	log := ctrllog.FromContext(ctx).WithName("createPipelineRun")
 
+	template := &tekton.PipelineTemplate{Spec: tekton.DefaultPipelineSpec(), Source: tekton.PipelineTemplateBuiltIn}
+	if r.PipelineTemplates != nil {
+		var err error
+		template, err = r.PipelineTemplates.Get(ctx, r.Client, config.Get().PipelineRunTemplate.RefreshInterval)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var resources []client.Object
 	defer func() {
 		if len(resources) > 0 {
@@ -222,19 +363,39 @@ func (r *DependencyUpdateCheckReconciler) createPipelineRun(ctx context.Context,
 		StringData: map[string]string{},
 	}
 
-	// For GitHub repositories, we intentionally do not set the "renovate-token"
+	// When the token broker is enabled, the token is never written to the
+	// Secret; the renovate step fetches it from the broker at runtime.
+	//
+	// Otherwise, for GitHub repositories, we intentionally do not set the "renovate-token"
 	// key. GitHub tokens generated from the Konflux GitHub application have a
 	// maximum lifespan of 1 hour. Instead of generating a token that might expire
 	// before the pipelinerun starts, we wait for events with "FailedMount" reason,
 	// which happens when pod try to mount the secret but can't find the key in
 	// secret. Then we populate the token in the event controller at that time to
 	// ensure it's valid for the pipelinerun execution.
+	//
+	// SSH deploy keys are used by git for cloning. Pods cannot mount the
+	// tenant's Secret from its namespace, so without the token broker the key
+	// is stored next to the token; the broker serves it at runtime instead.
+	tokenBroker := config.Get().TokenBroker
+	var sshCredential *credentials.SCMCredential
 	if comp.GetPlatform() != "github" {
-		renovateToken, err := comp.GetToken()
+		cred, err := comp.GetCredential()
 		if err != nil {
 			return nil, err
 		}
-		renovateSecret.StringData["renovate-token"] = renovateToken
+		if !tokenBroker.Enabled {
+			renovateSecret.StringData["renovate-token"] = cred.Token
+		}
+		if cred.Type == credentials.CredentialTypeSSH {
+			sshCredential = cred
+			if !tokenBroker.Enabled {
+				renovateSecret.StringData[corev1.SSHAuthPrivateKey] = string(cred.SSHPrivateKey)
+				if len(cred.SSHKnownHosts) > 0 {
+					renovateSecret.StringData[credentials.SSHKnownHostsKey] = string(cred.SSHKnownHosts)
+				}
+			}
+		}
 	}
 
 	// Add a merged docker config to the renovateSecret
@@ -256,7 +417,20 @@ func (r *DependencyUpdateCheckReconciler) createPipelineRun(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
-	renovateJsConfig := "module.exports = " + renovateConfig
+	// The registry credentials of the host rules are redacted from the log
+	var hostRuleSecrets []string
+	if parsedConfig, err := renovateconfig.Parse([]byte(renovateConfig)); err != nil {
+		log.Error(err, "failed to parse Renovate config for known secrets")
+	} else {
+		hostRuleSecrets = parsedConfig.Secrets()
+	}
+	// The JavaScript module is kept for backwards compatibility only, Node
+	// executes it so any string in the merged config would become code
+	renovateConfigFile := tekton.RenovateConfigFile
+	if config.Get().RenovateConfig.FileFormat == config.RenovateConfigFormatJS {
+		renovateConfigFile = tekton.RenovateConfigFileJS
+		renovateConfig = "module.exports = " + renovateConfig
+	}
 	// Create ConfigMap for Renovate global configuration
 	renovateConfigMap := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
@@ -264,7 +438,7 @@ func (r *DependencyUpdateCheckReconciler) createPipelineRun(ctx context.Context,
 			Namespace: mmconst.MintMakerNamespaceName,
 		},
 		Data: map[string]string{
-			"config.js": renovateJsConfig,
+			renovateConfigFile: renovateConfig,
 		},
 	}
 
@@ -297,8 +471,30 @@ func (r *DependencyUpdateCheckReconciler) createPipelineRun(ctx context.Context,
 		resources = append(resources, rpmSecret)
 	}
 
+	// Secret with the values of the host rules, mounted into the log sanitizer
+	// so they are always redacted from the log
+	var knownSecret *corev1.Secret
+	if len(hostRuleSecrets) > 0 {
+		knownSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name + "-known-secrets",
+				Namespace: mmconst.MintMakerNamespaceName,
+			},
+			Type:       corev1.SecretTypeOpaque,
+			StringData: map[string]string{},
+		}
+		for i, secret := range hostRuleSecrets {
+			knownSecret.StringData[fmt.Sprintf("host-rule-%d", i)] = secret
+		}
+
+		if err := r.Client.Create(ctx, knownSecret); err != nil {
+			return nil, err
+		}
+		resources = append(resources, knownSecret)
+	}
+
 	// Creating the pipelineRun definition
-	builder := tekton.NewPipelineRunBuilder(name, mmconst.MintMakerNamespaceName).
+	builder := tekton.NewPipelineRunBuilderFromSpec(name, mmconst.MintMakerNamespaceName, template.Spec).
 		WithLabels(map[string]string{
 			"mintmaker.appstudio.redhat.com/application":  comp.GetApplication(),
 			"mintmaker.appstudio.redhat.com/component":    comp.GetName(),
@@ -309,26 +505,69 @@ func (r *DependencyUpdateCheckReconciler) createPipelineRun(ctx context.Context,
 			"mintmaker.appstudio.redhat.com/branch":       utils.NormalizeLabelValue(currentBranch),
 			mmconst.MintMakerRepoBranchHashLabel:          utils.RepoBranchHash(comp.GetHost(), comp.GetRepository(), currentBranch),
 		}).
+		WithAnnotations(map[string]string{tekton.PipelineTemplateAnnotation: template.Source}).
 		WithTimeouts(nil)
-	builder.WithServiceAccount("mintmaker-controller-manager")
+	builder.WithServiceAccount("mintmaker-controller-manager").
+		WithPodTemplate(podTemplate)
+	if sizeProfile != nil {
+		builder.WithSizeProfile(*sizeProfile)
+	}
+
+	// Record which secrets were chosen for the component and why
+	if decisions, err := comp.ExplainCredentials(); err != nil {
+		log.Error(err, "failed to explain credentials for component")
+	} else {
+		builder.WithAnnotations(credentials.Annotations(decisions))
+	}
 
 	cmItems := []corev1.KeyToPath{
 		{
-			Key:  "config.js",
-			Path: "config.js",
+			Key:  renovateConfigFile,
+			Path: renovateConfigFile,
 		},
 	}
 	cmOpts := tekton.NewMountOptions().WithTaskName("build").WithStepNames([]string{"renovate"})
-	builder.WithConfigMap(name, "/etc/renovate/config", cmItems, cmOpts)
+	builder.WithConfigMap(name, tekton.RenovateConfigDir, cmItems, cmOpts).
+		WithRenovateConfigFile(renovateConfigFile)
 
-	secretItems := []corev1.KeyToPath{
-		{
-			Key:  "renovate-token",
-			Path: "renovate-token",
-		},
+	if tokenBroker.Enabled {
+		builder.WithTokenBroker(tokenBroker.URL, tokenBroker.Audience).
+			WithGitTokenRefresh(comp.GetPlatform(), comp.GetHost())
+		caOpts := tekton.NewMountOptions().
+			WithTaskName("build").
+			WithStepNames([]string{"renovate"}).
+			WithReadOnly(true).
+			WithOptional(true)
+		builder.WithConfigMap(
+			"openshift-service-ca.crt",
+			tekton.TokenBrokerCADir,
+			[]corev1.KeyToPath{
+				{Key: "service-ca.crt", Path: "ca.crt"},
+			},
+			caOpts,
+		)
+	} else {
+		secretItems := []corev1.KeyToPath{
+			{
+				Key:  "renovate-token",
+				Path: "renovate-token",
+			},
+		}
+		secretOpts := tekton.NewMountOptions().WithTaskName("build").WithStepNames([]string{"renovate"})
+		builder.WithSecret(name, "/etc/renovate/secret", secretItems, secretOpts)
+	}
+
+	if config.Get().Presets.Enabled {
+		builder.WithPresetsCA()
+	}
+
+	if sshCredential != nil {
+		if tokenBroker.Enabled {
+			builder.WithBrokeredSSHKey(len(sshCredential.SSHKnownHosts) > 0)
+		} else {
+			builder.WithSSHKey(name, len(sshCredential.SSHKnownHosts) > 0)
+		}
 	}
-	secretOpts := tekton.NewMountOptions().WithTaskName("build").WithStepNames([]string{"renovate"})
-	builder.WithSecret(name, "/etc/renovate/secret", secretItems, secretOpts)
 
 	if rpmKeyErr == nil {
 		rpmSecretItems := []corev1.KeyToPath{
@@ -345,6 +584,40 @@ func (r *DependencyUpdateCheckReconciler) createPipelineRun(ctx context.Context,
 		builder.WithSecret(name+"-rpm-key", "/etc/renovate/secret", rpmSecretItems, rpmSecretOpts)
 	}
 
+	// Every secret MintMaker mounted into the run is redacted from the log,
+	// whether or not leaktk recognizes it. Tokens and SSH keys fetched from the
+	// token broker at runtime are redacted by the fingerprints the renovate step
+	// records, see WithTokenBroker. The GitHub token is only added to the Secret
+	// once the build pod mounts it, so it is optional here.
+	var knownSecretItems []corev1.KeyToPath
+	if !tokenBroker.Enabled {
+		knownSecretItems = append(knownSecretItems, corev1.KeyToPath{Key: "renovate-token", Path: "renovate-token"})
+	}
+	if sshCredential != nil && !tokenBroker.Enabled {
+		knownSecretItems = append(knownSecretItems, corev1.KeyToPath{Key: corev1.SSHAuthPrivateKey, Path: "ssh-privatekey"})
+	}
+	if len(knownSecretItems) > 0 {
+		builder.WithKnownSecrets(corev1.SecretProjection{
+			LocalObjectReference: corev1.LocalObjectReference{Name: name},
+			Items:                knownSecretItems,
+			Optional:             ptr.To(true),
+		})
+	}
+	if rpmKeyErr == nil {
+		builder.WithKnownSecrets(corev1.SecretProjection{
+			LocalObjectReference: corev1.LocalObjectReference{Name: name + "-rpm-key"},
+			Items: []corev1.KeyToPath{
+				{Key: "activationkey", Path: "rpm-activationkey"},
+				{Key: "org", Path: "rpm-org"},
+			},
+		})
+	}
+	if knownSecret != nil {
+		builder.WithKnownSecrets(corev1.SecretProjection{
+			LocalObjectReference: corev1.LocalObjectReference{Name: knownSecret.Name},
+		})
+	}
+
 	// Check if a ConfigMap with the label `config.openshift.io/inject-trusted-cabundle: "true"` exists.
 	// If such a ConfigMap is found, add a volume to the PipelineRun specification to mount this ConfigMap.
 	// The volume will be mounted at '/etc/pki/ca-trust/extracted/pem' within the PipelineRun Pod.
@@ -378,7 +651,7 @@ func (r *DependencyUpdateCheckReconciler) createPipelineRun(ctx context.Context,
 	if kiteSecretName != "" {
 		builder.WithKiteIntegration(config.Get().Kite.APIURL)
 		opts := tekton.NewMountOptions().
-			WithTaskName("build").
+			WithTaskName(tekton.LogAnalyzerTaskName).
 			WithStepNames([]string{"log-analyzer"}).
 			WithReadOnly(true)
 		builder.WithConfigMap(
@@ -397,7 +670,7 @@ func (r *DependencyUpdateCheckReconciler) createPipelineRun(ctx context.Context,
 			},
 		}
 		tokenSecretOpts := tekton.NewMountOptions().
-			WithTaskName("build").
+			WithTaskName(tekton.LogAnalyzerTaskName).
 			WithStepNames([]string{"log-analyzer"}).
 			WithReadOnly(true)
 		builder.WithSecret(kiteSecretName, "/var/run/secrets/kite", tokenSecretItems, tokenSecretOpts)
@@ -432,6 +705,16 @@ func (r *DependencyUpdateCheckReconciler) createPipelineRun(ctx context.Context,
 		}
 	}
 
+	// ownership for the known secrets Secret
+	if knownSecret != nil {
+		if err := controllerutil.SetOwnerReference(pipelineRun, knownSecret, r.Scheme); err != nil {
+			return nil, err
+		}
+		if err := r.Client.Update(ctx, knownSecret); err != nil {
+			return nil, err
+		}
+	}
+
 	// ownership for the renovateConfigMap
 	if err := controllerutil.SetOwnerReference(pipelineRun, renovateConfigMap, r.Scheme); err != nil {
 		return nil, err
@@ -451,10 +734,13 @@ func (r *DependencyUpdateCheckReconciler) createPipelineRun(ctx context.Context,
 // +kubebuilder:rbac:groups=tekton.dev,resources=pipelineruns,verbs=get;list;watch;create;update;patch
 // +kubebuilder:rbac:groups=tekton.dev,resources=pipelineruns/finalizers,verbs=update
 // +kubebuilder:rbac:groups=tekton.dev,resources=pipelineruns/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=tekton.dev,resources=pipelines,verbs=get
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=config.openshift.io,resources=imagedigestmirrorsets,verbs=get;list;watch
 // +kubebuilder:rbac:groups=security.openshift.io,resources=securitycontextconstraints,resourceNames=anyuid,verbs=use
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -552,7 +838,10 @@ func (r *DependencyUpdateCheckReconciler) Reconcile(ctx context.Context, req ctr
 
 	// Track components for which we already created a PipelineRun
 	processedComponents := make([]string, 0)
+	var skippedComponents []mmv1alpha1.SkippedComponent
+	expiryHorizons := map[credentialScope]time.Duration{}
 
+	scheduling := podTemplate(config.Get().PodScheduling, dependencyupdatecheck.Spec.PodScheduling)
 	timestamp := time.Now().UTC().Format("01021504") // MMDDhhmm, from Go's time formatting reference date "20060102150405"
 	for _, appstudioComponent := range componentList {
 		compLog := log.WithValues("component", appstudioComponent.Name,
@@ -565,6 +854,11 @@ func (r *DependencyUpdateCheckReconciler) Reconcile(ctx context.Context, req ctr
 			continue
 		}
 
+		if skipped := validateCredentials(ctx, comp, expiryHorizons); skipped != nil {
+			skippedComponents = append(skippedComponents, *skipped)
+			continue
+		}
+
 		branches, err := comp.GetBranches()
 		if err != nil {
 			compLog.Info("couldn't find versions which are branches for component", "component", appstudioComponent.Name, "err", err)
@@ -602,9 +896,22 @@ func (r *DependencyUpdateCheckReconciler) Reconcile(ctx context.Context, req ctr
 				continue
 			}
 
+			sizeProfile := r.sizeProfile(ctx, &appstudioComponent, host, repository, branchName)
 			plrName := fmt.Sprintf("renovate-%s-%s", timestamp, utils.RandomString(8))
-			pipelinerun, err := r.createPipelineRun(ctx, plrName, comp, branchName, kiteSecretName)
-			if err != nil {
+			pipelinerun, err := r.createPipelineRun(ctx, plrName, comp, branchName, kiteSecretName, sizeProfile, scheduling)
+			if schemaErr, ok := renovateconfig.AsSchemaError(err); ok {
+				branchLog.Info("skipping branch, generated Renovate config is invalid", "path", schemaErr.Path, "message", schemaErr.Message)
+				message := fmt.Sprintf("branch %s: %s: %s", branchName, schemaErr.Path, schemaErr.Message)
+				skippedComponents = append(skippedComponents, mmv1alpha1.SkippedComponent{
+					Namespace: comp.GetNamespace(),
+					Name:      comp.GetName(),
+					Reason:    InvalidRenovateConfigReason,
+					Message:   message,
+				})
+				r.Recorder.Eventf(dependencyupdatecheck, nil, corev1.EventTypeWarning, InvalidRenovateConfigReason, "CreatePipelineRun",
+					"Component %s/%s %s", comp.GetNamespace(), comp.GetName(), message)
+				mintmakermetrics.CountScheduledRunFailure()
+			} else if err != nil {
 				branchLog.Error(err, "failed to create PipelineRun")
 				mintmakermetrics.CountScheduledRunFailure()
 			} else {
@@ -614,11 +921,69 @@ func (r *DependencyUpdateCheckReconciler) Reconcile(ctx context.Context, req ctr
 		}
 	}
 
+	for scope, horizon := range expiryHorizons {
+		mintmakermetrics.RecordSCMCredentialExpiryHorizon(scope.namespace, scope.host, horizon)
+	}
+
+	if len(skippedComponents) > 0 {
+		patch := client.MergeFrom(dependencyupdatecheck.DeepCopy())
+		dependencyupdatecheck.Status.SkippedComponents = skippedComponents
+		if err := r.Client.Status().Patch(ctx, dependencyupdatecheck, patch); err != nil {
+			log.Error(err, "failed to update DependencyUpdateCheck status")
+		}
+	}
+
 	return ctrl.Result{}, nil
 }
 
+// credentialScope identifies the credentials of a git host within a namespace
+type credentialScope struct {
+	namespace string
+	host      string
+}
+
+// validateCredentials runs the pre-flight credential validation of a component
+// and returns it as skipped when its credentials can't be used. Errors reaching
+// the git host are only logged, so an unavailable API doesn't block the run.
+// The shortest expiry horizon per namespace and host is kept in horizons.
+func validateCredentials(ctx context.Context, comp component.GitComponent, horizons map[credentialScope]time.Duration) *mmv1alpha1.SkippedComponent {
+	log := ctrllog.FromContext(ctx)
+
+	validation, err := comp.ValidateCredentials()
+	if err != nil {
+		validationErr, ok := credentials.AsValidationError(err)
+		if !ok {
+			log.Error(err, "failed to validate credentials, continuing without validation")
+			return nil
+		}
+		log.Info("skipping component, credentials failed validation", "reason", validationErr.Reason, "message", validationErr.Message)
+		return &mmv1alpha1.SkippedComponent{
+			Namespace: comp.GetNamespace(),
+			Name:      comp.GetName(),
+			Reason:    string(validationErr.Reason),
+			Message:   validationErr.Message,
+		}
+	}
+
+	if !validation.ExpiresAt.IsZero() {
+		scope := credentialScope{namespace: comp.GetNamespace(), host: comp.GetHost()}
+		horizon := time.Until(validation.ExpiresAt)
+		if current, ok := horizons[scope]; !ok || horizon < current {
+			horizons[scope] = horizon
+		}
+	}
+	return nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *DependencyUpdateCheckReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorder("dependencyupdatecheck-controller")
+	}
+	if r.PipelineTemplates == nil {
+		templateConfig := config.Get().PipelineRunTemplate
+		r.PipelineTemplates = tekton.NewTemplateLoader(mmconst.MintMakerNamespaceName, templateConfig.ConfigMap, templateConfig.Pipeline)
+	}
 	// We only react to Create events for DependencyUpdateCheck in mintmaker namespace.
 	// Namespace filtering is handled by the manager's cache configuration.
 	return ctrl.NewControllerManagedBy(mgr).
@@ -24,13 +24,19 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
+	mmv1alpha1 "github.com/konflux-ci/mintmaker/api/v1alpha1"
 	"github.com/konflux-ci/mintmaker/internal/component"
 	"github.com/konflux-ci/mintmaker/internal/component/mocks"
+	"github.com/konflux-ci/mintmaker/internal/config"
 	. "github.com/konflux-ci/mintmaker/internal/constant"
+	"github.com/konflux-ci/mintmaker/internal/credentials"
+	"github.com/konflux-ci/mintmaker/internal/renovateconfig"
+	"github.com/konflux-ci/mintmaker/internal/tekton"
 	"github.com/konflux-ci/mintmaker/internal/utils"
 )
 
@@ -82,6 +88,8 @@ var _ = Describe("DependencyUpdateCheck Controller", func() {
 					mockComp.EXPECT().GetRepository().Return("testcomp").Maybe()
 					mockComp.EXPECT().GetRenovateConfig(mock.Anything, mock.Anything).Return("mock config", nil).Maybe()
 					mockComp.EXPECT().GetRPMActivationKey(mock.Anything, mock.Anything).Return("", "", fmt.Errorf("no rpm key")).Maybe()
+					mockComp.EXPECT().ExplainCredentials().Return(nil, nil).Maybe()
+					mockComp.EXPECT().ValidateCredentials().Return(credentials.Validation{}, nil).Maybe()
 					return mockComp, nil
 				}
 
@@ -99,6 +107,55 @@ var _ = Describe("DependencyUpdateCheck Controller", func() {
 				deleteDependencyUpdateCheck(dependencyUpdateCheckKey)
 			})
 
+			It("should build pipelineruns from the built-in template without a configured one", func() {
+				createDependencyUpdateCheck(dependencyUpdateCheckKey, false, nil)
+				Eventually(listPipelineRuns).WithArguments(MintMakerNamespaceName).Should(HaveLen(expectedPipelineRuns))
+				for _, pipelineRun := range listPipelineRuns(MintMakerNamespaceName) {
+					Expect(pipelineRun.Annotations).To(HaveKeyWithValue(tekton.PipelineTemplateAnnotation, tekton.PipelineTemplateBuiltIn))
+				}
+				deleteDependencyUpdateCheck(dependencyUpdateCheckKey)
+			})
+
+			It("should size the renovate step by the profile selected on the component", func() {
+				componentKey := types.NamespacedName{Name: componentName, Namespace: componentNamespace}
+				appComp := getComponent(componentKey)
+				appComp.Annotations = map[string]string{tekton.SizeProfileAnnotation: "large"}
+				Expect(k8sClient.Update(ctx, appComp)).To(Succeed())
+
+				createDependencyUpdateCheck(dependencyUpdateCheckKey, false, nil)
+				Eventually(listPipelineRuns).WithArguments(MintMakerNamespaceName).Should(HaveLen(expectedPipelineRuns))
+				for _, pipelineRun := range listPipelineRuns(MintMakerNamespaceName) {
+					Expect(pipelineRun.Annotations).To(HaveKeyWithValue(tekton.SizeProfileAnnotation, "large"))
+					for _, step := range pipelineRun.Spec.PipelineSpec.Tasks[0].TaskSpec.Steps {
+						if step.Name == "renovate" {
+							Expect(step.ComputeResources.Limits.Memory().String()).To(Equal("6Gi"))
+						}
+					}
+				}
+				deleteDependencyUpdateCheck(dependencyUpdateCheckKey)
+			})
+
+			It("should schedule pipelineruns as requested by the DependencyUpdateCheck", func() {
+				dependencyUpdateCheck := &mmv1alpha1.DependencyUpdateCheck{
+					ObjectMeta: metav1.ObjectMeta{Name: dependencyUpdateCheckKey.Name, Namespace: dependencyUpdateCheckKey.Namespace},
+					Spec: mmv1alpha1.DependencyUpdateCheckSpec{
+						PodScheduling: &mmv1alpha1.PodSchedulingSpec{
+							NodeSelector:      map[string]string{"node-role.kubernetes.io/renovate": ""},
+							PriorityClassName: "renovate",
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, dependencyUpdateCheck)).To(Succeed())
+				Eventually(listPipelineRuns).WithArguments(MintMakerNamespaceName).Should(HaveLen(expectedPipelineRuns))
+				for _, pipelineRun := range listPipelineRuns(MintMakerNamespaceName) {
+					podTemplate := pipelineRun.Spec.TaskRunTemplate.PodTemplate
+					Expect(podTemplate).NotTo(BeNil())
+					Expect(podTemplate.NodeSelector).To(HaveKeyWithValue("node-role.kubernetes.io/renovate", ""))
+					Expect(*podTemplate.PriorityClassName).To(Equal("renovate"))
+				}
+				deleteDependencyUpdateCheck(dependencyUpdateCheckKey)
+			})
+
 			It("should create pipelineruns only for versions that are branches (filter out tags)", func() {
 				if crdVersion != "v2" {
 					return
@@ -116,6 +173,8 @@ var _ = Describe("DependencyUpdateCheck Controller", func() {
 					mockComp.EXPECT().GetRepository().Return("testcomp").Maybe()
 					mockComp.EXPECT().GetRenovateConfig(mock.Anything, mock.Anything).Return("mock config", nil).Maybe()
 					mockComp.EXPECT().GetRPMActivationKey(mock.Anything, mock.Anything).Return("", "", fmt.Errorf("no rpm key")).Maybe()
+					mockComp.EXPECT().ExplainCredentials().Return(nil, nil).Maybe()
+					mockComp.EXPECT().ValidateCredentials().Return(credentials.Validation{}, nil).Maybe()
 					return mockComp, nil
 				}
 				createDependencyUpdateCheck(dependencyUpdateCheckKey, false, nil)
@@ -123,6 +182,59 @@ var _ = Describe("DependencyUpdateCheck Controller", func() {
 				deleteDependencyUpdateCheck(dependencyUpdateCheckKey)
 			})
 
+			It("should skip components whose credentials fail validation and report them in the status", func() {
+				gt := GinkgoT()
+				newGitComponentForTest = func(_ context.Context, appComp *appstudiov1alpha1.Component, _ client.Client) (component.GitComponent, error) {
+					mockComp := mocks.NewMockGitComponent(gt)
+					mockComp.EXPECT().GetName().Return(appComp.Name).Maybe()
+					mockComp.EXPECT().GetNamespace().Return(appComp.Namespace).Maybe()
+					mockComp.EXPECT().GetHost().Return("gitlab.com").Maybe()
+					mockComp.EXPECT().ValidateCredentials().Return(credentials.Validation{},
+						credentials.NewValidationError(credentials.ValidationReasonExpired, "credential expired")).Maybe()
+					return mockComp, nil
+				}
+				createDependencyUpdateCheck(dependencyUpdateCheckKey, false, nil)
+				Eventually(func() []mmv1alpha1.SkippedComponent {
+					return getDependencyUpdateCheck(dependencyUpdateCheckKey).Status.SkippedComponents
+				}).Should(ConsistOf(mmv1alpha1.SkippedComponent{
+					Namespace: componentNamespace,
+					Name:      componentName,
+					Reason:    string(credentials.ValidationReasonExpired),
+					Message:   "credential expired",
+				}))
+				Consistently(listPipelineRuns).WithArguments(MintMakerNamespaceName).Should(HaveLen(0))
+				deleteDependencyUpdateCheck(dependencyUpdateCheckKey)
+			})
+
+			It("should skip components whose Renovate config fails schema validation and report the error path", func() {
+				gt := GinkgoT()
+				newGitComponentForTest = func(_ context.Context, appComp *appstudiov1alpha1.Component, _ client.Client) (component.GitComponent, error) {
+					mockComp := mocks.NewMockGitComponent(gt)
+					mockComp.EXPECT().GetBranches().Return([]string{"main"}, nil).Maybe()
+					mockComp.EXPECT().GetName().Return(appComp.Name).Maybe()
+					mockComp.EXPECT().GetNamespace().Return(appComp.Namespace).Maybe()
+					mockComp.EXPECT().GetApplication().Return(appComp.Spec.Application).Maybe()
+					mockComp.EXPECT().GetPlatform().Return("github").Maybe()
+					mockComp.EXPECT().GetHost().Return("github.com").Maybe()
+					mockComp.EXPECT().GetRepository().Return("testcomp").Maybe()
+					mockComp.EXPECT().GetRenovateConfig(mock.Anything, mock.Anything).Return("",
+						&renovateconfig.SchemaError{Path: "/packageRules/0/rebaseWhen", Message: "value must be one of the allowed values"}).Maybe()
+					mockComp.EXPECT().ValidateCredentials().Return(credentials.Validation{}, nil).Maybe()
+					return mockComp, nil
+				}
+				createDependencyUpdateCheck(dependencyUpdateCheckKey, false, nil)
+				Eventually(func() []mmv1alpha1.SkippedComponent {
+					return getDependencyUpdateCheck(dependencyUpdateCheckKey).Status.SkippedComponents
+				}).Should(ConsistOf(mmv1alpha1.SkippedComponent{
+					Namespace: componentNamespace,
+					Name:      componentName,
+					Reason:    InvalidRenovateConfigReason,
+					Message:   "branch main: /packageRules/0/rebaseWhen: value must be one of the allowed values",
+				}))
+				Consistently(listPipelineRuns).WithArguments(MintMakerNamespaceName).Should(HaveLen(0))
+				deleteDependencyUpdateCheck(dependencyUpdateCheckKey)
+			})
+
 			It("should not create a pipelinerun if the DependencyUpdateCheck CR has been processed before", func() {
 				// Create a DependencyUpdateCheck CR in "mintmaker" namespace, that was processed before
 				createDependencyUpdateCheck(dependencyUpdateCheckKey, true, nil)
@@ -314,3 +426,36 @@ var _ = Describe("DependencyUpdateCheck Controller", func() {
 		})
 	}
 })
+
+var _ = Describe("Pod scheduling", func() {
+
+	global := config.PodSchedulingConfig{
+		NodeSelector:      map[string]string{"pool": "renovate"},
+		Tolerations:       []corev1.Toleration{{Key: "renovate", Operator: corev1.TolerationOpExists}},
+		PriorityClassName: "renovate",
+	}
+
+	It("should return nil when nothing is set", func() {
+		Expect(podTemplate(config.PodSchedulingConfig{}, nil)).To(BeNil())
+		Expect(podTemplate(config.PodSchedulingConfig{}, &mmv1alpha1.PodSchedulingSpec{})).To(BeNil())
+	})
+
+	It("should use the MintMaker config without an override", func() {
+		template := podTemplate(global, nil)
+		Expect(template.NodeSelector).To(Equal(global.NodeSelector))
+		Expect(template.Tolerations).To(Equal(global.Tolerations))
+		Expect(*template.PriorityClassName).To(Equal("renovate"))
+		Expect(template.RuntimeClassName).To(BeNil())
+	})
+
+	It("should replace the fields set in the DependencyUpdateCheck", func() {
+		template := podTemplate(global, &mmv1alpha1.PodSchedulingSpec{
+			NodeSelector:     map[string]string{"pool": "large"},
+			RuntimeClassName: "kata",
+		})
+		Expect(template.NodeSelector).To(Equal(map[string]string{"pool": "large"}))
+		Expect(template.Tolerations).To(Equal(global.Tolerations))
+		Expect(*template.PriorityClassName).To(Equal("renovate"))
+		Expect(*template.RuntimeClassName).To(Equal("kata"))
+	})
+})
@@ -32,6 +32,7 @@ import (
 	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
 
 	component "github.com/konflux-ci/mintmaker/internal/component"
+	mmconst "github.com/konflux-ci/mintmaker/internal/constant"
 )
 
 // EventReconciler reconciles a Event object
@@ -153,8 +154,8 @@ func (r *EventReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	}
 
 	log = log.WithValues(
-		"component", pod.Labels[MintMakerComponentNameLabel],
-		"componentNamespace", pod.Labels[MintMakerComponentNamespaceLabel],
+		"component", pod.Labels[mmconst.MintMakerComponentNameLabel],
+		"componentNamespace", pod.Labels[mmconst.MintMakerComponentNamespaceLabel],
 		"pipelineRun", pod.Labels["tekton.dev/pipelineRun"],
 		"repository", strings.ReplaceAll(pod.Labels["mintmaker.appstudio.redhat.com/repository"], "_", "/"),
 		"gitHost", pod.Labels["mintmaker.appstudio.redhat.com/git-host"],
@@ -191,8 +192,8 @@ func (r *EventReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 			secret.Data = make(map[string][]byte)
 		}
 
-		componentName := pod.Labels[MintMakerComponentNameLabel]
-		componentNamespace := pod.Labels[MintMakerComponentNamespaceLabel]
+		componentName := pod.Labels[mmconst.MintMakerComponentNameLabel]
+		componentNamespace := pod.Labels[mmconst.MintMakerComponentNamespaceLabel]
 
 		// Get the component
 		var comp appstudiov1alpha1.Component
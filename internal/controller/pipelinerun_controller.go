@@ -16,23 +16,33 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"knative.dev/pkg/apis"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+
+	"github.com/konflux-ci/mintmaker/internal/config"
+	mmconst "github.com/konflux-ci/mintmaker/internal/constant"
+	mintmakermetrics "github.com/konflux-ci/mintmaker/internal/metrics"
+	"github.com/konflux-ci/mintmaker/internal/tekton"
 )
 
 var (
-	MintMakerGitPlatformLabel        = "mintmaker.appstudio.redhat.com/git-platform"
-	MintMakerComponentNameLabel      = "mintmaker.appstudio.redhat.com/component"
-	MintMakerComponentNamespaceLabel = "mintmaker.appstudio.redhat.com/namespace"
+	MintMakerGitPlatformLabel = "mintmaker.appstudio.redhat.com/git-platform"
 )
 
 // PipelineRunReconciler reconciles a PipelineRun object
@@ -41,10 +51,308 @@ type PipelineRunReconciler struct {
 	Scheme *runtime.Scheme
 }
 
-// +kubebuilder:rbac:groups=tekton.dev,resources=pipelineruns,verbs=get;list;watch
+// +kubebuilder:rbac:groups=tekton.dev,resources=pipelineruns,verbs=get;list;watch;create;patch
+// +kubebuilder:rbac:groups=tekton.dev,resources=taskruns,verbs=get
+// +kubebuilder:rbac:groups="",resources=pods,verbs=list
+// +kubebuilder:rbac:groups="",resources=secrets;configmaps,verbs=get;update
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=create
 
+// Reconcile records the results of finished PipelineRuns and creates failed
+// PipelineRuns again when they failed for a transient reason, after a backoff
+// and up to the configured number of retries.
 func (r *PipelineRunReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	return ctrl.Result{}, nil
+	log := ctrllog.FromContext(ctx).WithName("PipelineRunController")
+	ctx = ctrllog.IntoContext(ctx, log)
+
+	pipelineRun := &tektonv1.PipelineRun{}
+	if err := r.Client.Get(ctx, req.NamespacedName, pipelineRun); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !pipelineRun.IsDone() {
+		return ctrl.Result{}, nil
+	}
+	if err := r.collectResults(ctx, pipelineRun); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	retryConfig := config.Get().PipelineRunRetry
+	if retryConfig.MaxRetries == 0 || pipelineRun.Labels[mmconst.MintMakerRepoBranchHashLabel] == "" {
+		return ctrl.Result{}, nil
+	}
+
+	attempt := 1
+	if previous, err := strconv.Atoi(pipelineRun.Labels[mmconst.MintMakerRetryAttemptLabel]); err == nil {
+		attempt = previous + 1
+	}
+	if attempt > retryConfig.MaxRetries {
+		return ctrl.Result{}, nil
+	}
+
+	reason, err := r.classifyFailure(ctx, pipelineRun)
+	if err != nil || reason == "" {
+		return ctrl.Result{}, err
+	}
+	log = log.WithValues("pipelineRun", pipelineRun.Name, "reason", reason, "attempt", attempt)
+	ctx = ctrllog.IntoContext(ctx, log)
+
+	// A retry or the next scheduled run already covers the repo+branch
+	superseded, err := r.superseded(ctx, pipelineRun)
+	if err != nil || superseded {
+		return ctrl.Result{}, err
+	}
+
+	if pipelineRun.Status.CompletionTime != nil {
+		retryAt := pipelineRun.Status.CompletionTime.Add(retryBackoff(retryConfig, attempt))
+		if wait := time.Until(retryAt); wait > 0 {
+			log.Info("retrying failed PipelineRun after backoff", "retryAt", retryAt.Format(time.RFC3339))
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+	}
+	return ctrl.Result{}, r.retry(ctx, pipelineRun, reason, attempt, retryConfig.SizeUpOnOOM)
+}
+
+// collectResults logs and counts the results reported by the summarize task
+// of a finished PipelineRun, and stores its run report in a ConfigMap. An
+// annotation makes sure they are collected once.
+func (r *PipelineRunReconciler) collectResults(ctx context.Context, pipelineRun *tektonv1.PipelineRun) error {
+	if _, collected := pipelineRun.Annotations[mmconst.MintMakerResultsCollectedAnnotation]; collected {
+		return nil
+	}
+	results, hasResults := tekton.ParseRunResults(pipelineRun)
+	report, hasReport := tekton.RunReport(pipelineRun)
+	if !hasResults && !hasReport {
+		return nil
+	}
+	if hasReport {
+		if err := r.storeReport(ctx, pipelineRun, report); err != nil {
+			return err
+		}
+	}
+
+	patch := client.MergeFrom(pipelineRun.DeepCopy())
+	if pipelineRun.Annotations == nil {
+		pipelineRun.Annotations = map[string]string{}
+	}
+	pipelineRun.Annotations[mmconst.MintMakerResultsCollectedAnnotation] = "true"
+	if err := r.Client.Patch(ctx, pipelineRun, patch); err != nil {
+		return err
+	}
+
+	if !hasResults {
+		return nil
+	}
+	ctrllog.FromContext(ctx).Info("PipelineRun results", "pipelineRun", pipelineRun.Name,
+		"component", pipelineRun.Labels[mmconst.MintMakerComponentNameLabel],
+		"componentNamespace", pipelineRun.Labels[mmconst.MintMakerComponentNamespaceLabel],
+		"pullRequestsCreated", results.PullRequestsCreated,
+		"pullRequestsUpdated", results.PullRequestsUpdated,
+		"updatedDependencies", results.UpdatedDependencies,
+		"errors", results.Errors,
+	)
+	mintmakermetrics.CountRenovateRunResults(results.PullRequestsCreated, results.PullRequestsUpdated, results.UpdatedDependencies, results.Errors)
+	return nil
+}
+
+// storeReport creates the ConfigMap holding the run report of the PipelineRun,
+// named after it and owned by it
+func (r *PipelineRunReconciler) storeReport(ctx context.Context, pipelineRun *tektonv1.PipelineRun, report string) error {
+	labels := map[string]string{mmconst.MintMakerRunReportLabel: "true"}
+	for _, label := range []string{mmconst.MintMakerComponentNameLabel, mmconst.MintMakerComponentNamespaceLabel, mmconst.MintMakerGitHostLabel} {
+		if value, ok := pipelineRun.Labels[label]; ok {
+			labels[label] = value
+		}
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pipelineRun.Name + "-report",
+			Namespace: pipelineRun.Namespace,
+			Labels:    labels,
+		},
+		Data: map[string]string{mmconst.RunReportConfigMapKey: report},
+	}
+	if err := controllerutil.SetOwnerReference(pipelineRun, configMap, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Client.Create(ctx, configMap); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// retryBackoff returns the wait before the given retry attempt, doubled for
+// every attempt up to the configured maximum
+func retryBackoff(retryConfig config.PipelineRunRetryConfig, attempt int) time.Duration {
+	backoff := retryConfig.Backoff
+	for i := 1; i < attempt && backoff < retryConfig.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, retryConfig.MaxBackoff)
+}
+
+// classifyFailure reads the TaskRuns and pods of the PipelineRun and returns
+// why it failed, empty when the failure is not worth retrying
+func (r *PipelineRunReconciler) classifyFailure(ctx context.Context, pipelineRun *tektonv1.PipelineRun) (tekton.FailureReason, error) {
+	if !pipelineRun.Status.GetCondition(apis.ConditionSucceeded).IsFalse() {
+		return "", nil
+	}
+
+	var taskRuns []tektonv1.TaskRun
+	for _, child := range pipelineRun.Status.ChildReferences {
+		if child.Kind != "TaskRun" {
+			continue
+		}
+		taskRun := tektonv1.TaskRun{}
+		if err := r.Client.Get(ctx, client.ObjectKey{Namespace: pipelineRun.Namespace, Name: child.Name}, &taskRun); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return "", err
+		}
+		taskRuns = append(taskRuns, taskRun)
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.Client.List(ctx, pods,
+		client.InNamespace(pipelineRun.Namespace),
+		client.MatchingLabels{"tekton.dev/pipelineRun": pipelineRun.Name},
+	); err != nil {
+		return "", err
+	}
+	return tekton.ClassifyFailure(pipelineRun, taskRuns, pods.Items), nil
+}
+
+// superseded returns true when a newer PipelineRun exists for the same
+// repo+branch
+func (r *PipelineRunReconciler) superseded(ctx context.Context, pipelineRun *tektonv1.PipelineRun) (bool, error) {
+	pipelineRuns := &tektonv1.PipelineRunList{}
+	if err := r.Client.List(ctx, pipelineRuns,
+		client.InNamespace(pipelineRun.Namespace),
+		client.MatchingLabels{mmconst.MintMakerRepoBranchHashLabel: pipelineRun.Labels[mmconst.MintMakerRepoBranchHashLabel]},
+	); err != nil {
+		return false, err
+	}
+	for _, pr := range pipelineRuns.Items {
+		if pipelineRun.CreationTimestamp.Before(&pr.CreationTimestamp) {
+			ctrllog.FromContext(ctx).Info("not retrying failed PipelineRun, a newer one exists", "newerPipelineRun", pr.Name)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// retry creates a copy of the failed PipelineRun with its retry lineage in
+// labels. The per-run Secrets and ConfigMaps become owned by the retry too.
+func (r *PipelineRunReconciler) retry(ctx context.Context, pipelineRun *tektonv1.PipelineRun, reason tekton.FailureReason, attempt int, sizeUpOnOOM bool) error {
+	log := ctrllog.FromContext(ctx)
+
+	root := pipelineRun.Labels[mmconst.MintMakerRetryRootLabel]
+	if root == "" {
+		root = pipelineRun.Name
+	}
+	// The name is deterministic, so a retry is never created twice
+	builder := tekton.NewPipelineRunBuilderFromPipelineRun(fmt.Sprintf("%s-retry-%d", root, attempt), pipelineRun).
+		WithLabels(map[string]string{
+			mmconst.MintMakerRetryRootLabel:    root,
+			mmconst.MintMakerRetryParentLabel:  pipelineRun.Name,
+			mmconst.MintMakerRetryAttemptLabel: strconv.Itoa(attempt),
+			mmconst.MintMakerRetryReasonLabel:  string(reason),
+		})
+	if reason == tekton.FailureOOMKilled && sizeUpOnOOM {
+		if profile, ok := tekton.NextSizeProfile(pipelineRun); ok {
+			builder.WithSizeProfile(profile)
+		}
+	}
+	retryRun, err := builder.Build()
+	if err != nil {
+		return err
+	}
+
+	resources, err := r.runResources(ctx, pipelineRun)
+	if err != nil {
+		return err
+	}
+	// GitHub tokens expire after an hour, drop the one of the failed run so the
+	// event controller mints a fresh one when the retry's pod mounts the Secret
+	for _, resource := range resources {
+		if secret, ok := resource.(*corev1.Secret); ok && pipelineRun.Labels[MintMakerGitPlatformLabel] == "github" {
+			if _, found := secret.Data["renovate-token"]; found {
+				delete(secret.Data, "renovate-token")
+				if err := r.Client.Update(ctx, secret); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if err := r.Client.Create(ctx, retryRun); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return nil
+		}
+		return err
+	}
+	log.Info("retried failed PipelineRun", "retryPipelineRun", retryRun.Name)
+	mintmakermetrics.CountPipelineRunRetry(string(reason))
+
+	for _, resource := range resources {
+		if err := controllerutil.SetOwnerReference(retryRun, resource, r.Scheme); err != nil {
+			return err
+		}
+		if err := r.Client.Update(ctx, resource); err != nil {
+			log.Error(err, "failed to share per-run resource with the retry", "resource", resource.GetName())
+		}
+	}
+	return nil
+}
+
+// runResources returns the Secrets and ConfigMaps mounted by the PipelineRun
+// that it owns
+func (r *PipelineRunReconciler) runResources(ctx context.Context, pipelineRun *tektonv1.PipelineRun) ([]client.Object, error) {
+	var resources []client.Object
+	if pipelineRun.Spec.PipelineSpec == nil {
+		return nil, nil
+	}
+	seen := map[string]bool{}
+	for _, task := range pipelineRun.Spec.PipelineSpec.Tasks {
+		if task.TaskSpec == nil {
+			continue
+		}
+		var candidates []client.Object
+		for _, volume := range task.TaskSpec.Volumes {
+			switch {
+			case volume.Secret != nil:
+				candidates = append(candidates, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: volume.Secret.SecretName}})
+			case volume.ConfigMap != nil:
+				candidates = append(candidates, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: volume.ConfigMap.Name}})
+			case volume.Projected != nil:
+				// The known secrets of the log sanitizer
+				for _, source := range volume.Projected.Sources {
+					if source.Secret != nil {
+						candidates = append(candidates, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: source.Secret.Name}})
+					}
+				}
+			}
+		}
+		for _, resource := range candidates {
+			key := fmt.Sprintf("%T/%s", resource, resource.GetName())
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if err := r.Client.Get(ctx, client.ObjectKey{Namespace: pipelineRun.Namespace, Name: resource.GetName()}, resource); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			for _, owner := range resource.GetOwnerReferences() {
+				if owner.UID == pipelineRun.UID {
+					resources = append(resources, resource)
+					break
+				}
+			}
+		}
+	}
+	return resources, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -55,7 +363,10 @@ func (r *PipelineRunReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&tektonv1.PipelineRun{}).
 		WithEventFilter(predicate.Funcs{
 			CreateFunc: func(e event.CreateEvent) bool {
-				return false
+				// Picks up failed PipelineRuns waiting for a retry when the
+				// manager starts
+				pipelineRun, ok := e.Object.(*tektonv1.PipelineRun)
+				return ok && pipelineRun.IsDone() && pipelineRun.Status.GetCondition(apis.ConditionSucceeded).IsFalse()
 			},
 			DeleteFunc: func(e event.DeleteEvent) bool {
 				return false
@@ -68,8 +379,8 @@ func (r *PipelineRunReconciler) SetupWithManager(mgr ctrl.Manager) error {
 								log := ctrl.Log.WithName("PipelineRunController")
 								log.Info(
 									"PipelineRun finished", "pipelineRun", newPipelineRun.Name,
-									"component", newPipelineRun.Labels[MintMakerComponentNameLabel],
-									"componentNamespace", newPipelineRun.Labels[MintMakerComponentNamespaceLabel],
+									"component", newPipelineRun.Labels[mmconst.MintMakerComponentNameLabel],
+									"componentNamespace", newPipelineRun.Labels[mmconst.MintMakerComponentNamespaceLabel],
 									"repository", strings.ReplaceAll(newPipelineRun.Labels["mintmaker.appstudio.redhat.com/repository"], "_", "/"),
 									"gitHost", newPipelineRun.Labels["mintmaker.appstudio.redhat.com/git-host"],
 									"completionTime",
@@ -77,7 +388,7 @@ func (r *PipelineRunReconciler) SetupWithManager(mgr ctrl.Manager) error {
 									"success",
 									newPipelineRun.Status.GetCondition(apis.ConditionSucceeded).IsTrue(),
 									"reason",
-									newPipelineRun.Status.GetCondition(apis.ConditionSucceeded).GetReason(),
+									tekton.FinishedReason(newPipelineRun),
 								)
 							}
 							return true
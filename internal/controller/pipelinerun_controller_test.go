@@ -16,12 +16,18 @@ package controller
 
 import (
 	"bytes"
+	"fmt"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	. "github.com/konflux-ci/mintmaker/internal/constant"
 	tekton "github.com/konflux-ci/mintmaker/internal/tekton"
@@ -128,5 +134,111 @@ var _ = Describe("PipelineRun Controller", func() {
 				g.Expect(logOutput).To(ContainSubstring("\"reason\": \"Cancelled\""))
 			}, timeout, interval).Should(Succeed())
 		})
+
+		It("should log a distinct reason if renovate failed", func() {
+			Expect(k8sClient.Get(ctx, plrLookupKey, plr)).To(Succeed())
+
+			plr.Status.Results = []tektonv1.PipelineRunResult{
+				{Name: tekton.RenovateExitCodeResult, Value: *tektonv1.NewStructuredValues("1")},
+			}
+			plr.Status.MarkFailed(string(tektonv1.PipelineRunReasonFailed), "%s")
+			Expect(k8sClient.Status().Update(ctx, plr)).Should(Succeed())
+			Eventually(func(g Gomega) {
+				logOutput := logBuffer.String()
+				g.Expect(logOutput).To(ContainSubstring("PipelineRun finished"))
+				g.Expect(logOutput).To(ContainSubstring("\"success\": false"))
+				g.Expect(logOutput).To(ContainSubstring("\"reason\": \"RenovateFailed\""))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("should record the results and the report of a finished pipelinerun once", func() {
+			Expect(k8sClient.Get(ctx, plrLookupKey, plr)).To(Succeed())
+
+			plr.Status.Results = []tektonv1.PipelineRunResult{
+				{Name: tekton.PullRequestsCreatedResult, Value: *tektonv1.NewStructuredValues("2")},
+				{Name: tekton.ErrorsResult, Value: *tektonv1.NewStructuredValues("1")},
+				{Name: tekton.ReportResult, Value: *tektonv1.NewStructuredValues(`{"errorCount":1}`)},
+			}
+			plr.Status.MarkSucceeded(string(tektonv1.PipelineRunReasonSuccessful), "%s")
+			Expect(k8sClient.Status().Update(ctx, plr)).Should(Succeed())
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, plrLookupKey, plr)).To(Succeed())
+				g.Expect(plr.Annotations).To(HaveKeyWithValue(MintMakerResultsCollectedAnnotation, "true"))
+				logOutput := logBuffer.String()
+				g.Expect(logOutput).To(ContainSubstring("PipelineRun results"))
+				g.Expect(logOutput).To(ContainSubstring("\"pullRequestsCreated\": 2"))
+				g.Expect(logOutput).To(ContainSubstring("\"errors\": 1"))
+			}, timeout, interval).Should(Succeed())
+
+			report := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: plrName + "-report", Namespace: MintMakerNamespaceName}, report)).To(Succeed())
+			Expect(report.Labels).To(HaveKeyWithValue(MintMakerRunReportLabel, "true"))
+			Expect(report.Data).To(HaveKeyWithValue(RunReportConfigMapKey, `{"errorCount":1}`))
+			Expect(report.OwnerReferences).To(HaveLen(1))
+			Expect(report.OwnerReferences[0].Name).To(Equal(plrName))
+		})
+	})
+
+	Context("When a pipelinerun fails for a transient reason", func() {
+
+		plrName := "test-failed-plr"
+		plrLookupKey := types.NamespacedName{Name: plrName, Namespace: MintMakerNamespaceName}
+		retryLookupKey := types.NamespacedName{Name: plrName + "-retry-1", Namespace: MintMakerNamespaceName}
+
+		failPipelineRun := func(taskRunReason tektonv1.TaskRunReason) {
+			taskRun := &tektonv1.TaskRun{
+				ObjectMeta: metav1.ObjectMeta{Name: plrName + "-build", Namespace: MintMakerNamespaceName},
+				Spec:       tektonv1.TaskRunSpec{TaskSpec: &tektonv1.TaskSpec{Steps: []tektonv1.Step{{Name: "renovate", Image: "renovate"}}}},
+			}
+			Expect(k8sClient.Create(ctx, taskRun)).To(Succeed())
+			taskRun.Status.MarkResourceFailed(taskRunReason, fmt.Errorf("failed"))
+			Expect(k8sClient.Status().Update(ctx, taskRun)).To(Succeed())
+
+			plr := &tektonv1.PipelineRun{}
+			Expect(k8sClient.Get(ctx, plrLookupKey, plr)).To(Succeed())
+			plr.Status.ChildReferences = []tektonv1.ChildStatusReference{
+				{TypeMeta: runtime.TypeMeta{Kind: "TaskRun"}, Name: taskRun.Name, PipelineTaskName: "build"},
+			}
+			plr.Status.MarkFailed(string(tektonv1.PipelineRunReasonFailed), "%s", "failed")
+			Expect(k8sClient.Status().Update(ctx, plr)).To(Succeed())
+		}
+
+		_ = BeforeEach(func() {
+			createNamespace(MintMakerNamespaceName)
+			setupPipelineRun(plrName, map[string]string{
+				MintMakerRepoBranchHashLabel: "testhash",
+				MintMakerGitPlatformLabel:    "gitlab",
+			}, 0)
+			// Let the controller's cache see the running PipelineRun first
+			time.Sleep(500 * time.Millisecond)
+		})
+
+		_ = AfterEach(func() {
+			teardownPipelineRuns()
+			Expect(k8sClient.DeleteAllOf(ctx, &tektonv1.TaskRun{}, client.InNamespace(MintMakerNamespaceName))).To(Succeed())
+		})
+
+		It("should retry an OOM killed pipelinerun with a larger profile and record its lineage", func() {
+			failPipelineRun(tektonv1.TaskRunReasonStepOOM)
+
+			retry := &tektonv1.PipelineRun{}
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, retryLookupKey, retry)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			Expect(retry.Labels).To(HaveKeyWithValue(MintMakerRepoBranchHashLabel, "testhash"))
+			Expect(retry.Labels).To(HaveKeyWithValue(MintMakerRetryRootLabel, plrName))
+			Expect(retry.Labels).To(HaveKeyWithValue(MintMakerRetryParentLabel, plrName))
+			Expect(retry.Labels).To(HaveKeyWithValue(MintMakerRetryAttemptLabel, "1"))
+			Expect(retry.Labels).To(HaveKeyWithValue(MintMakerRetryReasonLabel, string(tekton.FailureOOMKilled)))
+			Expect(retry.Annotations).To(HaveKeyWithValue(tekton.SizeProfileAnnotation, "large"))
+		})
+
+		It("should not retry a pipelinerun failing in a step", func() {
+			failPipelineRun(tektonv1.TaskRunReasonFailed)
+
+			Consistently(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, retryLookupKey, &tektonv1.PipelineRun{}))
+			}, time.Second, interval).Should(BeTrue())
+		})
 	})
 })
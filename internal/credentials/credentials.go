@@ -0,0 +1,92 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package credentials resolves which repository scoped secret applies to a
+// component and records how that decision was made.
+package credentials
+
+import "fmt"
+
+const annotationPrefix = "mintmaker.appstudio.redhat.com/"
+
+// Kind is the kind of credential being resolved
+type Kind string
+
+const (
+	KindSCM Kind = "scm"
+	KindRPM Kind = "rpm"
+)
+
+// Reason explains why a secret was chosen
+type Reason string
+
+const (
+	// ReasonExactMatch means the secret lists the component repository
+	ReasonExactMatch Reason = "exact-match"
+	// ReasonWildcardMatch means a repository pattern of the secret matched best
+	ReasonWildcardMatch Reason = "wildcard-match"
+	// ReasonHostOnly means the secret is not scoped to repositories, only to the host
+	ReasonHostOnly Reason = "host-only"
+	// ReasonDefault means no labelled secret matched and the default secret was used
+	ReasonDefault Reason = "default"
+	// ReasonGitHubApp means the GitHub App installation token is used
+	ReasonGitHubApp Reason = "github-app"
+	// ReasonNoMatch means no secret applies to the component
+	ReasonNoMatch Reason = "no-match"
+)
+
+// Decision is the outcome of a credential resolution together with the
+// ordered steps that led to it.
+type Decision struct {
+	Kind   Kind   `json:"kind"`
+	Secret string `json:"secret,omitempty"`
+	Reason Reason `json:"reason"`
+	// Ambiguous is set when several secrets applied equally well and the
+	// secret was picked by name
+	Ambiguous bool     `json:"ambiguous,omitempty"`
+	Path      []string `json:"path"`
+}
+
+// Stepf appends a step to the decision path
+func (d *Decision) Stepf(format string, args ...any) {
+	d.Path = append(d.Path, fmt.Sprintf(format, args...))
+}
+
+// Choose records the chosen secret and the reason it was chosen
+func (d *Decision) Choose(secret string, reason Reason) {
+	d.Secret = secret
+	d.Reason = reason
+}
+
+// String returns a human readable summary, e.g. "scm: secret my-token (exact-match)"
+func (d Decision) String() string {
+	if d.Secret == "" {
+		return fmt.Sprintf("%s: no secret (%s)", d.Kind, d.Reason)
+	}
+	if d.Ambiguous {
+		return fmt.Sprintf("%s: secret %s (%s, ambiguous)", d.Kind, d.Secret, d.Reason)
+	}
+	return fmt.Sprintf("%s: secret %s (%s)", d.Kind, d.Secret, d.Reason)
+}
+
+// Annotations returns the PipelineRun annotations recording the chosen
+// secret and reason of each decision.
+func Annotations(decisions []Decision) map[string]string {
+	annotations := make(map[string]string, 2*len(decisions))
+	for _, d := range decisions {
+		annotations[annotationPrefix+string(d.Kind)+"-secret"] = d.Secret
+		annotations[annotationPrefix+string(d.Kind)+"-secret-reason"] = string(d.Reason)
+	}
+	return annotations
+}
@@ -0,0 +1,25 @@
+package credentials
+
+import "testing"
+
+func TestAnnotations(t *testing.T) {
+	annotations := Annotations([]Decision{
+		{Kind: KindSCM, Secret: "scm-token", Reason: ReasonExactMatch},
+		{Kind: KindRPM, Reason: ReasonNoMatch},
+	})
+
+	expected := map[string]string{
+		"mintmaker.appstudio.redhat.com/scm-secret":        "scm-token",
+		"mintmaker.appstudio.redhat.com/scm-secret-reason": "exact-match",
+		"mintmaker.appstudio.redhat.com/rpm-secret":        "",
+		"mintmaker.appstudio.redhat.com/rpm-secret-reason": "no-match",
+	}
+	if len(annotations) != len(expected) {
+		t.Fatalf("got %d annotations, want %d: %v", len(annotations), len(expected), annotations)
+	}
+	for key, value := range expected {
+		if annotations[key] != value {
+			t.Errorf("annotation %s: got %q, want %q", key, annotations[key], value)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// CredentialsLabel selects secrets of a given Kind
+	CredentialsLabel = "appstudio.redhat.com/credentials"
+	// HostLabel selects secrets for a given git host
+	HostLabel = "appstudio.redhat.com/scm.host"
+	// RepositoryAnnotation lists the repositories a secret applies to, comma separated.
+	// Entries are repository paths or glob patterns, see Match.
+	RepositoryAnnotation = "appstudio.redhat.com/scm.repository"
+)
+
+// ErrNoMatchingSecret is returned when no secret applies to the repository
+var ErrNoMatchingSecret = errors.New("no matching secret")
+
+// Resolver finds the secret of one Kind that applies to a repository.
+type Resolver struct {
+	Client client.Client
+	// Kind is matched against the CredentialsLabel of the secrets
+	Kind Kind
+	// Accept reports whether a labelled secret is a candidate, e.g. OfType or IsSCMSecret
+	Accept func(*corev1.Secret) bool
+	// DefaultSecret optionally names a secret used when no labelled secret applies
+	DefaultSecret string
+}
+
+// Resolve lists the labelled secrets of the resolver Kind for host in
+// namespace and returns the one that applies to repository.
+//
+// Candidates are the secrets accepted by the resolver. If
+// none applies, the DefaultSecret is used when it is set and exists, otherwise
+// ErrNoMatchingSecret is returned. The returned Decision is always populated.
+func (r *Resolver) Resolve(ctx context.Context, namespace, host, repository string) (*corev1.Secret, Decision, error) {
+	decision := Decision{Kind: r.Kind}
+
+	secretList := &corev1.SecretList{}
+	labels := client.MatchingLabels{
+		CredentialsLabel: string(r.Kind),
+		HostLabel:        host,
+	}
+	if err := r.Client.List(ctx, secretList, client.InNamespace(namespace), labels); err != nil {
+		decision.Stepf("failed to list %s secrets: %v", r.Kind, err)
+		if secret := r.defaultSecret(ctx, namespace, &decision); secret != nil {
+			return secret, decision, nil
+		}
+		return nil, decision, fmt.Errorf("failed to list %s secrets in namespace %s: %w", r.Kind, namespace, err)
+	}
+
+	candidates := slices.DeleteFunc(secretList.Items, func(secret corev1.Secret) bool {
+		return !r.Accept(&secret)
+	})
+	decision.Stepf("found %d usable %s secret(s) for host %s", len(candidates), r.Kind, host)
+
+	if secret := Match(repository, candidates, &decision); secret != nil {
+		return secret, decision, nil
+	}
+	if secret := r.defaultSecret(ctx, namespace, &decision); secret != nil {
+		return secret, decision, nil
+	}
+	return nil, decision, fmt.Errorf("%w: no %s secret for %s/%s", ErrNoMatchingSecret, r.Kind, host, repository)
+}
+
+// OfType accepts non-empty secrets of the given type
+func OfType(secretType corev1.SecretType) func(*corev1.Secret) bool {
+	return func(secret *corev1.Secret) bool {
+		return secret.Type == secretType && len(secret.Data) > 0
+	}
+}
+
+func (r *Resolver) defaultSecret(ctx context.Context, namespace string, decision *Decision) *corev1.Secret {
+	if r.DefaultSecret == "" {
+		return nil
+	}
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: r.DefaultSecret}, secret); err != nil {
+		decision.Stepf("default secret %s is not available: %v", r.DefaultSecret, err)
+		return nil
+	}
+	decision.Stepf("falling back to default secret %s", secret.Name)
+	decision.Choose(secret.Name, ReasonDefault)
+	return secret
+}
+
+// Match picks the secret out of candidates that applies to repository.
+//
+// A secret listing the repository exactly wins. Otherwise the secret with
+// the most specific matching pattern is chosen, specificity being the number
+// of leading pattern segments without glob characters. Patterns use path.Match
+// syntax per path segment, "**" matches any number of segments and a trailing
+// "*" segment also matches nested groups, so "org/*" matches "org/sub/repo".
+// Without a pattern match, the first secret that is not scoped to any
+// repository is used. Ties are broken by secret name so the result never
+// depends on the order of candidates; such a decision is marked ambiguous.
+// Match returns nil when nothing applies.
+func Match(repository string, candidates []corev1.Secret, decision *Decision) *corev1.Secret {
+	repository = normalizeRepository(repository)
+
+	sorted := slices.Clone(candidates)
+	slices.SortFunc(sorted, func(a, b corev1.Secret) int { return strings.Compare(a.Name, b.Name) })
+
+	var exact, hostOnly, bestPatterns []*corev1.Secret
+	bestScore := -1
+	for i := range sorted {
+		secret := &sorted[i]
+		annotation := secret.Annotations[RepositoryAnnotation]
+		if strings.TrimSpace(annotation) == "" {
+			decision.Stepf("secret %s is not scoped to repositories", secret.Name)
+			hostOnly = append(hostOnly, secret)
+			continue
+		}
+
+		matched, score := false, -1
+		for _, pattern := range strings.Split(annotation, ",") {
+			pattern = normalizeRepository(pattern)
+			if pattern == "" {
+				continue
+			}
+			if pattern == repository {
+				decision.Stepf("secret %s lists repository %s", secret.Name, repository)
+				exact = append(exact, secret)
+				matched = true
+				break
+			}
+			if !isGlob(pattern) || !matchPattern(pattern, repository) {
+				continue
+			}
+			matched = true
+			patternScore := specificity(pattern)
+			decision.Stepf("secret %s pattern %s matches with specificity %d", secret.Name, pattern, patternScore)
+			score = max(score, patternScore)
+		}
+		switch {
+		case !matched:
+			decision.Stepf("secret %s repositories %s do not match", secret.Name, annotation)
+		case score > bestScore:
+			bestScore = score
+			bestPatterns = []*corev1.Secret{secret}
+		case score == bestScore && score >= 0:
+			bestPatterns = append(bestPatterns, secret)
+		}
+	}
+
+	switch {
+	case len(exact) > 0:
+		decision.Choose(exact[0].Name, ReasonExactMatch)
+		chooseFirst(exact, decision)
+		return exact[0]
+	case len(bestPatterns) > 0:
+		decision.Stepf("secret %s has the most specific pattern match", bestPatterns[0].Name)
+		decision.Choose(bestPatterns[0].Name, ReasonWildcardMatch)
+		chooseFirst(bestPatterns, decision)
+		return bestPatterns[0]
+	case len(hostOnly) > 0:
+		decision.Stepf("falling back to host-only secret %s", hostOnly[0].Name)
+		decision.Choose(hostOnly[0].Name, ReasonHostOnly)
+		chooseFirst(hostOnly, decision)
+		return hostOnly[0]
+	default:
+		decision.Stepf("no secret matches repository %s", repository)
+		decision.Choose("", ReasonNoMatch)
+		return nil
+	}
+}
+
+// chooseFirst marks the decision ambiguous when several secrets tie
+func chooseFirst(tied []*corev1.Secret, decision *Decision) {
+	if len(tied) < 2 {
+		return
+	}
+	names := make([]string, len(tied))
+	for i, secret := range tied {
+		names[i] = secret.Name
+	}
+	decision.Stepf("secrets %s tie, using %s by name", strings.Join(names, ", "), names[0])
+	decision.Ambiguous = true
+}
+
+// normalizeRepository trims whitespace and leading or trailing "/"
+func normalizeRepository(repository string) string {
+	return strings.Trim(strings.TrimSpace(repository), "/")
+}
+
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// specificity is the number of leading pattern segments without glob characters
+func specificity(pattern string) int {
+	score := 0
+	for _, segment := range strings.Split(pattern, "/") {
+		if isGlob(segment) {
+			break
+		}
+		score++
+	}
+	return score
+}
+
+// matchPattern reports whether the repository path matches the glob pattern
+func matchPattern(pattern, repository string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(repository, "/"))
+}
+
+func matchSegments(pattern, repository []string) bool {
+	if len(pattern) == 0 {
+		return len(repository) == 0
+	}
+	switch {
+	case pattern[0] == "**":
+		for i := 0; i <= len(repository); i++ {
+			if matchSegments(pattern[1:], repository[i:]) {
+				return true
+			}
+		}
+		return false
+	case len(pattern) == 1 && pattern[0] == "*":
+		// a trailing "*" covers the rest of the path, including nested groups
+		return len(repository) > 0
+	case len(repository) == 0:
+		return false
+	}
+	if ok, err := path.Match(pattern[0], repository[0]); err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], repository[1:])
+}
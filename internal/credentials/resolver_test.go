@@ -0,0 +1,321 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func newSecret(name, repositories string) corev1.Secret {
+	secret := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if repositories != "" {
+		secret.Annotations = map[string]string{RepositoryAnnotation: repositories}
+	}
+	return secret
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name           string
+		repository     string
+		secrets        []corev1.Secret
+		expectedSecret string
+		expectedReason Reason
+		ambiguous      bool
+	}{
+		{
+			name:           "exact match ignores surrounding slashes and spaces",
+			repository:     "/org/repo/",
+			secrets:        []corev1.Secret{newSecret("exact", " org/other , /org/repo/")},
+			expectedSecret: "exact",
+			expectedReason: ReasonExactMatch,
+		},
+		{
+			name:           "exact match wins over a more specific pattern",
+			repository:     "org/team/repo",
+			secrets:        []corev1.Secret{newSecret("a-pattern", "org/team/*"), newSecret("z-exact", "org/team/repo")},
+			expectedSecret: "z-exact",
+			expectedReason: ReasonExactMatch,
+		},
+		{
+			name:           "repository listed by several secrets is broken by secret name",
+			repository:     "org/repo",
+			secrets:        []corev1.Secret{newSecret("b-exact", "org/repo"), newSecret("a-exact", "org/*,org/repo")},
+			expectedSecret: "a-exact",
+			expectedReason: ReasonExactMatch,
+			ambiguous:      true,
+		},
+		{
+			name:           "less specific pattern does not make the match ambiguous",
+			repository:     "org/team/repo",
+			secrets:        []corev1.Secret{newSecret("org", "org/*"), newSecret("team", "org/team/*"), newSecret("other", "org/**")},
+			expectedSecret: "team",
+			expectedReason: ReasonWildcardMatch,
+		},
+		{
+			name:           "trailing wildcard covers nested groups",
+			repository:     "org/team/repo",
+			secrets:        []corev1.Secret{newSecret("org", "org/*")},
+			expectedSecret: "org",
+			expectedReason: ReasonWildcardMatch,
+		},
+		{
+			name:           "most specific pattern wins",
+			repository:     "org/team/repo",
+			secrets:        []corev1.Secret{newSecret("org", "org/*"), newSecret("team", "org/team/*")},
+			expectedSecret: "team",
+			expectedReason: ReasonWildcardMatch,
+		},
+		{
+			name:           "pattern sharing only a prefix does not match",
+			repository:     "org/repo",
+			secrets:        []corev1.Secret{newSecret("team", "org/team/*"), newSecret("host", "")},
+			expectedSecret: "host",
+			expectedReason: ReasonHostOnly,
+		},
+		{
+			name:           "glob within a segment",
+			repository:     "org/service-api",
+			secrets:        []corev1.Secret{newSecret("services", "org/service-*")},
+			expectedSecret: "services",
+			expectedReason: ReasonWildcardMatch,
+		},
+		{
+			name:           "glob within a segment does not cross slashes",
+			repository:     "org/service/api",
+			secrets:        []corev1.Secret{newSecret("services", "org/service-*/api")},
+			expectedReason: ReasonNoMatch,
+		},
+		{
+			name:           "question mark and character class",
+			repository:     "org/repo1",
+			secrets:        []corev1.Secret{newSecret("numbered", "org/rep?[0-9]")},
+			expectedSecret: "numbered",
+			expectedReason: ReasonWildcardMatch,
+		},
+		{
+			name:           "double star matches any depth in the middle",
+			repository:     "org/a/b/c/repo",
+			secrets:        []corev1.Secret{newSecret("deep", "org/**/repo")},
+			expectedSecret: "deep",
+			expectedReason: ReasonWildcardMatch,
+		},
+		{
+			name:           "double star matches zero segments",
+			repository:     "org/repo",
+			secrets:        []corev1.Secret{newSecret("deep", "org/**/repo")},
+			expectedSecret: "deep",
+			expectedReason: ReasonWildcardMatch,
+		},
+		{
+			name:           "equally specific patterns are broken by secret name",
+			repository:     "org/repo",
+			secrets:        []corev1.Secret{newSecret("b-secret", "org/*"), newSecret("a-secret", "org/r*")},
+			expectedSecret: "a-secret",
+			expectedReason: ReasonWildcardMatch,
+			ambiguous:      true,
+		},
+		{
+			name:           "host-only secrets are broken by secret name",
+			repository:     "org/repo",
+			secrets:        []corev1.Secret{newSecret("b-host", ""), newSecret("a-host", "")},
+			expectedSecret: "a-host",
+			expectedReason: ReasonHostOnly,
+			ambiguous:      true,
+		},
+		{
+			name:           "malformed pattern never matches",
+			repository:     "org/repo",
+			secrets:        []corev1.Secret{newSecret("broken", "org/[")},
+			expectedReason: ReasonNoMatch,
+		},
+		{
+			name:           "repository pattern wins over host-only",
+			repository:     "org/repo",
+			secrets:        []corev1.Secret{newSecret("host", ""), newSecret("wildcard", "org/*")},
+			expectedSecret: "wildcard",
+			expectedReason: ReasonWildcardMatch,
+		},
+		{
+			name:           "secret scoped to other repositories does not match",
+			repository:     "org/repo",
+			secrets:        []corev1.Secret{newSecret("other", "another/repo")},
+			expectedReason: ReasonNoMatch,
+		},
+		{
+			name:           "no candidates",
+			repository:     "org/repo",
+			expectedReason: ReasonNoMatch,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// The result must not depend on the order of candidates
+			for _, candidates := range [][]corev1.Secret{tc.secrets, reversed(tc.secrets)} {
+				decision := Decision{Kind: KindSCM}
+				secret := Match(tc.repository, candidates, &decision)
+
+				name := ""
+				if secret != nil {
+					name = secret.Name
+				}
+				if name != tc.expectedSecret {
+					t.Fatalf("expected secret %q, got %q (path %v)", tc.expectedSecret, name, decision.Path)
+				}
+				if decision.Secret != tc.expectedSecret || decision.Reason != tc.expectedReason {
+					t.Errorf("decision: got %s, want secret %q (%s)", decision, tc.expectedSecret, tc.expectedReason)
+				}
+				if decision.Ambiguous != tc.ambiguous {
+					t.Errorf("expected ambiguous %t, got %t (path %v)", tc.ambiguous, decision.Ambiguous, decision.Path)
+				}
+			}
+		})
+	}
+}
+
+func reversed(secrets []corev1.Secret) []corev1.Secret {
+	r := slices.Clone(secrets)
+	slices.Reverse(r)
+	return r
+}
+
+func labelledSecret(name string, kind Kind, secretType corev1.SecretType, repositories string, data map[string][]byte) *corev1.Secret {
+	secret := newSecret(name, repositories)
+	secret.Namespace = "tenant"
+	secret.Labels = map[string]string{CredentialsLabel: string(kind), HostLabel: "gitlab.com"}
+	secret.Type = secretType
+	secret.Data = data
+	return &secret
+}
+
+func TestResolve(t *testing.T) {
+	data := map[string][]byte{"password": []byte("token")}
+	defaultSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "activation-key", Namespace: "tenant"},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       data,
+	}
+
+	tests := []struct {
+		name           string
+		resolver       Resolver
+		objs           []client.Object
+		listErr        error
+		expectedSecret string
+		expectedReason Reason
+		expectNoMatch  bool
+		expectError    bool
+	}{
+		{
+			name:     "matches only secrets of the resolver type",
+			resolver: Resolver{Kind: KindSCM, Accept: OfType(corev1.SecretTypeBasicAuth)},
+			objs: []client.Object{
+				labelledSecret("opaque", KindSCM, corev1.SecretTypeOpaque, "org/repo", data),
+				labelledSecret("basic", KindSCM, corev1.SecretTypeBasicAuth, "org/*", data),
+			},
+			expectedSecret: "basic",
+			expectedReason: ReasonWildcardMatch,
+		},
+		{
+			name:     "ignores secrets without data",
+			resolver: Resolver{Kind: KindSCM, Accept: OfType(corev1.SecretTypeBasicAuth)},
+			objs: []client.Object{
+				labelledSecret("empty", KindSCM, corev1.SecretTypeBasicAuth, "org/repo", nil),
+			},
+			expectNoMatch: true,
+		},
+		{
+			name:     "ignores secrets of another kind",
+			resolver: Resolver{Kind: KindRPM, Accept: OfType(corev1.SecretTypeOpaque)},
+			objs: []client.Object{
+				labelledSecret("scm", KindSCM, corev1.SecretTypeOpaque, "org/repo", data),
+			},
+			expectNoMatch: true,
+		},
+		{
+			name:     "falls back to the default secret",
+			resolver: Resolver{Kind: KindRPM, Accept: OfType(corev1.SecretTypeOpaque), DefaultSecret: "activation-key"},
+			objs: []client.Object{
+				labelledSecret("other", KindRPM, corev1.SecretTypeOpaque, "other/repo", data),
+				defaultSecret,
+			},
+			expectedSecret: "activation-key",
+			expectedReason: ReasonDefault,
+		},
+		{
+			name:           "missing default secret is no match",
+			resolver:       Resolver{Kind: KindRPM, Accept: OfType(corev1.SecretTypeOpaque), DefaultSecret: "activation-key"},
+			expectNoMatch:  true,
+			expectedReason: ReasonNoMatch,
+		},
+		{
+			name:           "list error falls back to the default secret",
+			resolver:       Resolver{Kind: KindRPM, Accept: OfType(corev1.SecretTypeOpaque), DefaultSecret: "activation-key"},
+			objs:           []client.Object{defaultSecret},
+			listErr:        errors.New("forbidden"),
+			expectedSecret: "activation-key",
+			expectedReason: ReasonDefault,
+		},
+		{
+			name:        "list error without default secret",
+			resolver:    Resolver{Kind: KindSCM, Accept: OfType(corev1.SecretTypeBasicAuth)},
+			listErr:     errors.New("forbidden"),
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := clientgoscheme.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objs...)
+			if tc.listErr != nil {
+				builder = builder.WithInterceptorFuncs(interceptor.Funcs{
+					List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+						return tc.listErr
+					},
+				})
+			}
+			tc.resolver.Client = builder.Build()
+
+			secret, decision, err := tc.resolver.Resolve(context.Background(), "tenant", "gitlab.com", "org/repo")
+
+			switch {
+			case tc.expectError:
+				if err == nil || errors.Is(err, ErrNoMatchingSecret) {
+					t.Fatalf("expected a list error, got %v", err)
+				}
+				return
+			case tc.expectNoMatch:
+				if !errors.Is(err, ErrNoMatchingSecret) {
+					t.Fatalf("expected ErrNoMatchingSecret, got %v", err)
+				}
+				if secret != nil {
+					t.Fatalf("expected no secret, got %s", secret.Name)
+				}
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case secret == nil || secret.Name != tc.expectedSecret:
+				t.Fatalf("expected secret %q, got %v", tc.expectedSecret, secret)
+			}
+			if tc.expectedReason != "" && decision.Reason != tc.expectedReason {
+				t.Errorf("Decision.Reason: got %q, want %q", decision.Reason, tc.expectedReason)
+			}
+			if decision.Kind != tc.resolver.Kind {
+				t.Errorf("Decision.Kind: got %q, want %q", decision.Kind, tc.resolver.Kind)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// CredentialTypeAnnotation marks Opaque SCM secrets holding an OAuth application
+	CredentialTypeAnnotation = "appstudio.redhat.com/scm.credential-type"
+	// TokenExpiresAtAnnotation records when the API token of an SCM secret expires,
+	// in RFC 3339 or YYYY-MM-DD format, e.g. for GitLab group and project access tokens
+	TokenExpiresAtAnnotation = "appstudio.redhat.com/scm.token-expires-at"
+
+	// Data keys of OAuth application secrets
+	OAuthClientIDKey     = "client-id"
+	OAuthClientSecretKey = "client-secret"
+	OAuthRefreshTokenKey = "refresh-token"
+	OAuthAccessTokenKey  = "access-token"
+	OAuthExpiresAtKey    = "expires-at"
+
+	// SSHTokenKey holds the API token stored next to an SSH deploy key
+	SSHTokenKey = "token"
+	// SSHKnownHostsKey optionally pins the host keys of the git host
+	SSHKnownHostsKey = "known_hosts"
+)
+
+// CredentialType is how an SCM secret authenticates
+type CredentialType string
+
+const (
+	// CredentialTypeAccessToken is a personal, group or project access token in a basic-auth secret
+	CredentialTypeAccessToken CredentialType = "access-token"
+	// CredentialTypeOAuth is an OAuth2 application with a refresh token in an annotated Opaque secret
+	CredentialTypeOAuth CredentialType = "oauth"
+	// CredentialTypeSSH is an SSH deploy key for cloning combined with an API token
+	CredentialTypeSSH CredentialType = "ssh"
+)
+
+// SCMCredential is the credential material of an SCM secret
+type SCMCredential struct {
+	Type       CredentialType
+	SecretName string
+	// Token authenticates API calls, it is the access token for OAuth applications
+	Token string
+	// ExpiresAt is when Token expires, zero when unknown
+	ExpiresAt time.Time
+
+	SSHPrivateKey []byte
+	SSHKnownHosts []byte
+
+	OAuthClientID     string
+	OAuthClientSecret string
+	OAuthRefreshToken string
+}
+
+// SCMCredentialType returns the credential type of an SCM secret, selected by
+// the secret type or, for Opaque secrets, by the CredentialTypeAnnotation.
+func SCMCredentialType(secret *corev1.Secret) (CredentialType, bool) {
+	switch secret.Type {
+	case corev1.SecretTypeBasicAuth:
+		return CredentialTypeAccessToken, true
+	case corev1.SecretTypeSSHAuth:
+		return CredentialTypeSSH, true
+	case corev1.SecretTypeOpaque:
+		if CredentialType(secret.Annotations[CredentialTypeAnnotation]) == CredentialTypeOAuth {
+			return CredentialTypeOAuth, true
+		}
+	}
+	return "", false
+}
+
+// IsSCMSecret reports whether the secret holds a supported SCM credential
+func IsSCMSecret(secret *corev1.Secret) bool {
+	_, ok := SCMCredentialType(secret)
+	return ok && len(secret.Data) > 0
+}
+
+// ParseSCMSecret reads the credential of an SCM secret
+func ParseSCMSecret(secret *corev1.Secret) (*SCMCredential, error) {
+	credentialType, ok := SCMCredentialType(secret)
+	if !ok {
+		return nil, fmt.Errorf("secret %s of type %s is not a supported SCM credential", secret.Name, secret.Type)
+	}
+	cred := &SCMCredential{Type: credentialType, SecretName: secret.Name}
+
+	switch credentialType {
+	case CredentialTypeAccessToken:
+		cred.Token = string(secret.Data[corev1.BasicAuthPasswordKey])
+	case CredentialTypeSSH:
+		cred.Token = string(secret.Data[SSHTokenKey])
+		cred.SSHPrivateKey = secret.Data[corev1.SSHAuthPrivateKey]
+		cred.SSHKnownHosts = secret.Data[SSHKnownHostsKey]
+		if len(cred.SSHPrivateKey) == 0 {
+			return nil, fmt.Errorf("secret %s doesn't contain %s", secret.Name, corev1.SSHAuthPrivateKey)
+		}
+	case CredentialTypeOAuth:
+		cred.Token = string(secret.Data[OAuthAccessTokenKey])
+		cred.OAuthClientID = string(secret.Data[OAuthClientIDKey])
+		cred.OAuthClientSecret = string(secret.Data[OAuthClientSecretKey])
+		cred.OAuthRefreshToken = string(secret.Data[OAuthRefreshTokenKey])
+		if cred.OAuthClientID == "" || cred.OAuthClientSecret == "" || cred.OAuthRefreshToken == "" {
+			return nil, fmt.Errorf("secret %s must contain %s, %s and %s",
+				secret.Name, OAuthClientIDKey, OAuthClientSecretKey, OAuthRefreshTokenKey)
+		}
+		if expiresAt, ok := secret.Data[OAuthExpiresAtKey]; ok && len(expiresAt) > 0 {
+			t, err := parseExpiry(string(expiresAt))
+			if err != nil {
+				return nil, fmt.Errorf("secret %s has an invalid %s: %w", secret.Name, OAuthExpiresAtKey, err)
+			}
+			cred.ExpiresAt = t
+		}
+		// The access token is refreshed on demand, so only a missing one is fine here
+		return cred, nil
+	}
+
+	if cred.Token == "" {
+		return nil, fmt.Errorf("secret %s doesn't contain an API token", secret.Name)
+	}
+	if expiresAt := secret.Annotations[TokenExpiresAtAnnotation]; expiresAt != "" {
+		t, err := parseExpiry(expiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("secret %s has an invalid %s annotation: %w", secret.Name, TokenExpiresAtAnnotation, err)
+		}
+		cred.ExpiresAt = t
+	}
+	return cred, nil
+}
+
+// parseExpiry accepts RFC 3339 timestamps and plain dates, as GitLab reports
+// access token expiry as a date
+func parseExpiry(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+// oauthRefreshLocks serializes refreshes of the same secret within the
+// manager, where the controller and the token broker may refresh concurrently
+var oauthRefreshLocks sync.Map
+
+// RefreshOAuth makes sure cred holds an access token valid for at least
+// minValidity, refreshing it at tokenURL otherwise. Providers rotate refresh
+// tokens, so the new tokens are written back to the secret. Refreshes are
+// serialized within the manager and checked against the resourceVersion of
+// the secret: when another replica stored newer tokens first, those are
+// reused instead.
+func RefreshOAuth(ctx context.Context, k8sClient client.Client, secret *corev1.Secret, cred *SCMCredential, tokenURL string, minValidity time.Duration) error {
+	if cred.Type != CredentialTypeOAuth {
+		return nil
+	}
+	if oauthTokenValid(cred, minValidity) {
+		return nil
+	}
+
+	key := client.ObjectKeyFromObject(secret)
+	lock, _ := oauthRefreshLocks.LoadOrStore(key, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	// The secret may have been refreshed while waiting for the lock
+	latest, latestCred, err := getOAuthSecret(ctx, k8sClient, key)
+	if err != nil {
+		return err
+	}
+	if oauthTokenValid(latestCred, minValidity) {
+		*cred = *latestCred
+		return nil
+	}
+	usedRefreshToken := latestCred.OAuthRefreshToken
+
+	oauthConfig := &oauth2.Config{
+		ClientID:     latestCred.OAuthClientID,
+		ClientSecret: latestCred.OAuthClientSecret,
+		Endpoint:     oauth2.Endpoint{TokenURL: tokenURL},
+	}
+	// An expired token forces the token source to use the refresh token
+	token, err := oauthConfig.TokenSource(ctx, &oauth2.Token{
+		RefreshToken: usedRefreshToken,
+		Expiry:       time.Now().Add(-time.Minute),
+	}).Token()
+	if err != nil {
+		// Another replica may have rotated the refresh token meanwhile
+		if reused, getErr := reuseStoredOAuth(ctx, k8sClient, key, cred, usedRefreshToken); getErr == nil && reused {
+			return nil
+		}
+		return fmt.Errorf("failed to refresh OAuth token of secret %s: %w", secret.Name, err)
+	}
+
+	*cred = *latestCred
+	cred.Token = token.AccessToken
+	cred.ExpiresAt = token.Expiry
+	if token.RefreshToken != "" {
+		cred.OAuthRefreshToken = token.RefreshToken
+	}
+
+	first := true
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if !first {
+			var err error
+			if latest, latestCred, err = getOAuthSecret(ctx, k8sClient, key); err != nil {
+				return err
+			}
+			// Another replica refreshed first, its tokens are the ones the provider keeps
+			if latestCred.OAuthRefreshToken != usedRefreshToken {
+				*cred = *latestCred
+				return nil
+			}
+		}
+		first = false
+		updated := latest.DeepCopy()
+		updated.Data[OAuthAccessTokenKey] = []byte(cred.Token)
+		updated.Data[OAuthRefreshTokenKey] = []byte(cred.OAuthRefreshToken)
+		if !cred.ExpiresAt.IsZero() {
+			updated.Data[OAuthExpiresAtKey] = []byte(cred.ExpiresAt.UTC().Format(time.RFC3339))
+		}
+		// Fails with a conflict when the secret changed since latest was read
+		return k8sClient.Update(ctx, updated)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store refreshed OAuth token in secret %s: %w", secret.Name, err)
+	}
+	return nil
+}
+
+// oauthTokenValid reports whether cred holds an access token valid for at least minValidity
+func oauthTokenValid(cred *SCMCredential, minValidity time.Duration) bool {
+	return cred.Token != "" && !cred.ExpiresAt.IsZero() && time.Until(cred.ExpiresAt) > minValidity
+}
+
+// getOAuthSecret reads the current version of an OAuth secret
+func getOAuthSecret(ctx context.Context, k8sClient client.Client, key client.ObjectKey) (*corev1.Secret, *SCMCredential, error) {
+	secret := &corev1.Secret{}
+	if err := k8sClient.Get(ctx, key, secret); err != nil {
+		return nil, nil, fmt.Errorf("failed to read OAuth secret %s: %w", key.Name, err)
+	}
+	cred, err := ParseSCMSecret(secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	return secret, cred, nil
+}
+
+// reuseStoredOAuth sets cred to the stored tokens when they were refreshed
+// with another refresh token than usedRefreshToken and are still valid
+func reuseStoredOAuth(ctx context.Context, k8sClient client.Client, key client.ObjectKey, cred *SCMCredential, usedRefreshToken string) (bool, error) {
+	_, stored, err := getOAuthSecret(ctx, k8sClient, key)
+	if err != nil {
+		return false, err
+	}
+	if stored.OAuthRefreshToken == usedRefreshToken || !oauthTokenValid(stored, 0) {
+		return false, nil
+	}
+	*cred = *stored
+	return true, nil
+}
@@ -0,0 +1,411 @@
+package credentials
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestParseSCMSecret(t *testing.T) {
+	expiry := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name              string
+		secret            corev1.Secret
+		expectedType      CredentialType
+		expectedToken     string
+		expectedExpiresAt time.Time
+		expectError       bool
+	}{
+		{
+			name: "basic-auth access token",
+			secret: corev1.Secret{
+				Type: corev1.SecretTypeBasicAuth,
+				Data: map[string][]byte{corev1.BasicAuthPasswordKey: []byte("glpat")},
+			},
+			expectedType:  CredentialTypeAccessToken,
+			expectedToken: "glpat",
+		},
+		{
+			name: "project access token with expiry date",
+			secret: corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{TokenExpiresAtAnnotation: "2026-12-31"}},
+				Type:       corev1.SecretTypeBasicAuth,
+				Data:       map[string][]byte{corev1.BasicAuthPasswordKey: []byte("glpat")},
+			},
+			expectedType:      CredentialTypeAccessToken,
+			expectedToken:     "glpat",
+			expectedExpiresAt: expiry,
+		},
+		{
+			name: "invalid expiry annotation",
+			secret: corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{TokenExpiresAtAnnotation: "next year"}},
+				Type:       corev1.SecretTypeBasicAuth,
+				Data:       map[string][]byte{corev1.BasicAuthPasswordKey: []byte("glpat")},
+			},
+			expectError: true,
+		},
+		{
+			name: "basic-auth without password",
+			secret: corev1.Secret{
+				Type: corev1.SecretTypeBasicAuth,
+				Data: map[string][]byte{corev1.BasicAuthUsernameKey: []byte("user")},
+			},
+			expectError: true,
+		},
+		{
+			name: "ssh deploy key with API token",
+			secret: corev1.Secret{
+				Type: corev1.SecretTypeSSHAuth,
+				Data: map[string][]byte{corev1.SSHAuthPrivateKey: []byte("key"), SSHTokenKey: []byte("api")},
+			},
+			expectedType:  CredentialTypeSSH,
+			expectedToken: "api",
+		},
+		{
+			name: "ssh deploy key without API token",
+			secret: corev1.Secret{
+				Type: corev1.SecretTypeSSHAuth,
+				Data: map[string][]byte{corev1.SSHAuthPrivateKey: []byte("key")},
+			},
+			expectError: true,
+		},
+		{
+			name: "oauth application",
+			secret: corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{CredentialTypeAnnotation: "oauth"}},
+				Type:       corev1.SecretTypeOpaque,
+				Data: map[string][]byte{
+					OAuthClientIDKey:     []byte("id"),
+					OAuthClientSecretKey: []byte("secret"),
+					OAuthRefreshTokenKey: []byte("refresh"),
+					OAuthAccessTokenKey:  []byte("access"),
+					OAuthExpiresAtKey:    []byte("2026-12-31T00:00:00Z"),
+				},
+			},
+			expectedType:      CredentialTypeOAuth,
+			expectedToken:     "access",
+			expectedExpiresAt: expiry,
+		},
+		{
+			name: "oauth application without refresh token",
+			secret: corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{CredentialTypeAnnotation: "oauth"}},
+				Type:       corev1.SecretTypeOpaque,
+				Data:       map[string][]byte{OAuthClientIDKey: []byte("id"), OAuthClientSecretKey: []byte("secret")},
+			},
+			expectError: true,
+		},
+		{
+			name: "unannotated opaque secret is not an SCM credential",
+			secret: corev1.Secret{
+				Type: corev1.SecretTypeOpaque,
+				Data: map[string][]byte{"token": []byte("x")},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cred, err := ParseSCMSecret(&tc.secret)
+			if tc.expectError {
+				if err == nil {
+					t.Fatal("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cred.Type != tc.expectedType {
+				t.Errorf("Type: got %q, want %q", cred.Type, tc.expectedType)
+			}
+			if cred.Token != tc.expectedToken {
+				t.Errorf("Token: got %q, want %q", cred.Token, tc.expectedToken)
+			}
+			if !cred.ExpiresAt.Equal(tc.expectedExpiresAt) {
+				t.Errorf("ExpiresAt: got %v, want %v", cred.ExpiresAt, tc.expectedExpiresAt)
+			}
+		})
+	}
+}
+
+func TestRefreshOAuth(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if err := r.ParseForm(); err != nil || r.Form.Get("refresh_token") != "old-refresh" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"new-access","refresh_token":"new-refresh","token_type":"bearer","expires_in":7200}`))
+	}))
+	defer server.Close()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "oauth",
+			Namespace:   "tenant",
+			Annotations: map[string]string{CredentialTypeAnnotation: "oauth"},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			OAuthClientIDKey:     []byte("id"),
+			OAuthClientSecretKey: []byte("secret"),
+			OAuthRefreshTokenKey: []byte("old-refresh"),
+		},
+	}
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+	ctx := context.Background()
+
+	stored := &corev1.Secret{}
+	if err := cl.Get(ctx, client.ObjectKeyFromObject(secret), stored); err != nil {
+		t.Fatal(err)
+	}
+	cred, err := ParseSCMSecret(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RefreshOAuth(ctx, cl, stored, cred, server.URL, time.Minute); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cred.Token != "new-access" || cred.OAuthRefreshToken != "new-refresh" {
+		t.Errorf("expected refreshed tokens, got %q and %q", cred.Token, cred.OAuthRefreshToken)
+	}
+	if time.Until(cred.ExpiresAt) < time.Hour {
+		t.Errorf("expected expiry about two hours ahead, got %v", cred.ExpiresAt)
+	}
+
+	updated := &corev1.Secret{}
+	if err := cl.Get(ctx, client.ObjectKeyFromObject(secret), updated); err != nil {
+		t.Fatal(err)
+	}
+	if string(updated.Data[OAuthRefreshTokenKey]) != "new-refresh" || string(updated.Data[OAuthAccessTokenKey]) != "new-access" {
+		t.Errorf("expected rotated tokens to be stored, got %v", updated.Data)
+	}
+
+	// A still valid access token is reused without contacting the provider
+	if err := RefreshOAuth(ctx, cl, updated, cred, server.URL, time.Minute); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected a single refresh request, got %d", requests)
+	}
+}
+
+func TestRefreshOAuthConflict(t *testing.T) {
+	otherExpiry := time.Now().Add(2 * time.Hour).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name string
+		// concurrent changes the stored secret before the first Update
+		concurrent      func(secret *corev1.Secret)
+		rejectRefresh   bool
+		expectedToken   string
+		expectedRefresh string
+		expectedLabel   string
+	}{
+		{
+			name: "another replica refreshed first",
+			concurrent: func(secret *corev1.Secret) {
+				secret.Data[OAuthAccessTokenKey] = []byte("other-access")
+				secret.Data[OAuthRefreshTokenKey] = []byte("other-refresh")
+				secret.Data[OAuthExpiresAtKey] = []byte(otherExpiry)
+			},
+			expectedToken:   "other-access",
+			expectedRefresh: "other-refresh",
+		},
+		{
+			name: "unrelated change",
+			concurrent: func(secret *corev1.Secret) {
+				secret.Labels = map[string]string{"team": "a"}
+			},
+			expectedToken:   "new-access",
+			expectedRefresh: "new-refresh",
+			expectedLabel:   "a",
+		},
+		{
+			name: "refresh token rotated by another replica",
+			concurrent: func(secret *corev1.Secret) {
+				secret.Data[OAuthAccessTokenKey] = []byte("other-access")
+				secret.Data[OAuthRefreshTokenKey] = []byte("other-refresh")
+				secret.Data[OAuthExpiresAtKey] = []byte(otherExpiry)
+			},
+			rejectRefresh:   true,
+			expectedToken:   "other-access",
+			expectedRefresh: "other-refresh",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "oauth",
+					Namespace:   "tenant",
+					Annotations: map[string]string{CredentialTypeAnnotation: "oauth"},
+				},
+				Type: corev1.SecretTypeOpaque,
+				Data: map[string][]byte{
+					OAuthClientIDKey:     []byte("id"),
+					OAuthClientSecretKey: []byte("secret"),
+					OAuthRefreshTokenKey: []byte("old-refresh"),
+				},
+			}
+			scheme := runtime.NewScheme()
+			if err := clientgoscheme.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+
+			var cl client.Client
+			concurrent := func(ctx context.Context, c client.Client) {
+				stored := &corev1.Secret{}
+				if err := c.Get(ctx, client.ObjectKeyFromObject(secret), stored); err != nil {
+					t.Fatal(err)
+				}
+				tc.concurrent(stored)
+				if err := c.Update(ctx, stored); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.rejectRefresh {
+					// The other replica rotates the refresh token before ours reaches the provider
+					concurrent(r.Context(), cl)
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusBadRequest)
+					_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"access_token":"new-access","refresh_token":"new-refresh","token_type":"bearer","expires_in":7200}`))
+			}))
+			defer server.Close()
+
+			updates := 0
+			cl = fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+						updates++
+						if updates == 1 && !tc.rejectRefresh {
+							// Bumps the resourceVersion, so this Update conflicts
+							concurrent(ctx, c)
+						}
+						return c.Update(ctx, obj, opts...)
+					},
+				}).Build()
+			ctx := context.Background()
+
+			stored := &corev1.Secret{}
+			if err := cl.Get(ctx, client.ObjectKeyFromObject(secret), stored); err != nil {
+				t.Fatal(err)
+			}
+			cred, err := ParseSCMSecret(stored)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := RefreshOAuth(ctx, cl, stored, cred, server.URL, time.Minute); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cred.Token != tc.expectedToken || cred.OAuthRefreshToken != tc.expectedRefresh {
+				t.Errorf("expected tokens %q and %q, got %q and %q", tc.expectedToken, tc.expectedRefresh, cred.Token, cred.OAuthRefreshToken)
+			}
+
+			updated := &corev1.Secret{}
+			if err := cl.Get(ctx, client.ObjectKeyFromObject(secret), updated); err != nil {
+				t.Fatal(err)
+			}
+			if string(updated.Data[OAuthAccessTokenKey]) != tc.expectedToken || string(updated.Data[OAuthRefreshTokenKey]) != tc.expectedRefresh {
+				t.Errorf("expected stored tokens %q and %q, got %v", tc.expectedToken, tc.expectedRefresh, updated.Data)
+			}
+			if updated.Labels["team"] != tc.expectedLabel {
+				t.Errorf("expected the concurrent change to be kept, got labels %v", updated.Labels)
+			}
+		})
+	}
+}
+
+func TestRefreshOAuthSerialized(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"new-access","refresh_token":"new-refresh","token_type":"bearer","expires_in":7200}`))
+	}))
+	defer server.Close()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "oauth",
+			Namespace:   "tenant",
+			Annotations: map[string]string{CredentialTypeAnnotation: "oauth"},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			OAuthClientIDKey:     []byte("id"),
+			OAuthClientSecretKey: []byte("secret"),
+			OAuthRefreshTokenKey: []byte("old-refresh"),
+		},
+	}
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+	ctx := context.Background()
+
+	// The controller and the token broker refresh the same secret at once
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	creds := make([]*SCMCredential, 2)
+	for i := range creds {
+		stored := &corev1.Secret{}
+		if err := cl.Get(ctx, client.ObjectKeyFromObject(secret), stored); err != nil {
+			t.Fatal(err)
+		}
+		cred, err := ParseSCMSecret(stored)
+		if err != nil {
+			t.Fatal(err)
+		}
+		creds[i] = cred
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = RefreshOAuth(ctx, cl, stored, cred, server.URL, time.Minute)
+		}()
+	}
+	wg.Wait()
+
+	for i, cred := range creds {
+		if errs[i] != nil {
+			t.Fatalf("unexpected error: %v", errs[i])
+		}
+		if cred.Token != "new-access" {
+			t.Errorf("expected the refreshed token, got %q", cred.Token)
+		}
+	}
+	if requests.Load() != 1 {
+		t.Errorf("expected a single refresh request, got %d", requests.Load())
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ValidationReason is why a credential can't be used for a Renovate run
+type ValidationReason string
+
+const (
+	// ValidationReasonNotFound means no credential applies to the component
+	ValidationReasonNotFound ValidationReason = "CredentialsNotFound"
+	// ValidationReasonInvalid means the git host rejected the credential
+	ValidationReasonInvalid ValidationReason = "CredentialsInvalid"
+	// ValidationReasonExpired means the credential expired
+	ValidationReasonExpired ValidationReason = "CredentialsExpired"
+	// ValidationReasonMissingScopes means the token lacks scopes Renovate needs
+	ValidationReasonMissingScopes ValidationReason = "CredentialsMissingScopes"
+	// ValidationReasonRepositoryAccess means the credential can't push to the repository
+	ValidationReasonRepositoryAccess ValidationReason = "RepositoryAccessDenied"
+)
+
+// ValidationError is returned when a credential is known to be unusable, as
+// opposed to errors reaching the git host
+type ValidationError struct {
+	Reason  ValidationReason
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Reason, e.Message)
+}
+
+// NewValidationError returns a ValidationError with a formatted message
+func NewValidationError(reason ValidationReason, format string, args ...any) *ValidationError {
+	return &ValidationError{Reason: reason, Message: fmt.Sprintf(format, args...)}
+}
+
+// AsValidationError returns the ValidationError wrapped in err, if any
+func AsValidationError(err error) (*ValidationError, bool) {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr, true
+	}
+	return nil, false
+}
+
+// Validation is the outcome of a successful credential validation
+type Validation struct {
+	// ExpiresAt is when the credential expires, zero when it doesn't or is unknown
+	ExpiresAt time.Time
+	// Scopes are the scopes granted to the token, when the git host reports them
+	Scopes []string
+}
+
+// CheckExpiry fails when expiresAt is set and in the past
+func CheckExpiry(expiresAt time.Time, now time.Time) error {
+	if !expiresAt.IsZero() && !now.Before(expiresAt) {
+		return NewValidationError(ValidationReasonExpired, "credential expired at %s", expiresAt.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// CheckScopes fails unless the granted scopes include one of the accepted
+// sets, each set listing scopes that are required together
+func CheckScopes(granted []string, accepted ...[]string) error {
+	for _, set := range accepted {
+		if !slices.ContainsFunc(set, func(scope string) bool { return !slices.Contains(granted, scope) }) {
+			return nil
+		}
+	}
+	alternatives := make([]string, 0, len(accepted))
+	for _, set := range accepted {
+		alternatives = append(alternatives, strings.Join(set, "+"))
+	}
+	return NewValidationError(ValidationReasonMissingScopes, "token has scopes [%s], requires one of [%s]",
+		strings.Join(granted, ", "), strings.Join(alternatives, ", "))
+}
@@ -0,0 +1,104 @@
+package credentials
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCheckExpiry(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		expiresAt   time.Time
+		expectError bool
+	}{
+		{name: "no expiry", expiresAt: time.Time{}},
+		{name: "expires in the future", expiresAt: now.Add(time.Hour)},
+		{name: "expired", expiresAt: now.Add(-time.Hour), expectError: true},
+		{name: "expires right now", expiresAt: now, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckExpiry(tt.expiresAt, now)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("expected error %v, got %v", tt.expectError, err)
+			}
+			if err != nil {
+				validationErr, ok := AsValidationError(err)
+				if !ok || validationErr.Reason != ValidationReasonExpired {
+					t.Errorf("expected %s validation error, got %v", ValidationReasonExpired, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckScopes(t *testing.T) {
+	tests := []struct {
+		name        string
+		granted     []string
+		accepted    [][]string
+		expectError bool
+	}{
+		{
+			name:     "single required scope granted",
+			granted:  []string{"read_user", "api"},
+			accepted: [][]string{{"api"}},
+		},
+		{
+			name:        "single required scope missing",
+			granted:     []string{"read_api"},
+			accepted:    [][]string{{"api"}},
+			expectError: true,
+		},
+		{
+			name:     "second alternative fully granted",
+			granted:  []string{"read_api", "write_repository"},
+			accepted: [][]string{{"api"}, {"read_api", "write_repository"}},
+		},
+		{
+			name:        "alternative only partially granted",
+			granted:     []string{"read_api"},
+			accepted:    [][]string{{"api"}, {"read_api", "write_repository"}},
+			expectError: true,
+		},
+		{
+			name:        "no scopes granted",
+			accepted:    [][]string{{"api"}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckScopes(tt.granted, tt.accepted...)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("expected error %v, got %v", tt.expectError, err)
+			}
+			if err != nil {
+				validationErr, ok := AsValidationError(err)
+				if !ok || validationErr.Reason != ValidationReasonMissingScopes {
+					t.Errorf("expected %s validation error, got %v", ValidationReasonMissingScopes, err)
+				}
+			}
+		})
+	}
+}
+
+func TestAsValidationError(t *testing.T) {
+	wrapped := fmt.Errorf("component check failed: %w", NewValidationError(ValidationReasonInvalid, "token %s rejected", "glpat"))
+	validationErr, ok := AsValidationError(wrapped)
+	if !ok {
+		t.Fatalf("expected a wrapped validation error in %v", wrapped)
+	}
+	if validationErr.Reason != ValidationReasonInvalid || validationErr.Message != "token glpat rejected" {
+		t.Errorf("unexpected validation error %+v", validationErr)
+	}
+
+	if _, ok := AsValidationError(fmt.Errorf("connection refused")); ok {
+		t.Error("expected plain errors not to be validation errors")
+	}
+}
@@ -28,6 +28,67 @@ var (
 		},
 		[]string{"namespace", "name"},
 	)
+	scmCredentialExpiration = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: "mintmaker",
+			Name:      "scm_credential_expiration_timestamp_seconds",
+			Help:      "Unix timestamp when the SCM credential used for a component expires",
+		},
+		[]string{"namespace", "host", "secret", "type"},
+	)
+	scmCredentialExpiryHorizon = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: "mintmaker",
+			Name:      "scm_credential_expiry_horizon_seconds",
+			Help:      "Seconds until the earliest expiring SCM credential validated for a host expires",
+		},
+		[]string{"namespace", "host"},
+	)
+	renovateBaseConfigInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: "mintmaker",
+			Name:      "renovate_base_config_info",
+			Help:      "Hash of the active Renovate base config, the value is always 1",
+		},
+		[]string{"hash"},
+	)
+	pipelineRunRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: "mintmaker",
+			Name:      "pipelinerun_retries_total",
+			Help:      "Number of failed MintMaker PipelineRuns created again, by failure reason",
+		},
+		[]string{"reason"},
+	)
+	renovatePullRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: "mintmaker",
+			Name:      "renovate_pull_requests_total",
+			Help:      "Number of pull requests created or updated by Renovate, by action",
+		},
+		[]string{"action"}, // "created" or "updated"
+	)
+	renovateUpdatedDependencies = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Subsystem: "mintmaker",
+			Name:      "renovate_updated_dependencies_total",
+			Help:      "Number of dependencies with an update branch in finished PipelineRuns",
+		},
+	)
+	renovateErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Subsystem: "mintmaker",
+			Name:      "renovate_errors_total",
+			Help:      "Number of error and fatal entries in the Renovate logs of finished PipelineRuns",
+		},
+	)
+	renovateBaseConfigRejections = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Subsystem: "mintmaker",
+			Name:      "renovate_base_config_rejections_total",
+			Help:      "Number of Renovate base config updates rejected as invalid",
+		},
+	)
 )
 
 func RegisterCommonMetrics(ctx context.Context, registerer prometheus.Registerer) error {
@@ -38,6 +99,30 @@ func RegisterCommonMetrics(ctx context.Context, registerer prometheus.Registerer
 	if err := registerer.Register(dependencyUpdateCheckCreationTime); err != nil {
 		return fmt.Errorf("failed to register metrics: %w", err)
 	}
+	if err := registerer.Register(scmCredentialExpiration); err != nil {
+		return fmt.Errorf("failed to register metrics: %w", err)
+	}
+	if err := registerer.Register(scmCredentialExpiryHorizon); err != nil {
+		return fmt.Errorf("failed to register metrics: %w", err)
+	}
+	if err := registerer.Register(renovateBaseConfigInfo); err != nil {
+		return fmt.Errorf("failed to register metrics: %w", err)
+	}
+	if err := registerer.Register(renovateBaseConfigRejections); err != nil {
+		return fmt.Errorf("failed to register metrics: %w", err)
+	}
+	if err := registerer.Register(pipelineRunRetries); err != nil {
+		return fmt.Errorf("failed to register metrics: %w", err)
+	}
+	if err := registerer.Register(renovatePullRequests); err != nil {
+		return fmt.Errorf("failed to register metrics: %w", err)
+	}
+	if err := registerer.Register(renovateUpdatedDependencies); err != nil {
+		return fmt.Errorf("failed to register metrics: %w", err)
+	}
+	if err := registerer.Register(renovateErrors); err != nil {
+		return fmt.Errorf("failed to register metrics: %w", err)
+	}
 
 	ticker := time.NewTicker(10 * time.Minute)
 	log.Info("Starting metrics")
@@ -97,3 +182,38 @@ type AvailabilityProbe interface {
 	CheckEvents(ctx context.Context) float64
 	AddEvent()
 }
+
+// RecordSCMCredentialExpiration records when the SCM credential stored in a secret expires
+func RecordSCMCredentialExpiration(namespace, host, secret, credentialType string, expiresAt time.Time) {
+	scmCredentialExpiration.WithLabelValues(namespace, host, secret, credentialType).Set(float64(expiresAt.Unix()))
+}
+
+// RecordSCMCredentialExpiryHorizon records how long the SCM credential validated
+// for a host in a namespace stays valid
+func RecordSCMCredentialExpiryHorizon(namespace, host string, horizon time.Duration) {
+	scmCredentialExpiryHorizon.WithLabelValues(namespace, host).Set(horizon.Seconds())
+}
+
+// RecordRenovateBaseConfig records the hash of the Renovate base config that became active
+func RecordRenovateBaseConfig(hash string) {
+	renovateBaseConfigInfo.Reset()
+	renovateBaseConfigInfo.WithLabelValues(hash).Set(1)
+}
+
+// CountRenovateBaseConfigRejection counts a Renovate base config update rejected as invalid
+func CountRenovateBaseConfigRejection() {
+	renovateBaseConfigRejections.Inc()
+}
+
+// CountPipelineRunRetry counts a failed PipelineRun created again
+func CountPipelineRunRetry(reason string) {
+	pipelineRunRetries.WithLabelValues(reason).Inc()
+}
+
+// CountRenovateRunResults counts the results reported by a finished PipelineRun
+func CountRenovateRunResults(pullRequestsCreated, pullRequestsUpdated, updatedDependencies, errors int) {
+	renovatePullRequests.WithLabelValues("created").Add(float64(pullRequestsCreated))
+	renovatePullRequests.WithLabelValues("updated").Add(float64(pullRequestsUpdated))
+	renovateUpdatedDependencies.Add(float64(updatedDependencies))
+	renovateErrors.Add(float64(errors))
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package presetserver serves the MintMaker preset library to Renovate pods.
+//
+// Presets are read from ConfigMaps labelled as presets in the MintMaker
+// namespace and served as JSON, so Renovate can extend them by URL. The
+// ConfigMaps are read from an informer cache, not from the API server on
+// every request.
+package presetserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/konflux-ci/mintmaker/internal/renovateconfig"
+)
+
+// Server is an http.Handler and a manager.Runnable serving presets.
+type Server struct {
+	// Client reads the preset ConfigMaps, usually the cache of NewCluster
+	Client client.Reader
+	// Namespace holds the preset ConfigMaps
+	Namespace string
+	// BindAddress is the address the server listens on
+	BindAddress string
+	// CertFile and KeyFile enable HTTPS when both are set
+	CertFile string
+	KeyFile  string
+}
+
+// NewCluster returns a cluster whose cache only holds the preset ConfigMaps
+// of namespace. Added to the manager, the cache is synced before the server
+// starts.
+func NewCluster(config *rest.Config, scheme *runtime.Scheme, namespace string) (cluster.Cluster, error) {
+	presetCluster, err := cluster.New(config, func(o *cluster.Options) {
+		o.Scheme = scheme
+		o.Cache = cache.Options{
+			DefaultNamespaces: map[string]cache.Config{namespace: {}},
+			ByObject: map[client.Object]cache.ByObject{
+				&corev1.ConfigMap{}: {Label: labels.SelectorFromSet(labels.Set{renovateconfig.PresetLabel: "true"})},
+			},
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Register the informer up front, so it is started and synced with the cache
+	if _, err := presetCluster.GetCache().GetInformer(context.Background(), &corev1.ConfigMap{}); err != nil {
+		return nil, err
+	}
+	return presetCluster, nil
+}
+
+// Start serves presets until the context is cancelled.
+func (s *Server) Start(ctx context.Context) error {
+	log := ctrllog.FromContext(ctx).WithName("PresetServer")
+
+	mux := http.NewServeMux()
+	mux.Handle(renovateconfig.PresetPath, s)
+	server := &http.Server{
+		Addr:              s.BindAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(_ net.Listener) context.Context { return ctx },
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Info("starting preset server", "address", s.BindAddress, "tls", s.CertFile != "")
+		var err error
+		if s.CertFile != "" && s.KeyFile != "" {
+			err = server.ListenAndServeTLS(s.CertFile, s.KeyFile)
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+
+	select {
+	case <-ctx.Done():
+		log.Info("shutting down preset server")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdownCtx) //nolint:contextcheck // parent context is already cancelled
+	case err := <-errCh:
+		return err
+	}
+}
+
+// NeedLeaderElection returns false, every replica serves presets.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=list;watch
+
+// ServeHTTP responds with the preset named by the request path.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := ctrllog.FromContext(ctx).WithName("PresetServer")
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name, found := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, renovateconfig.PresetPath), ".json")
+	if !found || name == "" || strings.Contains(name, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	presets, err := renovateconfig.LoadPresets(ctx, s.Client, s.Namespace)
+	if err != nil {
+		log.Error(err, "failed to load presets")
+		http.Error(w, "failed to load presets", http.StatusInternalServerError)
+		return
+	}
+	preset, ok := presets[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(preset); err != nil {
+		log.Error(err, "failed to write preset response", "preset", name)
+	}
+}
@@ -0,0 +1,190 @@
+package presetserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/konflux-ci/mintmaker/internal/renovateconfig"
+)
+
+func TestServeHTTP(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "mintmaker",
+			Name:      "presets",
+			Labels:    map[string]string{renovateconfig.PresetLabel: "true"},
+		},
+		Data: map[string]string{
+			"stable-fedora.json5": `// comment
+				{packageRules: [{matchPackageNames: ["fedora"], enabled: false}]}`,
+		},
+	}
+	server := &Server{Client: fake.NewClientBuilder().WithObjects(configMap).Build(), Namespace: "mintmaker"}
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "preset served as JSON",
+			method:       http.MethodGet,
+			path:         "/presets/stable-fedora.json",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"packageRules":[{"enabled":false,"matchPackageNames":["fedora"]}]}`,
+		},
+		{
+			name:         "unknown preset",
+			method:       http.MethodGet,
+			path:         "/presets/unknown.json",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "path without extension",
+			method:       http.MethodGet,
+			path:         "/presets/stable-fedora",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "method not allowed",
+			method:       http.MethodPost,
+			path:         "/presets/stable-fedora.json",
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+			if rec.Code != tc.expectedCode {
+				t.Errorf("expected status %d, got %d", tc.expectedCode, rec.Code)
+			}
+			if tc.expectedBody != "" && rec.Body.String() != tc.expectedBody {
+				t.Errorf("expected body %s, got %s", tc.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestStartTLS(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "mintmaker",
+			Name:      "presets",
+			Labels:    map[string]string{renovateconfig.PresetLabel: "true"},
+		},
+		Data: map[string]string{"automerge.json": `{"automerge": true}`},
+	}
+	certFile, keyFile, certPool := writeServingCert(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	_ = listener.Close()
+
+	server := &Server{Client: fake.NewClientBuilder().WithObjects(configMap).Build(), Namespace: "mintmaker", BindAddress: address, CertFile: certFile, KeyFile: keyFile}
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.Start(ctx) }()
+
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: certPool}}}
+	var body []byte
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		resp, err := httpClient.Get("https://" + address + "/presets/automerge.json")
+		if err == nil {
+			body, err = io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("preset server did not serve HTTPS: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if string(body) != `{"automerge":true}` {
+		t.Errorf("unexpected body %s", body)
+	}
+
+	// Plain HTTP is not served
+	if resp, err := http.Get("http://" + address + "/presets/automerge.json"); err == nil {
+		_ = resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			t.Error("expected plain HTTP requests to fail")
+		}
+	}
+
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Errorf("unexpected error on shutdown: %v", err)
+	}
+}
+
+// writeServingCert writes a self-signed certificate for 127.0.0.1 and
+// returns the certificate and key files and a pool trusting it
+func writeServingCert(t *testing.T) (string, string, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "mintmaker-presets"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	certPool := x509.NewCertPool()
+	certPool.AddCert(cert)
+	return certFile, keyFile, certPool
+}
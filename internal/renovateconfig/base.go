@@ -0,0 +1,165 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renovateconfig
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logger "sigs.k8s.io/controller-runtime/pkg/log"
+
+	mintmakermetrics "github.com/konflux-ci/mintmaker/internal/metrics"
+)
+
+const (
+	// RenovateConfigKey holds the global Renovate config in the base ConfigMap
+	RenovateConfigKey = "renovate.json"
+	// SelfHostedConfigKey holds the self-hosted Renovate config in the base ConfigMap
+	SelfHostedConfigKey = "self_hosted.json"
+)
+
+// BaseLoader reads the base config from a ConfigMap and reads it again once
+// the refresh interval passed. An invalid ConfigMap is rejected and the last
+// good config stays active.
+type BaseLoader struct {
+	// Key is the ConfigMap holding RenovateConfigKey and SelfHostedConfigKey
+	Key types.NamespacedName
+
+	mu           sync.RWMutex
+	config       *Config
+	hash         string
+	rejectedHash string
+	checkedAt    time.Time
+	now          func() time.Time
+}
+
+// NewBaseLoader returns a loader for the base config in the given ConfigMap
+func NewBaseLoader(key types.NamespacedName) *BaseLoader {
+	return &BaseLoader{Key: key, now: time.Now}
+}
+
+// Get returns the active base config, reading the ConfigMap first when it
+// wasn't read within refreshInterval. The config is shared, use a Builder to
+// derive from it.
+func (l *BaseLoader) Get(ctx context.Context, k8sClient client.Client, refreshInterval time.Duration) (*Config, error) {
+	l.mu.RLock()
+	if l.config != nil && l.now().Sub(l.checkedAt) < refreshInterval {
+		defer l.mu.RUnlock()
+		return l.config, nil
+	}
+	l.mu.RUnlock()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// Another caller may have refreshed while we waited for the lock
+	if l.config != nil && l.now().Sub(l.checkedAt) < refreshInterval {
+		return l.config, nil
+	}
+	if err := l.refresh(ctx, k8sClient); err != nil {
+		if l.config == nil {
+			return nil, err
+		}
+		logger.FromContext(ctx).Error(err, "keeping the last good Renovate base config", "hash", l.hash)
+	}
+	return l.config, nil
+}
+
+// Hash returns the hash of the active base config, empty before the first load
+func (l *BaseLoader) Hash() string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.hash
+}
+
+// refresh reads the ConfigMap and activates it when it changed and is valid.
+// Must be called with the write lock held.
+func (l *BaseLoader) refresh(ctx context.Context, k8sClient client.Client) error {
+	// Failures are retried after the next refresh interval too, the last good
+	// config stays active meanwhile
+	l.checkedAt = l.now()
+
+	configMap := corev1.ConfigMap{}
+	if err := k8sClient.Get(ctx, l.Key, &configMap); err != nil {
+		return fmt.Errorf("failed to get Renovate base config %s: %w", l.Key, err)
+	}
+
+	hash := HashBaseConfig(configMap.Data)
+	if hash == l.hash {
+		return nil
+	}
+	config, err := ParseBaseConfig(configMap.Data)
+	if err != nil {
+		// Count every invalid revision once, not every time it is checked again
+		if hash != l.rejectedHash {
+			mintmakermetrics.CountRenovateBaseConfigRejection()
+			l.rejectedHash = hash
+		}
+		return fmt.Errorf("rejected Renovate base config %s with hash %s: %w", l.Key, hash, err)
+	}
+
+	logger.FromContext(ctx).Info("activated Renovate base config", "configMap", l.Key.String(), "hash", hash, "previousHash", l.hash)
+	mintmakermetrics.RecordRenovateBaseConfig(hash)
+	l.config = config
+	l.hash = hash
+	return nil
+}
+
+// ParseBaseConfig validates the base ConfigMap data against the Renovate schema
+// and returns the self-hosted config layered over the global config
+func ParseBaseConfig(data map[string]string) (*Config, error) {
+	config, err := parseBaseConfigKey(data, RenovateConfigKey)
+	if err != nil {
+		return nil, err
+	}
+	selfHostedConfig, err := parseBaseConfigKey(data, SelfHostedConfigKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewBuilder(config).With(LayerGlobal, selfHostedConfig).Build(), nil
+}
+
+func parseBaseConfigKey(data map[string]string, key string) (*Config, error) {
+	value, ok := data[key]
+	if !ok {
+		return nil, fmt.Errorf("%s is missing", key)
+	}
+	config, err := Parse([]byte(value))
+	if err != nil {
+		return nil, fmt.Errorf("%s is invalid: %w", key, err)
+	}
+	if len(config.Repositories) > 0 {
+		return nil, fmt.Errorf("%s must not set repositories, they are set per PipelineRun", key)
+	}
+	if err := Validate(config); err != nil {
+		return nil, fmt.Errorf("%s is invalid: %w", key, err)
+	}
+	return config, nil
+}
+
+// HashBaseConfig returns a stable hash of the base ConfigMap data
+func HashBaseConfig(data map[string]string) string {
+	h := sha256.New()
+	for _, key := range []string{RenovateConfigKey, SelfHostedConfigKey} {
+		fmt.Fprintf(h, "%s\x00%d\x00%s", key, len(data[key]), data[key])
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
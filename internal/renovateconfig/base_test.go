@@ -0,0 +1,102 @@
+package renovateconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBaseLoader(t *testing.T) {
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: "mintmaker", Name: "renovate-config"}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+		Data: map[string]string{
+			RenovateConfigKey:   `{"onboarding": false, "vulnerability": {"enabled": true}}`,
+			SelfHostedConfigKey: `{"platform": "github", "vulnerability": {"labels": ["security"]}}`,
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithObjects(configMap).Build()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	loader := NewBaseLoader(key)
+	loader.now = func() time.Time { return now }
+	const refreshInterval = 5 * time.Minute
+
+	config, err := loader.Get(ctx, k8sClient, refreshInterval)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.Platform != "github" || config.Options["onboarding"] != false {
+		t.Errorf("expected the self-hosted config layered over the global config, got %+v", config)
+	}
+	if vulnerability := config.Options["vulnerability"].(map[string]any); len(vulnerability) != 2 {
+		t.Errorf("expected merged vulnerability options, got %v", vulnerability)
+	}
+	firstHash := loader.Hash()
+	if firstHash != HashBaseConfig(configMap.Data) {
+		t.Errorf("expected hash %s, got %s", HashBaseConfig(configMap.Data), firstHash)
+	}
+
+	// Updates are picked up only after the refresh interval
+	configMap.Data[SelfHostedConfigKey] = `{"platform": "gitlab"}`
+	if err := k8sClient.Update(ctx, configMap); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now = now.Add(time.Minute)
+	if config, _ := loader.Get(ctx, k8sClient, refreshInterval); config.Platform != "github" {
+		t.Errorf("expected the cached config within the refresh interval, got platform %q", config.Platform)
+	}
+	now = now.Add(refreshInterval)
+	if config, _ := loader.Get(ctx, k8sClient, refreshInterval); config.Platform != "gitlab" {
+		t.Errorf("expected the updated config after the refresh interval, got platform %q", config.Platform)
+	}
+	secondHash := loader.Hash()
+	if secondHash == firstHash {
+		t.Error("expected the hash to change with the config")
+	}
+
+	// Invalid updates are rejected and the last good config is kept
+	for _, data := range []map[string]string{
+		{RenovateConfigKey: `{"onboarding": `, SelfHostedConfigKey: `{}`},
+		{RenovateConfigKey: `{}`},
+		{RenovateConfigKey: `{}`, SelfHostedConfigKey: `{"repositories": [{"repository": "org/repo"}]}`},
+		{RenovateConfigKey: `{"onboarding": "no"}`, SelfHostedConfigKey: `{}`},
+	} {
+		configMap.Data = data
+		if err := k8sClient.Update(ctx, configMap); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		now = now.Add(refreshInterval)
+		config, err := loader.Get(ctx, k8sClient, refreshInterval)
+		if err != nil {
+			t.Fatalf("expected the last good config instead of an error, got %v", err)
+		}
+		if config.Platform != "gitlab" || loader.Hash() != secondHash {
+			t.Errorf("expected the last good config to stay active for %v, got platform %q", data, config.Platform)
+		}
+	}
+}
+
+func TestBaseLoaderWithoutGoodConfig(t *testing.T) {
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: "mintmaker", Name: "renovate-config"}
+	loader := NewBaseLoader(key)
+
+	if _, err := loader.Get(ctx, fake.NewClientBuilder().Build(), time.Minute); err == nil {
+		t.Error("expected an error when the ConfigMap doesn't exist")
+	}
+
+	invalid := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+		Data:       map[string]string{RenovateConfigKey: `[]`, SelfHostedConfigKey: `{}`},
+	}
+	if _, err := loader.Get(ctx, fake.NewClientBuilder().WithObjects(invalid).Build(), time.Minute); err == nil {
+		t.Error("expected an error when the first config is invalid")
+	}
+}
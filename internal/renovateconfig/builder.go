@@ -0,0 +1,148 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renovateconfig
+
+import (
+	"slices"
+)
+
+// Layer orders overlays, higher layers override lower ones
+type Layer int
+
+const (
+	// LayerGlobal is the MintMaker wide configuration on top of the base config
+	LayerGlobal Layer = iota
+	// LayerPlatform holds the settings of the git platform
+	LayerPlatform
+	// LayerNamespace holds the settings of the component namespace
+	LayerNamespace
+	// LayerComponent holds the settings of a single component
+	LayerComponent
+	// LayerRun holds the settings of a single PipelineRun
+	LayerRun
+)
+
+func (l Layer) String() string {
+	switch l {
+	case LayerGlobal:
+		return "global"
+	case LayerPlatform:
+		return "platform"
+	case LayerNamespace:
+		return "namespace"
+	case LayerComponent:
+		return "component"
+	case LayerRun:
+		return "run"
+	}
+	return "unknown"
+}
+
+// concatenatedOptions are array options that are appended across layers, as
+// Renovate does when merging configurations, instead of being replaced
+var concatenatedOptions = []string{"packageRules", "customManagers"}
+
+type overlay struct {
+	layer  Layer
+	config *Config
+}
+
+// Builder builds a Renovate configuration from a base configuration and
+// overlays. The base and the overlays are never modified, so a cached base
+// can be shared between concurrent builds.
+type Builder struct {
+	base     *Config
+	overlays []overlay
+}
+
+// NewBuilder returns a builder starting from base
+func NewBuilder(base *Config) *Builder {
+	return &Builder{base: base}
+}
+
+// With adds an overlay on the given layer. Overlays on the same layer apply
+// in the order they were added.
+func (b *Builder) With(layer Layer, config *Config) *Builder {
+	if config != nil {
+		b.overlays = append(b.overlays, overlay{layer: layer, config: config})
+	}
+	return b
+}
+
+// Build returns a new configuration with all overlays applied in layer order:
+//   - typed scalar options are overridden when set on the overlay
+//   - host rules and the concatenatedOptions are appended
+//   - repositories are replaced when set on the overlay
+//   - other objects are merged key by key, other arrays are replaced
+func (b *Builder) Build() *Config {
+	result := b.base.DeepCopy()
+	if result == nil {
+		result = &Config{}
+	}
+
+	overlays := slices.Clone(b.overlays)
+	slices.SortStableFunc(overlays, func(a, b overlay) int { return int(a.layer) - int(b.layer) })
+	for _, o := range overlays {
+		result.merge(o.config.DeepCopy())
+	}
+	return result
+}
+
+// merge applies src on top of c, src must not be used afterwards
+func (c *Config) merge(src *Config) {
+	if src.Platform != "" {
+		c.Platform = src.Platform
+	}
+	if src.Endpoint != "" {
+		c.Endpoint = src.Endpoint
+	}
+	if src.Username != nil {
+		c.Username = src.Username
+	}
+	if src.GitAuthor != nil {
+		c.GitAuthor = src.GitAuthor
+	}
+	if src.GitURL != "" {
+		c.GitURL = src.GitURL
+	}
+	c.HostRules = append(c.HostRules, src.HostRules...)
+	if src.Repositories != nil {
+		c.Repositories = src.Repositories
+	}
+	if len(src.Options) > 0 {
+		if c.Options == nil {
+			c.Options = map[string]any{}
+		}
+		mergeOptions(c.Options, src.Options)
+	}
+}
+
+func mergeOptions(dst, src map[string]any) {
+	for key, value := range src {
+		switch v := value.(type) {
+		case map[string]any:
+			if existing, ok := dst[key].(map[string]any); ok {
+				mergeOptions(existing, v)
+				continue
+			}
+		case []any:
+			if existing, ok := dst[key].([]any); ok && slices.Contains(concatenatedOptions, key) {
+				dst[key] = append(existing, v...)
+				continue
+			}
+		}
+		dst[key] = value
+	}
+}
@@ -0,0 +1,147 @@
+package renovateconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestBuildLayerOrder(t *testing.T) {
+	base := &Config{Platform: "github", Options: map[string]any{"onboarding": false}}
+
+	// Overlays are added out of order on purpose
+	config := NewBuilder(base).
+		With(LayerRun, &Config{Options: map[string]any{"dryRun": "full"}}).
+		With(LayerNamespace, &Config{Options: map[string]any{"dryRun": "lookup", "automerge": true}}).
+		With(LayerPlatform, &Config{Platform: "gitlab", Endpoint: "https://gitlab.com/api/v4/"}).
+		With(LayerComponent, &Config{Options: map[string]any{"automerge": false}}).
+		With(LayerGlobal, &Config{Platform: "forgejo", Options: map[string]any{"onboarding": true}}).
+		Build()
+
+	if config.Platform != "gitlab" || config.Endpoint != "https://gitlab.com/api/v4/" {
+		t.Errorf("expected the platform layer to override the global layer, got %q %q", config.Platform, config.Endpoint)
+	}
+	expected := map[string]any{"onboarding": true, "dryRun": "full", "automerge": false}
+	if !reflect.DeepEqual(config.Options, expected) {
+		t.Errorf("expected options %v, got %v", expected, config.Options)
+	}
+}
+
+func TestBuildSameLayerKeepsInsertionOrder(t *testing.T) {
+	first, second := "first", "second"
+	config := NewBuilder(nil).
+		With(LayerComponent, &Config{GitAuthor: &first}).
+		With(LayerComponent, &Config{GitAuthor: &second}).
+		Build()
+	if config.GitAuthor == nil || *config.GitAuthor != second {
+		t.Errorf("expected the last overlay of a layer to win, got %v", config.GitAuthor)
+	}
+}
+
+func TestBuildMergeSemantics(t *testing.T) {
+	base := &Config{
+		HostRules:    []HostRule{{MatchHost: "github.com", Token: "base"}},
+		Repositories: []Repository{{Repository: "org/base"}},
+		Options: map[string]any{
+			"packageRules":  []any{map[string]any{"matchManagers": []any{"npm"}}},
+			"extends":       []any{"config:recommended"},
+			"vulnerability": map[string]any{"enabled": true, "labels": []any{"security"}},
+		},
+	}
+	config := NewBuilder(base).
+		With(LayerComponent, &Config{
+			HostRules:    []HostRule{{MatchHost: "quay.io", Username: "u", Password: "p"}},
+			Repositories: []Repository{{Repository: "org/repo", BaseBranchPatterns: []string{"main"}}},
+			Options: map[string]any{
+				"packageRules":  []any{map[string]any{"matchManagers": []any{"pip"}}},
+				"extends":       []any{"config:base"},
+				"vulnerability": map[string]any{"labels": []any{"cve"}},
+			},
+		}).
+		Build()
+
+	if len(config.HostRules) != 2 || config.HostRules[1].MatchHost != "quay.io" {
+		t.Errorf("expected host rules to be appended, got %+v", config.HostRules)
+	}
+	if len(config.Repositories) != 1 || config.Repositories[0].Repository != "org/repo" {
+		t.Errorf("expected repositories to be replaced, got %+v", config.Repositories)
+	}
+	if rules := config.Options["packageRules"].([]any); len(rules) != 2 {
+		t.Errorf("expected package rules to be appended, got %v", rules)
+	}
+	if extends := config.Options["extends"].([]any); !reflect.DeepEqual(extends, []any{"config:base"}) {
+		t.Errorf("expected other arrays to be replaced, got %v", extends)
+	}
+	expectedVulnerability := map[string]any{"enabled": true, "labels": []any{"cve"}}
+	if !reflect.DeepEqual(config.Options["vulnerability"], expectedVulnerability) {
+		t.Errorf("expected objects to be merged key by key, got %v", config.Options["vulnerability"])
+	}
+}
+
+func TestBuildIsolation(t *testing.T) {
+	base := &Config{
+		HostRules: []HostRule{{MatchHost: "github.com"}},
+		Options: map[string]any{
+			"packageRules":  []any{map[string]any{"enabled": true}},
+			"vulnerability": map[string]any{"enabled": true},
+		},
+	}
+	baseSnapshot := mustMarshal(t, base)
+	overlay := &Config{
+		HostRules: []HostRule{{MatchHost: "quay.io"}},
+		Options:   map[string]any{"vulnerability": map[string]any{"labels": []any{"cve"}}},
+	}
+	overlaySnapshot := mustMarshal(t, overlay)
+
+	config := NewBuilder(base).With(LayerComponent, overlay).Build()
+	config.HostRules[0].MatchHost = "changed"
+	config.Options["packageRules"].([]any)[0].(map[string]any)["enabled"] = false
+	config.Options["vulnerability"].(map[string]any)["labels"].([]any)[0] = "changed"
+
+	if got := mustMarshal(t, base); got != baseSnapshot {
+		t.Errorf("building changed the base config:\nbefore %s\nafter  %s", baseSnapshot, got)
+	}
+	if got := mustMarshal(t, overlay); got != overlaySnapshot {
+		t.Errorf("building changed the overlay:\nbefore %s\nafter  %s", overlaySnapshot, got)
+	}
+
+	// Host rules of one component must not leak into the next build
+	next := NewBuilder(base).Build()
+	if len(next.HostRules) != 1 || next.HostRules[0].MatchHost != "github.com" {
+		t.Errorf("expected only the base host rules, got %+v", next.HostRules)
+	}
+}
+
+func TestBuildConcurrently(t *testing.T) {
+	base := &Config{Options: map[string]any{"packageRules": []any{}}}
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() {
+			repository := fmt.Sprintf("org/repo-%d", i)
+			config := NewBuilder(base).
+				With(LayerComponent, &Config{HostRules: []HostRule{{MatchHost: repository}}}).
+				With(LayerRun, &Config{Repositories: []Repository{{Repository: repository}}}).
+				Build()
+			if len(config.HostRules) != 1 || config.HostRules[0].MatchHost != repository {
+				t.Errorf("unexpected host rules for %s: %+v", repository, config.HostRules)
+			}
+		})
+	}
+	wg.Wait()
+
+	if len(base.HostRules) != 0 || base.Repositories != nil {
+		t.Errorf("concurrent builds changed the base config: %+v", base)
+	}
+}
+
+func mustMarshal(t *testing.T, config *Config) string {
+	t.Helper()
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return string(data)
+}
@@ -0,0 +1,250 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package renovateconfig models the Renovate configuration MintMaker generates
+// for a PipelineRun and builds it from layered overlays on top of the base config.
+package renovateconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Config is a Renovate configuration. The options MintMaker sets itself are
+// typed, every other option is kept untouched in Options.
+type Config struct {
+	Platform     string       `json:"platform,omitempty"`
+	Endpoint     string       `json:"endpoint,omitempty"`
+	Username     *string      `json:"username,omitempty"`
+	GitAuthor    *string      `json:"gitAuthor,omitempty"`
+	GitURL       string       `json:"gitUrl,omitempty"`
+	HostRules    []HostRule   `json:"hostRules,omitempty"`
+	Repositories []Repository `json:"repositories,omitempty"`
+
+	// Options holds the remaining options as decoded by encoding/json
+	Options map[string]any `json:"-"`
+}
+
+// HostRule is a Renovate host rule
+type HostRule struct {
+	MatchHost string `json:"matchHost,omitempty"`
+	HostType  string `json:"hostType,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Password  string `json:"password,omitempty"`
+	Token     string `json:"token,omitempty"`
+
+	// Options holds the remaining host rule options as decoded by encoding/json
+	Options map[string]any `json:"-"`
+}
+
+// Repository is a repository Renovate processes
+type Repository struct {
+	Repository         string   `json:"repository"`
+	BaseBranchPatterns []string `json:"baseBranchPatterns,omitempty"`
+}
+
+// Parse decodes a Renovate configuration
+func Parse(data []byte) (*Config, error) {
+	config := &Config{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("error unmarshaling Renovate config: %w", err)
+	}
+	return config, nil
+}
+
+// JSON returns the indented JSON encoding of the configuration
+func (c *Config) JSON() (string, error) {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error marshaling Renovate config: %w", err)
+	}
+	return string(data), nil
+}
+
+func (c Config) MarshalJSON() ([]byte, error) {
+	type plain Config
+	return marshalWithOptions(plain(c), c.Options)
+}
+
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type plain Config
+	var typed plain
+	options, err := unmarshalWithOptions(data, &typed)
+	if err != nil {
+		return err
+	}
+	*c = Config(typed)
+	c.Options = options
+	return nil
+}
+
+func (r HostRule) MarshalJSON() ([]byte, error) {
+	type plain HostRule
+	return marshalWithOptions(plain(r), r.Options)
+}
+
+func (r *HostRule) UnmarshalJSON(data []byte) error {
+	type plain HostRule
+	var typed plain
+	options, err := unmarshalWithOptions(data, &typed)
+	if err != nil {
+		return err
+	}
+	*r = HostRule(typed)
+	r.Options = options
+	return nil
+}
+
+// Secrets returns the passwords and tokens of the host rules, without
+// duplicates
+func (c *Config) Secrets() []string {
+	var secrets []string
+	for _, rule := range c.HostRules {
+		for _, secret := range []string{rule.Password, rule.Token} {
+			if secret != "" && !slices.Contains(secrets, secret) {
+				secrets = append(secrets, secret)
+			}
+		}
+	}
+	return secrets
+}
+
+// DeepCopy returns a copy of the configuration sharing no memory with c
+func (c *Config) DeepCopy() *Config {
+	if c == nil {
+		return nil
+	}
+	out := *c
+	out.Username = copyString(c.Username)
+	out.GitAuthor = copyString(c.GitAuthor)
+	if c.HostRules != nil {
+		out.HostRules = make([]HostRule, len(c.HostRules))
+		for i, rule := range c.HostRules {
+			out.HostRules[i] = rule.DeepCopy()
+		}
+	}
+	if c.Repositories != nil {
+		out.Repositories = make([]Repository, len(c.Repositories))
+		for i, repo := range c.Repositories {
+			out.Repositories[i] = repo.DeepCopy()
+		}
+	}
+	out.Options = copyOptions(c.Options)
+	return &out
+}
+
+// DeepCopy returns a copy of the host rule sharing no memory with r
+func (r HostRule) DeepCopy() HostRule {
+	r.Options = copyOptions(r.Options)
+	return r
+}
+
+// DeepCopy returns a copy of the repository sharing no memory with r
+func (r Repository) DeepCopy() Repository {
+	if r.BaseBranchPatterns != nil {
+		r.BaseBranchPatterns = append([]string(nil), r.BaseBranchPatterns...)
+	}
+	return r
+}
+
+// marshalWithOptions encodes typed and adds the options it doesn't define itself
+func marshalWithOptions(typed any, options map[string]any) ([]byte, error) {
+	data, err := json.Marshal(typed)
+	if err != nil {
+		return nil, err
+	}
+	if len(options) == 0 {
+		return data, nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	merged := make(map[string]any, len(options)+len(fields))
+	maps.Copy(merged, options)
+	maps.Copy(merged, fields)
+	return json.Marshal(merged)
+}
+
+// unmarshalWithOptions decodes data into typed and returns the options typed
+// doesn't define, nil if there are none
+func unmarshalWithOptions(data []byte, typed any) (map[string]any, error) {
+	if err := json.Unmarshal(data, typed); err != nil {
+		return nil, err
+	}
+	var options map[string]any
+	if err := json.Unmarshal(data, &options); err != nil {
+		return nil, err
+	}
+	for _, key := range jsonKeys(reflect.TypeOf(typed).Elem()) {
+		delete(options, key)
+	}
+	if len(options) == 0 {
+		return nil, nil
+	}
+	return options, nil
+}
+
+// jsonKeys returns the JSON keys of the fields of a struct type
+func jsonKeys(t reflect.Type) []string {
+	keys := make([]string, 0, t.NumField())
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
+
+func copyString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	out := *s
+	return &out
+}
+
+func copyOptions(options map[string]any) map[string]any {
+	if options == nil {
+		return nil
+	}
+	return copyValue(options).(map[string]any)
+}
+
+// copyValue deep copies a value decoded by encoding/json
+func copyValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			out[key] = copyValue(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = copyValue(item)
+		}
+		return out
+	case []string:
+		return append([]string(nil), v...)
+	default:
+		return v
+	}
+}
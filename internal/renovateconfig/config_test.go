@@ -0,0 +1,110 @@
+package renovateconfig
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseKeepsUnknownOptions(t *testing.T) {
+	data := `{
+		"platform": "gitlab",
+		"username": "",
+		"onboarding": false,
+		"packageRules": [{"matchManagers": ["npm"], "enabled": false}],
+		"hostRules": [{"matchHost": "quay.io", "hostType": "docker", "concurrentRequestLimit": 2}]
+	}`
+
+	config, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.Platform != "gitlab" {
+		t.Errorf("expected platform gitlab, got %q", config.Platform)
+	}
+	if config.Username == nil || *config.Username != "" {
+		t.Errorf("expected an explicitly empty username, got %v", config.Username)
+	}
+	if _, ok := config.Options["platform"]; ok {
+		t.Error("typed options must not be duplicated in Options")
+	}
+	if len(config.HostRules) != 1 || config.HostRules[0].Options["concurrentRequestLimit"] != float64(2) {
+		t.Errorf("expected unknown host rule options to be kept, got %+v", config.HostRules)
+	}
+
+	encoded, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got, want map[string]any
+	if err := json.Unmarshal(encoded, &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := json.Unmarshal([]byte(data), &want); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip changed the config:\ngot  %v\nwant %v", got, want)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, data := range []string{`not json`, `{"hostRules": "quay.io"}`, `{"repositories": [{"repository": 1}]}`} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("expected an error parsing %s", data)
+		}
+	}
+}
+
+func TestDeepCopy(t *testing.T) {
+	username := "bot"
+	original := &Config{
+		Username:     &username,
+		HostRules:    []HostRule{{MatchHost: "quay.io", Options: map[string]any{"timeout": float64(10)}}},
+		Repositories: []Repository{{Repository: "org/repo", BaseBranchPatterns: []string{"main"}}},
+		Options: map[string]any{
+			"packageRules": []any{map[string]any{"enabled": true}},
+			"lockFileMaintenance": map[string]any{
+				"schedule": []any{"before 5am"},
+			},
+		},
+	}
+	snapshot, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	copied := original.DeepCopy()
+	*copied.Username = "other"
+	copied.HostRules[0].MatchHost = "registry.redhat.io"
+	copied.HostRules[0].Options["timeout"] = float64(20)
+	copied.Repositories[0].BaseBranchPatterns[0] = "release"
+	copied.Options["packageRules"].([]any)[0].(map[string]any)["enabled"] = false
+	copied.Options["lockFileMaintenance"].(map[string]any)["schedule"].([]any)[0] = "after 10pm"
+
+	after, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(after) != string(snapshot) {
+		t.Errorf("modifying the copy changed the original:\nbefore %s\nafter  %s", snapshot, after)
+	}
+}
+
+func TestSecrets(t *testing.T) {
+	config := &Config{
+		HostRules: []HostRule{
+			{MatchHost: "quay.io", HostType: "docker", Username: "robot", Password: "quay-password"},
+			{MatchHost: "https://quay.io/v2/org/repo", HostType: "docker", Token: "repo-token"},
+			{MatchHost: "mirror.example.com", HostType: "docker", Username: "robot", Password: "quay-password"},
+			{MatchHost: "registry.npmjs.org", HostType: "npm"},
+		},
+	}
+	expected := []string{"quay-password", "repo-token"}
+	if secrets := config.Secrets(); !reflect.DeepEqual(secrets, expected) {
+		t.Errorf("expected %v, got %v", expected, secrets)
+	}
+	if secrets := (&Config{}).Secrets(); secrets != nil {
+		t.Errorf("expected no secrets, got %v", secrets)
+	}
+}
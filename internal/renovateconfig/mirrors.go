@@ -0,0 +1,89 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renovateconfig
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ImageDigestMirrorSetGVK is the OpenShift cluster wide image mirror config
+var ImageDigestMirrorSetGVK = schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1", Kind: "ImageDigestMirrorSet"}
+
+// Mirror maps a registry, or a repository prefix within it, to its mirrors in
+// order of preference
+type Mirror struct {
+	Source  string
+	Mirrors []string
+}
+
+// ClusterMirrors returns the mirrors of the cluster's ImageDigestMirrorSets in
+// name order. It returns nil on clusters without ImageDigestMirrorSets.
+func ClusterMirrors(ctx context.Context, k8sClient client.Client) ([]Mirror, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(ImageDigestMirrorSetGVK.GroupVersion().WithKind(ImageDigestMirrorSetGVK.Kind + "List"))
+	if err := k8sClient.List(ctx, list); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list ImageDigestMirrorSets: %w", err)
+	}
+	sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].GetName() < list.Items[j].GetName() })
+
+	var mirrors []Mirror
+	for _, item := range list.Items {
+		entries, _, err := unstructured.NestedSlice(item.Object, "spec", "imageDigestMirrors")
+		if err != nil {
+			return nil, fmt.Errorf("ImageDigestMirrorSet %s is invalid: %w", item.GetName(), err)
+		}
+		for _, entry := range entries {
+			fields, ok := entry.(map[string]any)
+			if !ok {
+				continue
+			}
+			source, _, _ := unstructured.NestedString(fields, "source")
+			targets, _, _ := unstructured.NestedStringSlice(fields, "mirrors")
+			if source != "" && len(targets) > 0 {
+				mirrors = append(mirrors, Mirror{Source: source, Mirrors: targets})
+			}
+		}
+	}
+	return mirrors, nil
+}
+
+// MirrorOverlay returns the registryAliases rewriting every source to its
+// preferred mirror. The first mirror of a source wins. Credentials of a source
+// are never copied to its mirror, which is a different host: a mirror is only
+// authenticated to with its own registry auths.
+func MirrorOverlay(mirrors []Mirror) *Config {
+	if len(mirrors) == 0 {
+		return nil
+	}
+
+	aliases := map[string]any{}
+	for _, mirror := range mirrors {
+		if _, ok := aliases[mirror.Source]; ok || len(mirror.Mirrors) == 0 {
+			continue
+		}
+		aliases[mirror.Source] = mirror.Mirrors[0]
+	}
+	return &Config{Options: map[string]any{"registryAliases": aliases}}
+}
@@ -0,0 +1,59 @@
+package renovateconfig
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMirrorOverlay(t *testing.T) {
+	mirrors := []Mirror{
+		{Source: "quay.io/konflux-ci", Mirrors: []string{"mirror.example.com/konflux-ci", "backup.example.com/konflux-ci"}},
+		{Source: "registry.redhat.io", Mirrors: []string{"rh-mirror.example.com"}},
+		{Source: "docker.io", Mirrors: []string{"docker-mirror.example.com"}},
+		{Source: "quay.io/konflux-ci", Mirrors: []string{"ignored.example.com"}},
+	}
+
+	config := MirrorOverlay(mirrors)
+	expectedAliases := map[string]any{
+		"quay.io/konflux-ci": "mirror.example.com/konflux-ci",
+		"registry.redhat.io": "rh-mirror.example.com",
+		"docker.io":          "docker-mirror.example.com",
+	}
+	if !reflect.DeepEqual(config.Options["registryAliases"], expectedAliases) {
+		t.Errorf("expected aliases %v, got %v", expectedAliases, config.Options["registryAliases"])
+	}
+	// Mirrors only get the host rules of their own registry auths, source
+	// credentials are not copied to them
+	if len(config.HostRules) != 0 {
+		t.Errorf("expected no host rules, got %+v", config.HostRules)
+	}
+
+	if MirrorOverlay(nil) != nil {
+		t.Error("expected no overlay without mirrors")
+	}
+}
+
+func TestClusterMirrors(t *testing.T) {
+	idms := &unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{"name": "mirrors"},
+		"spec": map[string]any{"imageDigestMirrors": []any{
+			map[string]any{"source": "quay.io/konflux-ci", "mirrors": []any{"mirror.example.com/konflux-ci"}},
+			map[string]any{"source": "registry.redhat.io"},
+		}},
+	}}
+	idms.SetGroupVersionKind(ImageDigestMirrorSetGVK)
+	k8sClient := fake.NewClientBuilder().WithObjects(idms).Build()
+
+	mirrors, err := ClusterMirrors(context.Background(), k8sClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Mirror{{Source: "quay.io/konflux-ci", Mirrors: []string{"mirror.example.com/konflux-ci"}}}
+	if !reflect.DeepEqual(mirrors, expected) {
+		t.Errorf("expected mirrors %+v, got %+v", expected, mirrors)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renovateconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logger "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// NamespaceConfigLabel marks ConfigMaps in a component namespace whose
+// RenovateConfigKey is layered over the config of the namespace's components
+const NamespaceConfigLabel = "mintmaker.appstudio.redhat.com/renovate-config"
+
+// DefaultNamespaceOptions are the top level options tenants may set in a
+// namespace ConfigMap. Options affecting the Renovate process itself, such as
+// allowShellExecutorForPostUpgradeCommands or redisUrl, are deliberately missing.
+var DefaultNamespaceOptions = []string{
+	"addLabels",
+	"assignees",
+	"automerge",
+	"automergeSchedule",
+	"automergeStrategy",
+	"automergeType",
+	"branchConcurrentLimit",
+	"branchPrefix",
+	"commitMessagePrefix",
+	"dependencyDashboard",
+	"dependencyDashboardTitle",
+	"enabledManagers",
+	"ignoreDeps",
+	"ignorePaths",
+	"labels",
+	"lockFileMaintenance",
+	"minimumReleaseAge",
+	"packageRules",
+	"prConcurrentLimit",
+	"prFooter",
+	"prHeader",
+	"prHourlyLimit",
+	"rangeStrategy",
+	"rebaseWhen",
+	"reviewers",
+	"schedule",
+	"semanticCommits",
+	"separateMajorMinor",
+	"separateMinorPatch",
+	"timezone",
+	"vulnerabilityAlerts",
+}
+
+// NamespaceOverlay returns the options of the labelled ConfigMaps in namespace
+// restricted to allowed, merged in ConfigMap name order, and the options that
+// were dropped because they aren't allowed. It returns nil when the namespace
+// has no such ConfigMap. ConfigMaps with invalid JSON are skipped.
+func NamespaceOverlay(ctx context.Context, k8sClient client.Client, namespace string, allowed []string) (*Config, []string, error) {
+	log := logger.FromContext(ctx)
+
+	configMaps := &corev1.ConfigMapList{}
+	if err := k8sClient.List(ctx, configMaps, client.InNamespace(namespace), client.MatchingLabels{NamespaceConfigLabel: "true"}); err != nil {
+		return nil, nil, fmt.Errorf("failed to list Renovate config ConfigMaps in namespace %s: %w", namespace, err)
+	}
+	if len(configMaps.Items) == 0 {
+		return nil, nil, nil
+	}
+	sort.Slice(configMaps.Items, func(i, j int) bool { return configMaps.Items[i].Name < configMaps.Items[j].Name })
+
+	builder := NewBuilder(nil)
+	var dropped []string
+	for _, configMap := range configMaps.Items {
+		options, droppedOptions, err := filterOptions(configMap.Data[RenovateConfigKey], allowed)
+		if err != nil {
+			log.Error(err, "skipping invalid Renovate config ConfigMap", "configMap", configMap.Name, "namespace", namespace)
+			continue
+		}
+		builder.With(LayerNamespace, &Config{Options: options})
+		for _, option := range droppedOptions {
+			dropped = append(dropped, configMap.Name+": "+option)
+		}
+	}
+	return builder.Build(), dropped, nil
+}
+
+// filterOptions decodes a JSON object and splits its options into allowed and
+// dropped ones
+func filterOptions(data string, allowed []string) (map[string]any, []string, error) {
+	if data == "" {
+		return nil, nil, fmt.Errorf("%s is missing", RenovateConfigKey)
+	}
+	var options map[string]any
+	if err := json.Unmarshal([]byte(data), &options); err != nil {
+		return nil, nil, fmt.Errorf("%s is invalid: %w", RenovateConfigKey, err)
+	}
+	// Options MintMaker sets itself are never overridable
+	typed := jsonKeys(reflect.TypeFor[Config]())
+	var dropped []string
+	for option := range options {
+		if !slices.Contains(allowed, option) || slices.Contains(typed, option) {
+			dropped = append(dropped, option)
+			delete(options, option)
+		}
+	}
+	slices.Sort(dropped)
+	return options, dropped, nil
+}
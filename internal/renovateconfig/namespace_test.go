@@ -0,0 +1,83 @@
+package renovateconfig
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func namespaceConfigMap(namespace, name string, labelled bool, data string) *corev1.ConfigMap {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Data:       map[string]string{RenovateConfigKey: data},
+	}
+	if labelled {
+		configMap.Labels = map[string]string{NamespaceConfigLabel: "true"}
+	}
+	return configMap
+}
+
+func TestNamespaceOverlay(t *testing.T) {
+	ctx := context.Background()
+	objects := []client.Object{
+		namespaceConfigMap("tenant", "b-overrides", true, `{"schedule": ["after 10pm"], "labels": ["deps"]}`),
+		namespaceConfigMap("tenant", "a-defaults", true, `{
+			"schedule": ["before 5am"],
+			"prFooter": "Managed by MintMaker",
+			"allowShellExecutorForPostUpgradeCommands": true,
+			"redisUrl": "redis://attacker"
+		}`),
+		namespaceConfigMap("tenant", "broken", true, `{"schedule": `),
+		namespaceConfigMap("tenant", "unlabelled", false, `{"automerge": true}`),
+		namespaceConfigMap("other", "other-tenant", true, `{"automerge": true}`),
+	}
+	k8sClient := fake.NewClientBuilder().WithObjects(objects...).Build()
+
+	config, dropped, err := NamespaceOverlay(ctx, k8sClient, "tenant", DefaultNamespaceOptions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]any{
+		"schedule": []any{"after 10pm"},
+		"labels":   []any{"deps"},
+		"prFooter": "Managed by MintMaker",
+	}
+	if !reflect.DeepEqual(config.Options, expected) {
+		t.Errorf("expected options %v, got %v", expected, config.Options)
+	}
+	expectedDropped := []string{"a-defaults: allowShellExecutorForPostUpgradeCommands", "a-defaults: redisUrl"}
+	if !reflect.DeepEqual(dropped, expectedDropped) {
+		t.Errorf("expected dropped options %v, got %v", expectedDropped, dropped)
+	}
+}
+
+func TestNamespaceOverlayTypedOptions(t *testing.T) {
+	ctx := context.Background()
+	k8sClient := fake.NewClientBuilder().WithObjects(
+		namespaceConfigMap("tenant", "config", true, `{"platform": "gitlab", "hostRules": [{"matchHost": "evil.example.com"}], "automerge": true}`),
+	).Build()
+
+	// Options MintMaker sets itself are never overridable, even when allowed
+	config, dropped, err := NamespaceOverlay(ctx, k8sClient, "tenant", []string{"automerge", "platform", "hostRules"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.Platform != "" || len(config.HostRules) != 0 || config.Options["automerge"] != true {
+		t.Errorf("unexpected overlay %+v", config)
+	}
+	if !reflect.DeepEqual(dropped, []string{"config: hostRules", "config: platform"}) {
+		t.Errorf("unexpected dropped options %v", dropped)
+	}
+}
+
+func TestNamespaceOverlayWithoutConfigMaps(t *testing.T) {
+	config, dropped, err := NamespaceOverlay(context.Background(), fake.NewClientBuilder().Build(), "tenant", DefaultNamespaceOptions)
+	if err != nil || config != nil || dropped != nil {
+		t.Errorf("expected no overlay, got %+v %v %v", config, dropped, err)
+	}
+}
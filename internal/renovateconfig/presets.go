@@ -0,0 +1,135 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renovateconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/titanous/json5"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logger "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// PresetLabel marks ConfigMaps in the MintMaker namespace whose .json and
+	// .json5 keys are presets of the preset library, named after the key
+	// without extension
+	PresetLabel = "mintmaker.appstudio.redhat.com/renovate-preset"
+	// PresetsAnnotation on a Component lists the comma separated presets of
+	// the preset library its config extends
+	PresetsAnnotation = "mintmaker.appstudio.redhat.com/renovate-presets"
+	// PresetPath is the HTTP path presets are served under, followed by
+	// <name>.json
+	PresetPath = "/presets/"
+)
+
+var presetNameRegexp = regexp.MustCompile(`^[a-z0-9]([a-z0-9._-]*[a-z0-9])?$`)
+
+// LoadPresets returns the valid presets of the labelled ConfigMaps in
+// namespace as JSON by name. ConfigMaps are read in name order and the first
+// preset of a name wins. Invalid presets are skipped.
+func LoadPresets(ctx context.Context, k8sClient client.Reader, namespace string) (map[string][]byte, error) {
+	log := logger.FromContext(ctx)
+
+	configMaps := &corev1.ConfigMapList{}
+	if err := k8sClient.List(ctx, configMaps, client.InNamespace(namespace), client.MatchingLabels{PresetLabel: "true"}); err != nil {
+		return nil, fmt.Errorf("failed to list Renovate preset ConfigMaps in namespace %s: %w", namespace, err)
+	}
+	sort.Slice(configMaps.Items, func(i, j int) bool { return configMaps.Items[i].Name < configMaps.Items[j].Name })
+
+	presets := map[string][]byte{}
+	for _, configMap := range configMaps.Items {
+		keys := make([]string, 0, len(configMap.Data))
+		for key := range configMap.Data {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			extension := path.Ext(key)
+			if extension != ".json" && extension != ".json5" {
+				continue
+			}
+			name := strings.TrimSuffix(key, extension)
+			if _, exists := presets[name]; exists {
+				log.Info("ignoring duplicate Renovate preset", "preset", name, "configMap", configMap.Name)
+				continue
+			}
+			preset, err := parsePreset(name, []byte(configMap.Data[key]))
+			if err != nil {
+				log.Error(err, "skipping invalid Renovate preset", "preset", name, "configMap", configMap.Name)
+				continue
+			}
+			presets[name] = preset
+		}
+	}
+	return presets, nil
+}
+
+// parsePreset validates a JSON or JSON5 preset and returns it as JSON
+func parsePreset(name string, data []byte) ([]byte, error) {
+	if !presetNameRegexp.MatchString(name) {
+		return nil, fmt.Errorf("invalid preset name %q", name)
+	}
+	if err := ValidatePreset(data); err != nil {
+		return nil, err
+	}
+	var value any
+	if err := json5.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("error unmarshaling Renovate preset: %w", err)
+	}
+	return json.Marshal(value)
+}
+
+// ParsePresetNames returns the preset names of a PresetsAnnotation value
+func ParsePresetNames(annotation string) []string {
+	var names []string
+	for name := range strings.SplitSeq(annotation, ",") {
+		if name = strings.TrimSpace(name); name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// PresetOverlay returns the config extending the named presets, served by the
+// preset server at baseURL, after the presets base already extends. Names
+// missing from presets are reported as a SchemaError of the extends option.
+func PresetOverlay(base *Config, names []string, presets map[string][]byte, baseURL string) (*Config, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	// Arrays other than the concatenatedOptions are replaced by overlays
+	var extends []any
+	if base != nil {
+		if inherited, ok := base.Options["extends"].([]any); ok {
+			extends = append(extends, inherited...)
+		}
+	}
+	for _, name := range names {
+		if _, ok := presets[name]; !ok {
+			return nil, &SchemaError{Path: "/extends", Message: fmt.Sprintf("unknown MintMaker preset %q", name)}
+		}
+		extends = append(extends, strings.TrimSuffix(baseURL, "/")+PresetPath+name+".json")
+	}
+	return &Config{Options: map[string]any{"extends": extends}}, nil
+}
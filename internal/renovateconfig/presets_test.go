@@ -0,0 +1,87 @@
+package renovateconfig
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func presetConfigMap(name string, labelled bool, data map[string]string) *corev1.ConfigMap {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "mintmaker", Name: name},
+		Data:       data,
+	}
+	if labelled {
+		configMap.Labels = map[string]string{PresetLabel: "true"}
+	}
+	return configMap
+}
+
+func TestLoadPresets(t *testing.T) {
+	k8sClient := fake.NewClientBuilder().WithObjects(
+		presetConfigMap("a-presets", true, map[string]string{
+			"automerge-digests.json": `{"packageRules": [{"matchUpdateTypes": ["digest"], "automerge": true}]}`,
+			"stable-fedora.json5":    `{packageRules: [{matchPackageNames: ["fedora"], enabled: false,}]}`,
+			"invalid-schema.json":    `{"packageRules": {}}`,
+			"Invalid Name.json":      `{}`,
+			"README.md":              `not a preset`,
+		}),
+		presetConfigMap("b-presets", true, map[string]string{
+			"stable-fedora.json": `{"enabled": false}`,
+		}),
+		presetConfigMap("unlabelled", false, map[string]string{"other.json": `{}`}),
+	).Build()
+
+	presets, err := LoadPresets(context.Background(), k8sClient, "mintmaker")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string][]byte{
+		"automerge-digests": []byte(`{"packageRules":[{"automerge":true,"matchUpdateTypes":["digest"]}]}`),
+		"stable-fedora":     []byte(`{"packageRules":[{"enabled":false,"matchPackageNames":["fedora"]}]}`),
+	}
+	if !reflect.DeepEqual(presets, expected) {
+		t.Errorf("expected presets %s, got %s", expected, presets)
+	}
+}
+
+func TestParsePresetNames(t *testing.T) {
+	names := ParsePresetNames(" stable-fedora, ,automerge-digests,stable-fedora")
+	if !reflect.DeepEqual(names, []string{"stable-fedora", "automerge-digests"}) {
+		t.Errorf("unexpected names %v", names)
+	}
+	if ParsePresetNames("") != nil {
+		t.Error("expected no names for an empty annotation")
+	}
+}
+
+func TestPresetOverlay(t *testing.T) {
+	presets := map[string][]byte{"stable-fedora": []byte(`{}`)}
+	base := &Config{Options: map[string]any{"extends": []any{"config:recommended"}}}
+
+	config, err := PresetOverlay(base, []string{"stable-fedora"}, presets, "http://presets.mintmaker.svc:8091/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []any{"config:recommended", "http://presets.mintmaker.svc:8091/presets/stable-fedora.json"}
+	if !reflect.DeepEqual(config.Options["extends"], expected) {
+		t.Errorf("expected extends %v, got %v", expected, config.Options["extends"])
+	}
+	// The base config is left untouched
+	if len(base.Options["extends"].([]any)) != 1 {
+		t.Errorf("expected the base config to be unchanged, got %v", base.Options["extends"])
+	}
+
+	_, err = PresetOverlay(base, []string{"unknown"}, presets, "http://presets.mintmaker.svc:8091")
+	if schemaErr, ok := AsSchemaError(err); !ok || schemaErr.Path != "/extends" {
+		t.Errorf("expected a schema error for an unknown preset, got %v", err)
+	}
+
+	if config, err := PresetOverlay(base, nil, presets, ""); config != nil || err != nil {
+		t.Errorf("expected no overlay without presets, got %v %v", config, err)
+	}
+}
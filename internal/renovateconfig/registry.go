@@ -0,0 +1,94 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renovateconfig
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logger "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// RegistryTypeLabel marks secrets in a component namespace holding credentials
+// of a package registry, its value is one of the keys of RegistryHostTypes
+const RegistryTypeLabel = "mintmaker.appstudio.redhat.com/registry-type"
+
+const (
+	// RegistryHostKey is the secret key holding the registry host or URL
+	RegistryHostKey = "host"
+	// RegistryTokenKey is the secret key holding a token, used instead of
+	// username and password
+	RegistryTokenKey = "token"
+)
+
+// RegistryHostTypes maps the values of RegistryTypeLabel to Renovate host types
+var RegistryHostTypes = map[string]string{
+	"npm":   "npm",
+	"pypi":  "pypi",
+	"maven": "maven",
+	"go":    "go-proxy",
+}
+
+// RegistryHostRules returns host rules for the package registry secrets in
+// namespace, in secret name order. Secrets with an unknown registry type or
+// without a host or credentials are skipped.
+func RegistryHostRules(ctx context.Context, k8sClient client.Client, namespace string) ([]HostRule, error) {
+	log := logger.FromContext(ctx)
+
+	secrets := &corev1.SecretList{}
+	if err := k8sClient.List(ctx, secrets, client.InNamespace(namespace), client.HasLabels{RegistryTypeLabel}); err != nil {
+		return nil, fmt.Errorf("failed to list registry secrets in namespace %s: %w", namespace, err)
+	}
+	sort.Slice(secrets.Items, func(i, j int) bool { return secrets.Items[i].Name < secrets.Items[j].Name })
+
+	var hostRules []HostRule
+	for _, secret := range secrets.Items {
+		hostRule, err := registryHostRule(&secret)
+		if err != nil {
+			log.Error(err, "skipping invalid registry secret", "secret", secret.Name, "namespace", namespace)
+			continue
+		}
+		hostRules = append(hostRules, hostRule)
+	}
+	return hostRules, nil
+}
+
+func registryHostRule(secret *corev1.Secret) (HostRule, error) {
+	if secret.Type != corev1.SecretTypeOpaque && secret.Type != corev1.SecretTypeBasicAuth {
+		return HostRule{}, fmt.Errorf("unsupported secret type %s", secret.Type)
+	}
+	registryType := secret.Labels[RegistryTypeLabel]
+	hostType, ok := RegistryHostTypes[registryType]
+	if !ok {
+		return HostRule{}, fmt.Errorf("unknown registry type %q", registryType)
+	}
+	hostRule := HostRule{
+		MatchHost: string(secret.Data[RegistryHostKey]),
+		HostType:  hostType,
+		Username:  string(secret.Data[corev1.BasicAuthUsernameKey]),
+		Password:  string(secret.Data[corev1.BasicAuthPasswordKey]),
+		Token:     string(secret.Data[RegistryTokenKey]),
+	}
+	if hostRule.MatchHost == "" {
+		return HostRule{}, fmt.Errorf("%s is missing", RegistryHostKey)
+	}
+	if hostRule.Token == "" && hostRule.Password == "" {
+		return HostRule{}, fmt.Errorf("either %s or %s is required", RegistryTokenKey, corev1.BasicAuthPasswordKey)
+	}
+	return hostRule, nil
+}
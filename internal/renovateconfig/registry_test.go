@@ -0,0 +1,63 @@
+package renovateconfig
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func registrySecret(namespace, name, registryType string, secretType corev1.SecretType, data map[string]string) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Type:       secretType,
+		Data:       map[string][]byte{},
+	}
+	if registryType != "" {
+		secret.Labels = map[string]string{RegistryTypeLabel: registryType}
+	}
+	for key, value := range data {
+		secret.Data[key] = []byte(value)
+	}
+	return secret
+}
+
+func TestRegistryHostRules(t *testing.T) {
+	ctx := context.Background()
+	objects := []client.Object{
+		registrySecret("tenant", "pypi", "pypi", corev1.SecretTypeBasicAuth,
+			map[string]string{"host": "https://pypi.example.com/simple", "username": "user", "password": "secret"}),
+		registrySecret("tenant", "npm", "npm", corev1.SecretTypeOpaque,
+			map[string]string{"host": "npm.example.com", "token": "npm-token"}),
+		registrySecret("tenant", "go", "go", corev1.SecretTypeOpaque,
+			map[string]string{"host": "goproxy.example.com", "username": "user", "password": "secret"}),
+		registrySecret("tenant", "maven", "maven", corev1.SecretTypeOpaque,
+			map[string]string{"host": "maven.example.com", "username": "user", "password": "secret"}),
+		registrySecret("tenant", "unknown-type", "cargo", corev1.SecretTypeOpaque,
+			map[string]string{"host": "crates.example.com", "token": "token"}),
+		registrySecret("tenant", "no-host", "npm", corev1.SecretTypeOpaque, map[string]string{"token": "token"}),
+		registrySecret("tenant", "no-credentials", "npm", corev1.SecretTypeOpaque, map[string]string{"host": "npm.example.com"}),
+		registrySecret("tenant", "docker", "npm", corev1.SecretTypeDockerConfigJson, map[string]string{"host": "npm.example.com", "token": "token"}),
+		registrySecret("tenant", "unlabelled", "", corev1.SecretTypeOpaque, map[string]string{"host": "npm.example.com", "token": "token"}),
+		registrySecret("other", "other-tenant", "npm", corev1.SecretTypeOpaque, map[string]string{"host": "npm.example.com", "token": "token"}),
+	}
+	k8sClient := fake.NewClientBuilder().WithObjects(objects...).Build()
+
+	hostRules, err := RegistryHostRules(ctx, k8sClient, "tenant")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []HostRule{
+		{MatchHost: "goproxy.example.com", HostType: "go-proxy", Username: "user", Password: "secret"},
+		{MatchHost: "maven.example.com", HostType: "maven", Username: "user", Password: "secret"},
+		{MatchHost: "npm.example.com", HostType: "npm", Token: "npm-token"},
+		{MatchHost: "https://pypi.example.com/simple", HostType: "pypi", Username: "user", Password: "secret"},
+	}
+	if !reflect.DeepEqual(hostRules, expected) {
+		t.Errorf("expected host rules %+v, got %+v", expected, hostRules)
+	}
+}
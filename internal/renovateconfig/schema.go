@@ -0,0 +1,126 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renovateconfig
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/titanous/json5"
+)
+
+// schemaURL is the $id of the embedded schema, configs referencing it via
+// $schema are validated offline
+const schemaURL = "https://docs.renovatebot.com/renovate-schema.json"
+
+// renovateSchema is the Renovate schema, vendored from the renovate npm
+// package at RENOVATE_SCHEMA_VERSION with `make renovate-schema`. Until the
+// upstream file is vendored, it is a subset covering the options MintMaker and
+// its presets use, see docs/contributing.md for what it leaves unvalidated.
+// Unknown options are allowed, the Renovate fork supports managers and
+// options upstream doesn't know about.
+//
+//go:embed schema/renovate-schema.json
+var renovateSchema []byte
+
+var compiledSchema = func() *jsonschema.Schema {
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft7
+	if err := compiler.AddResource(schemaURL, bytes.NewReader(renovateSchema)); err != nil {
+		panic(err)
+	}
+	return compiler.MustCompile(schemaURL)
+}()
+
+// SchemaError is a config value violating the Renovate schema
+type SchemaError struct {
+	// Path is the JSON pointer to the invalid value
+	Path    string
+	Message string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("invalid Renovate config at %s: %s", e.Path, e.Message)
+}
+
+// AsSchemaError returns the SchemaError wrapped in err, if any
+func AsSchemaError(err error) (*SchemaError, bool) {
+	var schemaErr *SchemaError
+	ok := errors.As(err, &schemaErr)
+	return schemaErr, ok
+}
+
+// Validate checks the config against the embedded Renovate schema
+func Validate(config *Config) error {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("error marshaling Renovate config: %w", err)
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("error unmarshaling Renovate config: %w", err)
+	}
+	return validateValue(value)
+}
+
+// ValidatePreset checks a JSON5 preset file against the embedded Renovate schema
+func ValidatePreset(data []byte) error {
+	var value any
+	if err := json5.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("error unmarshaling Renovate preset: %w", err)
+	}
+	return validateValue(value)
+}
+
+func validateValue(value any) error {
+	err := compiledSchema.Validate(value)
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+	leaf := deepestCause(validationErr)
+	path := leaf.InstanceLocation
+	if path == "" {
+		path = "/"
+	}
+	return &SchemaError{Path: path, Message: leaf.Message}
+}
+
+// deepestCause returns the leaf cause pointing furthest into the config, which
+// is the most specific one when a value fails several alternatives
+func deepestCause(err *jsonschema.ValidationError) *jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return err
+	}
+	var deepest *jsonschema.ValidationError
+	for _, cause := range err.Causes {
+		if leaf := deepestCause(cause); deepest == nil || len(leaf.InstanceLocation) > len(deepest.InstanceLocation) {
+			deepest = leaf
+		}
+	}
+	return deepest
+}
+
+// ValidatedJSON validates the config and returns it as indented JSON
+func (c *Config) ValidatedJSON() (string, error) {
+	if err := Validate(c); err != nil {
+		return "", err
+	}
+	return c.JSON()
+}
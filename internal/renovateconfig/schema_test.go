@@ -0,0 +1,115 @@
+package renovateconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateDeployedConfig(t *testing.T) {
+	data := map[string]string{}
+	for _, key := range []string{RenovateConfigKey, SelfHostedConfigKey} {
+		content, err := os.ReadFile(filepath.Join("..", "..", "config", "renovate", key))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data[key] = string(content)
+	}
+	config, err := ParseBaseConfig(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	platform := NewBuilder(config).With(LayerPlatform, &Config{Platform: "github", Username: new(string)}).
+		With(LayerRun, &Config{Repositories: []Repository{{Repository: "org/repo", BaseBranchPatterns: []string{"main"}}}}).Build()
+	if err := Validate(platform); err != nil {
+		t.Errorf("expected the deployed config to be valid, got %v", err)
+	}
+}
+
+func TestValidatePresets(t *testing.T) {
+	presets, err := filepath.Glob(filepath.Join("..", "..", "config", "renovate", "presets", "*.json*"))
+	if err != nil || len(presets) == 0 {
+		t.Fatalf("expected presets, got %v %v", presets, err)
+	}
+	for _, preset := range presets {
+		data, err := os.ReadFile(preset)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := ValidatePreset(data); err != nil {
+			t.Errorf("expected preset %s to be valid, got %v", preset, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       *Config
+		expectedPath string
+	}{
+		{
+			name:   "valid config with unknown options",
+			config: &Config{Platform: "gitlab", Options: map[string]any{"rpmVulnerabilityAlerts": true, "schedule": "before 5am"}},
+		},
+		{
+			name:         "unknown platform",
+			config:       &Config{Platform: "svn"},
+			expectedPath: "/platform",
+		},
+		{
+			name:         "wrong type",
+			config:       &Config{Options: map[string]any{"automerge": "yes"}},
+			expectedPath: "/automerge",
+		},
+		{
+			name: "invalid nested package rule",
+			config: &Config{Options: map[string]any{"tekton": map[string]any{
+				"packageRules": []any{map[string]any{"enabled": true}, map[string]any{"rebaseWhen": "sometimes"}},
+			}}},
+			expectedPath: "/tekton/packageRules/1/rebaseWhen",
+		},
+		{
+			name:         "custom manager without type",
+			config:       &Config{Options: map[string]any{"customManagers": []any{map[string]any{"matchStrings": []any{"x"}}}}},
+			expectedPath: "/customManagers/0",
+		},
+		{
+			name:         "invalid schedule",
+			config:       &Config{Options: map[string]any{"schedule": []any{"before 5am", 5}}},
+			expectedPath: "/schedule/1",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate(tc.config)
+			if tc.expectedPath == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			schemaErr, ok := AsSchemaError(err)
+			if !ok {
+				t.Fatalf("expected a schema error, got %v", err)
+			}
+			if schemaErr.Path != tc.expectedPath {
+				t.Errorf("expected path %s, got %s (%s)", tc.expectedPath, schemaErr.Path, schemaErr.Message)
+			}
+		})
+	}
+}
+
+func TestValidatePresetSyntax(t *testing.T) {
+	if err := ValidatePreset([]byte(`// comment
+	{packageRules: [{matchPackageNames: ["foo"], enabled: false,},],}`)); err != nil {
+		t.Errorf("expected JSON5 preset to be valid, got %v", err)
+	}
+	if err := ValidatePreset([]byte(`{packageRules: [`)); err == nil {
+		t.Error("expected an error for an unparsable preset")
+	}
+	if _, ok := AsSchemaError(ValidatePreset([]byte(`{"packageRules": {}}`))); !ok {
+		t.Error("expected a schema error for an invalid preset")
+	}
+}
@@ -0,0 +1,357 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package renovatelog summarizes the JSON log Renovate writes to LOG_FILE into
+// a run report.
+package renovatelog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Bunyan log levels used by Renovate
+const (
+	levelWarn  = 40
+	levelError = 50
+)
+
+const (
+	// maxMessages is how many distinct warnings and errors a report lists
+	maxMessages = 10
+	// maxMessageLength is where listed messages are cut
+	maxMessageLength = 200
+)
+
+// Messages Renovate logs when it changes branches and pull requests
+const (
+	msgBranchCreated = "Branch created"
+	msgBranchUpdated = "Branch updated"
+	msgBranchDeleted = "Deleting orphan branch"
+	msgPRCreated     = "PR created"
+	msgPRUpdated     = "PR updated"
+	msgPRClosed      = "Autoclosing PR"
+	msgBranchesInfo  = "branches info extended"
+)
+
+// statusCodes matches the HTTP status codes as they appear in error messages,
+// e.g. "Response code 503", "statusCode: 429" or "returned error: 502", so
+// that numbers in versions, digests or issue references are not mistaken for
+// them
+func statusCodes(codes string) *regexp.Regexp {
+	return regexp.MustCompile(`\b(?:status ?code|status|code|error|http(?:/[\d.]+)?)[:= ]+(?:` + codes + `)\b`)
+}
+
+// Error categories, matched in order against the message of the error and of
+// the log entry, and against the status code of the error when it has one
+var errorCategories = []struct {
+	category    string
+	statusCodes []int
+	patterns    []*regexp.Regexp
+}{
+	{"rate-limit", []int{429}, []*regexp.Regexp{
+		regexp.MustCompile(`\brate limit`),
+		statusCodes(`429`),
+	}},
+	{"authentication", []int{401, 403}, []*regexp.Regexp{
+		regexp.MustCompile(`\b(?:authentication|bad credentials|unauthorized|forbidden)\b`),
+		statusCodes(`40[13]`),
+	}},
+	{CategoryNetwork, []int{500, 502, 503, 504}, []*regexp.Regexp{
+		regexp.MustCompile(`\b(?:econnreset|etimedout|esockettimedout|enotfound|eai_again|econnrefused|socket hang up|timed out|timeout awaiting|timeouterror)\b`),
+		// Server errors of registries and git hosts, and failed git fetches
+		regexp.MustCompile(`\b(?:external-host-error|internal server error|bad gateway|service unavailable|gateway timeout)\b`),
+		statusCodes(`50[0234]`),
+		regexp.MustCompile(`\b(?:could not resolve host|failed to connect to|early eof|remote end hung up)\b`),
+	}},
+	{"config", nil, []*regexp.Regexp{
+		regexp.MustCompile(`\b(?:config-validation|config validation|invalid configuration|config error)\b`),
+	}},
+	{"lookup", nil, []*regexp.Regexp{
+		regexp.MustCompile(`\b(?:failed to look up|lookup failure)\b`),
+	}},
+}
+
+const (
+	// CategoryNetwork is the category of network errors and server errors of
+	// remote hosts, which may not happen again
+	CategoryNetwork = "network"
+	// CategoryOther is the category of errors matching no other category
+	CategoryOther = "other"
+)
+
+// Report summarizes a Renovate run
+type Report struct {
+	Repositories []string `json:"repositories,omitempty"`
+	Branches     Changes  `json:"branches"`
+	PullRequests Changes  `json:"pullRequests"`
+	Updates      Updates  `json:"updates"`
+	WarningCount int      `json:"warningCount"`
+	ErrorCount   int      `json:"errorCount"`
+	// Warnings and Errors list the most frequent messages
+	Warnings []Message `json:"warnings,omitempty"`
+	Errors   []Message `json:"errors,omitempty"`
+	// Truncated is set when lists were dropped to fit a size limit
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Changes counts branches or pull requests changed by Renovate
+type Changes struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Closed  int `json:"closed"`
+}
+
+// Updates counts the dependencies with an update branch
+type Updates struct {
+	Total        int            `json:"total"`
+	ByManager    map[string]int `json:"byManager,omitempty"`
+	ByDatasource map[string]int `json:"byDatasource,omitempty"`
+}
+
+// Message is a distinct warning or error message and how often it was logged
+type Message struct {
+	Category string `json:"category,omitempty"`
+	Message  string `json:"message"`
+	Count    int    `json:"count"`
+}
+
+// entry holds the fields of a Renovate log entry the report is built from
+type entry struct {
+	Level      int    `json:"level"`
+	Msg        string `json:"msg"`
+	Repository string `json:"repository"`
+	Err        *struct {
+		Message    string `json:"message"`
+		StatusCode int    `json:"statusCode"`
+	} `json:"err"`
+	BranchesInformation []struct {
+		Upgrades []struct {
+			Manager    string `json:"manager"`
+			Datasource string `json:"datasource"`
+			DepName    string `json:"depName"`
+		} `json:"upgrades"`
+	} `json:"branchesInformation"`
+}
+
+// Parse reads a Renovate JSON log, one entry per line, and summarizes it.
+// Lines that are not JSON are skipped. Only the message of log entries is
+// listed, never error details, which may contain URLs with credentials.
+func Parse(r io.Reader) (*Report, error) {
+	report := &Report{}
+	repositories := map[string]bool{}
+	dependencies := map[string]bool{}
+	warnings := map[Message]int{}
+	errs := map[Message]int{}
+
+	reader := bufio.NewReader(r)
+	for {
+		// Entries listing branches can be megabytes long, so lines are not
+		// read with a bufio.Scanner
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var e entry
+			if json.Unmarshal(line, &e) == nil {
+				report.add(&e, repositories, dependencies, warnings, errs)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for repository := range repositories {
+		report.Repositories = append(report.Repositories, repository)
+	}
+	sort.Strings(report.Repositories)
+	report.Warnings = topMessages(warnings)
+	report.Errors = topMessages(errs)
+	return report, nil
+}
+
+func (r *Report) add(e *entry, repositories, dependencies map[string]bool, warnings, errs map[Message]int) {
+	if e.Repository != "" {
+		repositories[e.Repository] = true
+	}
+
+	switch e.Msg {
+	case msgBranchCreated:
+		r.Branches.Created++
+	case msgBranchUpdated:
+		r.Branches.Updated++
+	case msgBranchDeleted:
+		r.Branches.Closed++
+	case msgPRCreated:
+		r.PullRequests.Created++
+	case msgPRUpdated:
+		r.PullRequests.Updated++
+	case msgPRClosed:
+		r.PullRequests.Closed++
+	case msgBranchesInfo:
+		for _, branch := range e.BranchesInformation {
+			for _, upgrade := range branch.Upgrades {
+				if upgrade.DepName == "" {
+					continue
+				}
+				key := upgrade.Manager + "/" + upgrade.Datasource + "/" + upgrade.DepName
+				if dependencies[key] {
+					continue
+				}
+				dependencies[key] = true
+				r.Updates.add(upgrade.Manager, upgrade.Datasource)
+			}
+		}
+	}
+
+	switch {
+	case e.Level >= levelError:
+		r.ErrorCount++
+		errs[Message{Category: categorize(e), Message: truncate(e.Msg)}]++
+	case e.Level >= levelWarn:
+		r.WarningCount++
+		warnings[Message{Message: truncate(e.Msg)}]++
+	}
+}
+
+func (u *Updates) add(manager, datasource string) {
+	u.Total++
+	if manager != "" {
+		if u.ByManager == nil {
+			u.ByManager = map[string]int{}
+		}
+		u.ByManager[manager]++
+	}
+	if datasource != "" {
+		if u.ByDatasource == nil {
+			u.ByDatasource = map[string]int{}
+		}
+		u.ByDatasource[datasource]++
+	}
+}
+
+// categorize returns the category of an error entry
+func categorize(e *entry) string {
+	text := strings.ToLower(e.Msg)
+	statusCode := 0
+	if e.Err != nil {
+		text += " " + strings.ToLower(e.Err.Message)
+		statusCode = e.Err.StatusCode
+	}
+	for _, c := range errorCategories {
+		if slices.Contains(c.statusCodes, statusCode) {
+			return c.category
+		}
+		for _, pattern := range c.patterns {
+			if pattern.MatchString(text) {
+				return c.category
+			}
+		}
+	}
+	return CategoryOther
+}
+
+func truncate(message string) string {
+	if len(message) <= maxMessageLength {
+		return message
+	}
+	// Cut at a rune boundary
+	cut := maxMessageLength
+	for cut > 0 && !utf8.RuneStart(message[cut]) {
+		cut--
+	}
+	return message[:cut] + "..."
+}
+
+// topMessages returns the most frequent messages, most frequent first
+func topMessages(counts map[Message]int) []Message {
+	messages := make([]Message, 0, len(counts))
+	for message, count := range counts {
+		message.Count = count
+		messages = append(messages, message)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		if messages[i].Count != messages[j].Count {
+			return messages[i].Count > messages[j].Count
+		}
+		if messages[i].Category != messages[j].Category {
+			return messages[i].Category < messages[j].Category
+		}
+		return messages[i].Message < messages[j].Message
+	})
+	if len(messages) > maxMessages {
+		messages = messages[:maxMessages]
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	return messages
+}
+
+// Marshal encodes the report as JSON of at most maxBytes, as Tekton limits
+// the size of task results. Warnings, repositories, errors and the updates by
+// datasource and manager are dropped, in that order, until it fits; the counts
+// are always kept.
+func (r *Report) Marshal(maxBytes int) ([]byte, error) {
+	report := *r
+	data, err := json.Marshal(&report)
+	if err != nil {
+		return nil, err
+	}
+	drops := []func(*Report) bool{
+		func(r *Report) bool { return dropLast(&r.Warnings) },
+		func(r *Report) bool { return dropLast(&r.Repositories) },
+		func(r *Report) bool { return dropLast(&r.Errors) },
+		func(r *Report) bool {
+			dropped := r.Updates.ByDatasource != nil
+			r.Updates.ByDatasource = nil
+			return dropped
+		},
+		func(r *Report) bool {
+			dropped := r.Updates.ByManager != nil
+			r.Updates.ByManager = nil
+			return dropped
+		},
+	}
+	for _, drop := range drops {
+		for len(data) > maxBytes && drop(&report) {
+			report.Truncated = true
+			if data, err = json.Marshal(&report); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return data, nil
+}
+
+func dropLast[T any](items *[]T) bool {
+	if len(*items) == 0 {
+		return false
+	}
+	*items = (*items)[:len(*items)-1]
+	if len(*items) == 0 {
+		*items = nil
+	}
+	return true
+}
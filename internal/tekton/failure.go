@@ -0,0 +1,192 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tekton
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/konflux-ci/mintmaker/internal/renovatelog"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+)
+
+// FailureReason is why a PipelineRun failed. Only transient failures are
+// retried. It is used as a label value.
+type FailureReason string
+
+const (
+	// FailureOOMKilled is a step, sidecar or init container killed for
+	// running out of memory
+	FailureOOMKilled FailureReason = "OOMKilled"
+	// FailureEvicted is a TaskRun pod evicted by the kubelet
+	FailureEvicted FailureReason = "Evicted"
+	// FailureImagePull is a step image that could not be pulled
+	FailureImagePull FailureReason = "ImagePullFailed"
+	// FailurePodCreation is a TaskRun pod that could not be created, e.g.
+	// while the namespace quota was exhausted
+	FailurePodCreation FailureReason = "PodCreationFailed"
+	// FailureRenovate is Renovate exiting with a non-zero code, it is not
+	// retried
+	FailureRenovate FailureReason = "RenovateFailed"
+	// FailureNetwork is Renovate exiting with a non-zero code after logging
+	// only network errors, e.g. server errors of a registry or a failed git
+	// fetch
+	FailureNetwork FailureReason = "NetworkError"
+
+	// RenovateExitCodeResult is the task result the renovate step writes the
+	// exit code of Renovate to, also exposed as a PipelineRun result
+	RenovateExitCodeResult = "renovate-exit-code"
+)
+
+// runRenovateScript runs Renovate without failing the step, so the tasks
+// reading its log still run. The exit code is written to the
+// RenovateExitCodeResult task result instead. Tekton runs scripts without a
+// shebang with set -e, the || keeps a failing Renovate from ending the script.
+const runRenovateScript = `LOG_FILE=/workspace/shared-data/renovate-logs.json renovate || rc=$?
+printf '%s' "${rc:-0}" > "$(results.` + RenovateExitCodeResult + `.path)"
+`
+
+// renovateStatusScript fails the renovate-status task when Renovate failed
+const renovateStatusScript = `if [ "$RENOVATE_EXIT_CODE" != "0" ]; then
+  echo "Renovate failed with exit code $RENOVATE_EXIT_CODE"
+  exit 1
+fi
+echo 'Renovate succeeded'
+`
+
+// RenovateExitCode returns the exit code of Renovate reported by the
+// PipelineRun. It returns false when the run did not report one, e.g. when it
+// failed before Renovate ran.
+func RenovateExitCode(pipelineRun *tektonv1.PipelineRun) (int, bool) {
+	for _, result := range pipelineRun.Status.Results {
+		if result.Name != RenovateExitCodeResult {
+			continue
+		}
+		exitCode, err := strconv.Atoi(result.Value.StringVal)
+		return exitCode, err == nil
+	}
+	return 0, false
+}
+
+// FinishedReason returns the reason of a finished PipelineRun: FailureRenovate
+// when it failed because Renovate did, otherwise the reason of its condition
+func FinishedReason(pipelineRun *tektonv1.PipelineRun) string {
+	condition := pipelineRun.Status.GetCondition(apis.ConditionSucceeded)
+	if condition.IsFalse() {
+		if exitCode, ok := RenovateExitCode(pipelineRun); ok && exitCode != 0 {
+			return string(FailureRenovate)
+		}
+	}
+	return condition.GetReason()
+}
+
+// ClassifyFailure returns why the PipelineRun failed when the failure is
+// transient, from the status of its TaskRuns and their pods, or from its run
+// report when Renovate failed. It returns an empty reason for successful,
+// cancelled, timed out and other failed runs.
+func ClassifyFailure(pipelineRun *tektonv1.PipelineRun, taskRuns []tektonv1.TaskRun, pods []corev1.Pod) FailureReason {
+	condition := pipelineRun.Status.GetCondition(apis.ConditionSucceeded)
+	if condition == nil || !condition.IsFalse() || pipelineRun.IsCancelled() ||
+		condition.Reason == tektonv1.PipelineRunReasonCancelled.String() ||
+		condition.Reason == tektonv1.PipelineRunReasonTimedOut.String() {
+		return ""
+	}
+
+	for _, taskRun := range taskRuns {
+		taskCondition := taskRun.Status.GetCondition(apis.ConditionSucceeded)
+		if taskCondition == nil || !taskCondition.IsFalse() {
+			continue
+		}
+		switch tektonv1.TaskRunReason(taskCondition.Reason) {
+		case tektonv1.TaskRunReasonStepOOM, tektonv1.TaskRunReasonSidecarOOM, tektonv1.TaskRunReasonInitContainerOOM:
+			return FailureOOMKilled
+		case tektonv1.TaskRunReasonPodEvicted:
+			return FailureEvicted
+		case tektonv1.TaskRunReasonImagePullFailed:
+			return FailureImagePull
+		case tektonv1.TaskRunReasonPodCreationFailed:
+			return FailurePodCreation
+		}
+		for _, step := range taskRun.Status.Steps {
+			if step.Terminated != nil && step.Terminated.Reason == "OOMKilled" {
+				return FailureOOMKilled
+			}
+		}
+	}
+
+	// Older Tekton releases only report these in the pod status
+	for _, pod := range pods {
+		if pod.Status.Reason == "Evicted" {
+			return FailureEvicted
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Terminated != nil && status.State.Terminated.Reason == "OOMKilled" {
+				return FailureOOMKilled
+			}
+			if status.State.Waiting != nil && (status.State.Waiting.Reason == "ErrImagePull" ||
+				strings.HasSuffix(status.State.Waiting.Reason, "ImagePullBackOff")) {
+				return FailureImagePull
+			}
+		}
+	}
+
+	if exitCode, ok := RenovateExitCode(pipelineRun); ok && exitCode != 0 && onlyNetworkErrors(pipelineRun) {
+		return FailureNetwork
+	}
+	return ""
+}
+
+// onlyNetworkErrors returns whether the run report of the PipelineRun lists
+// errors and all of them are network errors
+func onlyNetworkErrors(pipelineRun *tektonv1.PipelineRun) bool {
+	value, ok := RunReport(pipelineRun)
+	if !ok {
+		return false
+	}
+	var report renovatelog.Report
+	if err := json.Unmarshal([]byte(value), &report); err != nil || len(report.Errors) == 0 {
+		return false
+	}
+	listed := 0
+	for _, message := range report.Errors {
+		if message.Category != renovatelog.CategoryNetwork {
+			return false
+		}
+		listed += message.Count
+	}
+	// Errors left out of the report may be of any category
+	return listed == report.ErrorCount
+}
+
+// NextSizeProfile returns the profile following the one recorded on the
+// PipelineRun, for retrying a run that ran out of memory. It returns false
+// when the run already used the largest profile.
+func NextSizeProfile(pipelineRun *tektonv1.PipelineRun) (SizeProfile, bool) {
+	current := pipelineRun.Annotations[SizeProfileAnnotation]
+	if current == "" {
+		// Runs without a profile use the template resources, the built-in
+		// ones match the medium profile
+		current = "medium"
+	}
+	for i, profile := range SizeProfiles {
+		if profile.Name == current && i+1 < len(SizeProfiles) {
+			return SizeProfiles[i+1], true
+		}
+	}
+	return SizeProfile{}, false
+}
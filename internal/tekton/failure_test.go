@@ -0,0 +1,216 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tekton
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/konflux-ci/mintmaker/internal/constant"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func failedPipelineRun(reason tektonv1.PipelineRunReason) *tektonv1.PipelineRun {
+	pipelineRun := &tektonv1.PipelineRun{}
+	pipelineRun.Status.MarkFailed(reason.String(), "failed")
+	return pipelineRun
+}
+
+func failedTaskRun(reason tektonv1.TaskRunReason) tektonv1.TaskRun {
+	taskRun := tektonv1.TaskRun{}
+	taskRun.Status.MarkResourceFailed(reason, errors.New("failed"))
+	return taskRun
+}
+
+var _ = Describe("PipelineRun failures", func() {
+
+	When("ClassifyFailure is called", func() {
+		DescribeTable("should classify failures from TaskRun status",
+			func(taskRunReason tektonv1.TaskRunReason, expected FailureReason) {
+				pipelineRun := failedPipelineRun(tektonv1.PipelineRunReasonFailed)
+				Expect(ClassifyFailure(pipelineRun, []tektonv1.TaskRun{failedTaskRun(taskRunReason)}, nil)).To(Equal(expected))
+			},
+			Entry("OOM killed step", tektonv1.TaskRunReasonStepOOM, FailureOOMKilled),
+			Entry("OOM killed sidecar", tektonv1.TaskRunReasonSidecarOOM, FailureOOMKilled),
+			Entry("evicted pod", tektonv1.TaskRunReasonPodEvicted, FailureEvicted),
+			Entry("image pull", tektonv1.TaskRunReasonImagePullFailed, FailureImagePull),
+			Entry("pod creation", tektonv1.TaskRunReasonPodCreationFailed, FailurePodCreation),
+			Entry("failed step", tektonv1.TaskRunReasonFailed, FailureReason("")),
+			Entry("timeout", tektonv1.TaskRunReasonTimedOut, FailureReason("")),
+		)
+
+		It("should classify OOM killed steps of a failed TaskRun", func() {
+			taskRun := failedTaskRun(tektonv1.TaskRunReasonFailed)
+			taskRun.Status.Steps = []tektonv1.StepState{
+				{Name: "renovate", ContainerState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"}}},
+			}
+			pipelineRun := failedPipelineRun(tektonv1.PipelineRunReasonFailed)
+			Expect(ClassifyFailure(pipelineRun, []tektonv1.TaskRun{taskRun}, nil)).To(Equal(FailureOOMKilled))
+		})
+
+		DescribeTable("should classify failures from pod status",
+			func(pod corev1.Pod, expected FailureReason) {
+				pipelineRun := failedPipelineRun(tektonv1.PipelineRunReasonFailed)
+				Expect(ClassifyFailure(pipelineRun, nil, []corev1.Pod{pod})).To(Equal(expected))
+			},
+			Entry("evicted pod", corev1.Pod{Status: corev1.PodStatus{Reason: "Evicted"}}, FailureEvicted),
+			Entry("OOM killed container", corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "step-renovate", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"}}},
+			}}}, FailureOOMKilled),
+			Entry("image pull back-off", corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "step-renovate", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+			}}}, FailureImagePull),
+			Entry("failed container", corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "step-renovate", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error"}}},
+			}}}, FailureReason("")),
+		)
+
+		It("should not retry cancelled, timed out or successful runs", func() {
+			oom := []tektonv1.TaskRun{failedTaskRun(tektonv1.TaskRunReasonStepOOM)}
+			Expect(ClassifyFailure(failedPipelineRun(tektonv1.PipelineRunReasonCancelled), oom, nil)).To(BeEmpty())
+			Expect(ClassifyFailure(failedPipelineRun(tektonv1.PipelineRunReasonTimedOut), oom, nil)).To(BeEmpty())
+
+			succeeded := &tektonv1.PipelineRun{}
+			succeeded.Status.MarkSucceeded(tektonv1.PipelineRunReasonSuccessful.String(), "succeeded")
+			Expect(ClassifyFailure(succeeded, oom, nil)).To(BeEmpty())
+		})
+	})
+
+	When("FinishedReason is called", func() {
+		withExitCode := func(pipelineRun *tektonv1.PipelineRun, exitCode string) *tektonv1.PipelineRun {
+			pipelineRun.Status.Results = []tektonv1.PipelineRunResult{
+				{Name: RenovateExitCodeResult, Value: *tektonv1.NewStructuredValues(exitCode)},
+			}
+			return pipelineRun
+		}
+
+		It("should report runs failed by Renovate", func() {
+			pipelineRun := withExitCode(failedPipelineRun(tektonv1.PipelineRunReasonFailed), "1")
+			Expect(FinishedReason(pipelineRun)).To(Equal(string(FailureRenovate)))
+
+			exitCode, ok := RenovateExitCode(pipelineRun)
+			Expect(ok).To(BeTrue())
+			Expect(exitCode).To(Equal(1))
+		})
+
+		It("should report the condition reason of other runs", func() {
+			Expect(FinishedReason(withExitCode(failedPipelineRun(tektonv1.PipelineRunReasonFailed), "0"))).To(Equal("Failed"))
+			Expect(FinishedReason(failedPipelineRun(tektonv1.PipelineRunReasonCancelled))).To(Equal("Cancelled"))
+
+			succeeded := &tektonv1.PipelineRun{}
+			succeeded.Status.MarkSucceeded(tektonv1.PipelineRunReasonSuccessful.String(), "succeeded")
+			Expect(FinishedReason(withExitCode(succeeded, "0"))).To(Equal("Succeeded"))
+
+			_, ok := RenovateExitCode(&tektonv1.PipelineRun{})
+			Expect(ok).To(BeFalse())
+		})
+
+		It("should not retry runs failed by Renovate", func() {
+			pipelineRun := withExitCode(failedPipelineRun(tektonv1.PipelineRunReasonFailed), "1")
+			Expect(ClassifyFailure(pipelineRun, []tektonv1.TaskRun{failedTaskRun(tektonv1.TaskRunReasonFailed)}, nil)).To(BeEmpty())
+		})
+
+		DescribeTable("should retry runs failed by Renovate with only network errors",
+			func(exitCode, report string, expected FailureReason) {
+				pipelineRun := withExitCode(failedPipelineRun(tektonv1.PipelineRunReasonFailed), exitCode)
+				pipelineRun.Status.Results = append(pipelineRun.Status.Results,
+					tektonv1.PipelineRunResult{Name: ReportResult, Value: *tektonv1.NewStructuredValues(report)})
+				Expect(ClassifyFailure(pipelineRun, []tektonv1.TaskRun{failedTaskRun(tektonv1.TaskRunReasonFailed)}, nil)).To(Equal(expected))
+			},
+			Entry("network errors", "1",
+				`{"errorCount":3,"errors":[{"category":"network","message":"Repository has unknown error","count":2},{"category":"network","message":"Error fetching releases","count":1}]}`,
+				FailureNetwork),
+			Entry("network and config errors", "1",
+				`{"errorCount":2,"errors":[{"category":"network","message":"Error fetching releases","count":1},{"category":"config","message":"Invalid configuration","count":1}]}`,
+				FailureReason("")),
+			Entry("errors left out of the report", "1",
+				`{"errorCount":5,"errors":[{"category":"network","message":"Error fetching releases","count":1}],"truncated":true}`,
+				FailureReason("")),
+			Entry("no errors", "1", `{"errorCount":0}`, FailureReason("")),
+			Entry("Renovate succeeded", "0",
+				`{"errorCount":1,"errors":[{"category":"network","message":"Error fetching releases","count":1}]}`,
+				FailureReason("")),
+		)
+	})
+
+	When("the renovate step runs", func() {
+		DescribeTable("should write the exit code of Renovate without failing",
+			func(stub, expected string) {
+				tmpDir := GinkgoT().TempDir()
+				writeStub(tmpDir, "renovate", stub)
+
+				output, exitCode := runRenovateStep(tmpDir, tmpDir)
+				Expect(exitCode).To(Equal(0), output)
+				Expect(os.ReadFile(filepath.Join(tmpDir, RenovateExitCodeResult))).To(BeEquivalentTo(expected))
+			},
+			Entry("Renovate succeeds", "exit 0", "0"),
+			Entry("Renovate fails", "exit 3", "3"),
+		)
+	})
+
+	When("NextSizeProfile is called", func() {
+		It("should return the next larger profile", func() {
+			pipelineRun := &tektonv1.PipelineRun{}
+			pipelineRun.Annotations = map[string]string{SizeProfileAnnotation: "small"}
+			profile, ok := NextSizeProfile(pipelineRun)
+			Expect(ok).To(BeTrue())
+			Expect(profile.Name).To(Equal("medium"))
+		})
+
+		It("should treat runs without a profile as medium", func() {
+			profile, ok := NextSizeProfile(&tektonv1.PipelineRun{})
+			Expect(ok).To(BeTrue())
+			Expect(profile.Name).To(Equal("large"))
+		})
+
+		It("should not go beyond the largest profile", func() {
+			pipelineRun := &tektonv1.PipelineRun{}
+			pipelineRun.Annotations = map[string]string{SizeProfileAnnotation: "large"}
+			_, ok := NextSizeProfile(pipelineRun)
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	When("NewPipelineRunBuilderFromPipelineRun is called", func() {
+		It("should copy the spec and MintMaker metadata without Tekton's", func() {
+			original, err := NewPipelineRunBuilder("original", "mintmaker").
+				WithLabels(map[string]string{"mintmaker.appstudio.redhat.com/component": "comp", "tekton.dev/pipeline": "original"}).
+				WithAnnotations(map[string]string{PipelineTemplateAnnotation: PipelineTemplateBuiltIn}).
+				WithServiceAccount("mintmaker-controller-manager").
+				Build()
+			Expect(err).NotTo(HaveOccurred())
+			original.Spec.Status = tektonv1.PipelineRunSpecStatusCancelled
+			original.Status.MarkFailed(tektonv1.PipelineRunReasonFailed.String(), "failed")
+			original.Annotations[MintMakerResultsCollectedAnnotation] = "true"
+
+			retry, err := NewPipelineRunBuilderFromPipelineRun("original-retry-1", original).Build()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retry.Name).To(Equal("original-retry-1"))
+			Expect(retry.Namespace).To(Equal("mintmaker"))
+			Expect(retry.Labels).To(Equal(map[string]string{"mintmaker.appstudio.redhat.com/component": "comp"}))
+			Expect(retry.Annotations).To(HaveKeyWithValue(PipelineTemplateAnnotation, PipelineTemplateBuiltIn))
+			Expect(retry.Annotations).NotTo(HaveKey(MintMakerResultsCollectedAnnotation))
+			Expect(retry.Spec.Status).To(BeEmpty())
+			Expect(retry.Spec.TaskRunTemplate.ServiceAccountName).To(Equal("mintmaker-controller-manager"))
+			Expect(retry.Status.Conditions).To(BeEmpty())
+			Expect(retry.Spec.PipelineSpec).To(Equal(original.Spec.PipelineSpec))
+			Expect(retry.Spec.PipelineSpec).NotTo(BeIdenticalTo(original.Spec.PipelineSpec))
+		})
+	})
+})
@@ -0,0 +1,415 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tekton
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed log_scan.sh
+var logScanScript string
+
+const (
+	// LogScanDir is where the leaktk findings are passed from the scan step to
+	// the log-sanitizer step, an in-memory emptyDir
+	LogScanDir = "/var/run/log-sanitizer"
+	// Files in LogScanDir written by log_scan.sh
+	logScanFindingsFile = "findings.json"
+	logScanFailedFile   = "scan-failed"
+	// File in LogScanDir with the number of known secrets RedactKnownSecrets
+	// redacted before the scan
+	knownRedactionsFile = "known-redactions"
+
+	// KnownSecretsDir is where WithKnownSecrets mounts the secrets MintMaker
+	// injected into the run, one per file
+	KnownSecretsDir = "/etc/mintmaker/known-secrets"
+	// KnownFingerprintsFile is where the renovate step records the
+	// fingerprints of the credentials it fetched from the token broker, one
+	// per line. They are never written down, so only their fingerprints are
+	// shared with the sanitize-logs task.
+	KnownFingerprintsFile = "/workspace/shared-data/known-secret-fingerprints"
+
+	// LogRedactionsResult is the number of secrets redacted from the log
+	LogRedactionsResult = "log-redactions"
+
+	// RedactedSecret replaces secrets in the log
+	RedactedSecret = "**REDACTED**"
+	// LogSanitizationFailedMessage replaces the whole log when it could not
+	// be sanitized
+	LogSanitizationFailedMessage = "Sanitization step failed; the entire logs of this execution were removed for caution"
+
+	// Shorter secrets are not redacted, they would mangle the log
+	minSecretLength = 4
+	// Shorter fragments of a secret are not fingerprinted, their fingerprint
+	// could be reversed by brute force
+	minFingerprintedFragment = 16
+)
+
+var (
+	// secretFragmentPattern splits a secret into the fragments that are
+	// fingerprinted, runs of the characters of tokens and base64
+	secretFragmentPattern = regexp.MustCompile(`[A-Za-z0-9+/_-]+`)
+	// logFragmentPattern splits the JSON log the same way, JSON escapes
+	// separate fragments like the characters they stand for
+	logFragmentPattern = regexp.MustCompile(`\\(?:u[0-9a-fA-F]{4}|.)|[A-Za-z0-9+/_-]+`)
+)
+
+// LogSanitizer redacts secrets from a Renovate JSON log
+type LogSanitizer struct {
+	// Longest first, so a secret containing another is redacted whole
+	secrets [][]byte
+}
+
+// NewLogSanitizer returns a LogSanitizer for the given secrets. Both the
+// secrets and their JSON escaped form are redacted, as the log is JSON and a
+// secret with quotes, backslashes or newlines is logged escaped.
+func NewLogSanitizer(secrets []string) *LogSanitizer {
+	seen := map[string]bool{}
+	sanitizer := &LogSanitizer{}
+	add := func(secret string) {
+		if len(secret) < minSecretLength || seen[secret] {
+			return
+		}
+		seen[secret] = true
+		sanitizer.secrets = append(sanitizer.secrets, []byte(secret))
+	}
+	for _, secret := range secrets {
+		add(secret)
+		add(jsonEscape(secret))
+	}
+	sort.SliceStable(sanitizer.secrets, func(i, j int) bool {
+		return len(sanitizer.secrets[i]) > len(sanitizer.secrets[j])
+	})
+	return sanitizer
+}
+
+// jsonEscape returns the secret as it appears inside a JSON string
+func jsonEscape(secret string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(secret); err != nil {
+		return secret
+	}
+	// Strip the quotes and the newline added by Encode
+	escaped := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	return string(escaped[1 : len(escaped)-1])
+}
+
+// Sanitize copies the log from r to w line by line, redacting the secrets,
+// and returns how many were redacted
+func (s *LogSanitizer) Sanitize(r io.Reader, w io.Writer) (int, error) {
+	redactions := 0
+	reader := bufio.NewReader(r)
+	writer := bufio.NewWriter(w)
+	for {
+		// Log entries can be megabytes long, so lines are not read with a
+		// bufio.Scanner
+		line, readErr := reader.ReadBytes('\n')
+		for _, secret := range s.secrets {
+			if count := bytes.Count(line, secret); count > 0 {
+				redactions += count
+				line = bytes.ReplaceAll(line, secret, []byte(RedactedSecret))
+			}
+		}
+		if _, err := writer.Write(line); err != nil {
+			return redactions, err
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return redactions, readErr
+		}
+	}
+	return redactions, writer.Flush()
+}
+
+// SanitizeFile redacts the secrets from the file, replacing it only once it
+// was fully written
+func (s *LogSanitizer) SanitizeFile(path string) (int, error) {
+	in, err := os.Open(path) //nolint:gosec // the log file is given by the step
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = in.Close() }()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temporary log file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	redactions, err := s.Sanitize(in, tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to redact secrets from log file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, fmt.Errorf("failed to replace log file with redacted version: %w", err)
+	}
+	return redactions, nil
+}
+
+// ParseLeakTKFindings returns the secrets of a leaktk scan report. Empty
+// output means nothing was found.
+func ParseLeakTKFindings(data []byte) ([]string, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	var report struct {
+		Results []struct {
+			Secret string `json:"secret"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, errors.New("failed to parse leaktk output")
+	}
+	var secrets []string
+	for _, result := range report.Results {
+		if result.Secret != "" {
+			secrets = append(secrets, result.Secret)
+		}
+	}
+	return secrets, nil
+}
+
+// ReadKnownSecrets reads the secrets MintMaker injected into the run from a
+// mounted Secret, one per key. A missing directory holds no secrets.
+func ReadKnownSecrets(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var secrets []string
+	for _, entry := range entries {
+		// Skip the timestamped directory and ..data link of Secret volumes
+		if strings.HasPrefix(entry.Name(), "..") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name())) //nolint:gosec // the directory is given by the step
+		if err != nil {
+			return nil, err
+		}
+		secret := string(data)
+		secrets = append(secrets, secret)
+		if trimmed := strings.TrimSpace(secret); trimmed != secret {
+			secrets = append(secrets, trimmed)
+		}
+	}
+	return secrets, nil
+}
+
+// SecretFingerprints returns the SHA-256 fingerprints of the fragments of a
+// secret, so it can be redacted from the log by FingerprintedSecrets without
+// being written down. Fragments are runs of token and base64 characters, an
+// SSH key has one per line.
+func SecretFingerprints(secret string) []string {
+	var fingerprints []string
+	for _, fragment := range secretFragmentPattern.FindAllString(secret, -1) {
+		if len(fragment) < minFingerprintedFragment {
+			continue
+		}
+		if fingerprint := fingerprintOf(fragment); !slices.Contains(fingerprints, fingerprint) {
+			fingerprints = append(fingerprints, fingerprint)
+		}
+	}
+	return fingerprints
+}
+
+func fingerprintOf(fragment string) string {
+	sum := sha256.Sum256([]byte(fragment))
+	return hex.EncodeToString(sum[:])
+}
+
+// ReadKnownFingerprints reads the fingerprints recorded by the renovate step,
+// one per line. A missing file holds no fingerprints.
+func ReadKnownFingerprints(file string) (map[string]bool, error) {
+	data, err := os.ReadFile(file) //nolint:gosec // the file is given by the step
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	fingerprints := map[string]bool{}
+	for _, line := range strings.Fields(string(data)) {
+		fingerprints[line] = true
+	}
+	return fingerprints, nil
+}
+
+// FingerprintedSecrets returns the fragments of the log whose fingerprint is
+// one of the given fingerprints
+func FingerprintedSecrets(r io.Reader, fingerprints map[string]bool) ([]string, error) {
+	var secrets []string
+	reader := bufio.NewReader(r)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		for _, fragment := range logFragmentPattern.FindAll(line, -1) {
+			if len(fragment) < minFingerprintedFragment || fragment[0] == '\\' {
+				continue
+			}
+			if secret := string(fragment); fingerprints[fingerprintOf(secret)] && !slices.Contains(secrets, secret) {
+				secrets = append(secrets, secret)
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			return secrets, nil
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+}
+
+// RedactKnownSecrets redacts the secrets in knownSecretsDir and those
+// fingerprinted in fingerprintsFile from the log file before leaktk scans it, so they are redacted even when the scan fails, and
+// records their number in scanDir for SanitizeLog. When that fails, the log is
+// replaced by LogSanitizationFailedMessage. An error is only returned when the
+// log could not be replaced either. Progress is written to out, never any
+// secret.
+func RedactKnownSecrets(logFile, scanDir, knownSecretsDir, fingerprintsFile string, out io.Writer) error {
+	if _, err := os.Stat(logFile); errors.Is(err, fs.ErrNotExist) {
+		_, _ = fmt.Fprintln(out, "Log file not found, skipping redaction")
+		return nil
+	}
+
+	redactions, err := redactKnownSecrets(logFile, knownSecretsDir, fingerprintsFile)
+	if err != nil {
+		_, _ = fmt.Fprintln(out, err)
+		_, _ = fmt.Fprintln(out, LogSanitizationFailedMessage)
+		return os.WriteFile(logFile, []byte(LogSanitizationFailedMessage+"\n"), 0600)
+	}
+	_, _ = fmt.Fprintf(out, "Known secrets redacted, %d secrets redacted\n", redactions)
+	return os.WriteFile(filepath.Join(scanDir, knownRedactionsFile), []byte(strconv.Itoa(redactions)), 0600)
+}
+
+// SanitizeLog redacts the findings of log_scan.sh in scanDir and the known
+// secrets in knownSecretsDir and fingerprintsFile from the log file. When that fails, the log is replaced by
+// LogSanitizationFailedMessage so no secret is left behind, as the script did
+// before. An error is only returned when the log could not be replaced either.
+// Progress is written to out, never any secret.
+func SanitizeLog(logFile, scanDir, knownSecretsDir, fingerprintsFile string, out io.Writer) (int, error) {
+	if _, err := os.Stat(logFile); errors.Is(err, fs.ErrNotExist) {
+		_, _ = fmt.Fprintln(out, "Log file not found, skipping sanitization")
+		return 0, nil
+	}
+
+	redactions, err := sanitizeLog(logFile, scanDir, knownSecretsDir, fingerprintsFile)
+	if err != nil {
+		_, _ = fmt.Fprintln(out, err)
+		_, _ = fmt.Fprintln(out, LogSanitizationFailedMessage)
+		return 0, os.WriteFile(logFile, []byte(LogSanitizationFailedMessage+"\n"), 0600)
+	}
+	_, _ = fmt.Fprintf(out, "Log sanitization complete, %d secrets redacted\n", redactions)
+	return redactions, nil
+}
+
+func sanitizeLog(logFile, scanDir, knownSecretsDir, fingerprintsFile string) (int, error) {
+	// The known secrets are redacted first, whatever leaktk finds and even
+	// when the scan failed
+	redactions, err := redactKnownSecrets(logFile, knownSecretsDir, fingerprintsFile)
+	if err != nil {
+		return 0, err
+	}
+	if data, err := os.ReadFile(filepath.Join(scanDir, knownRedactionsFile)); err == nil { //nolint:gosec // the scan directory is given by the step
+		if known, err := strconv.Atoi(string(data)); err == nil {
+			redactions += known
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(scanDir, logScanFailedFile)); err == nil {
+		return redactions, errors.New("leaktk scan failed")
+	}
+	findings, err := os.ReadFile(filepath.Join(scanDir, logScanFindingsFile)) //nolint:gosec // the scan directory is given by the step
+	if err != nil {
+		return redactions, errors.New("leaktk findings not found")
+	}
+	foundSecrets, err := ParseLeakTKFindings(findings)
+	if err != nil {
+		return redactions, err
+	}
+	if len(foundSecrets) == 0 {
+		return redactions, nil
+	}
+	found, err := NewLogSanitizer(foundSecrets).SanitizeFile(logFile)
+	return redactions + found, err
+}
+
+// redactKnownSecrets redacts the secrets in knownSecretsDir and those
+// fingerprinted in fingerprintsFile from the log file
+func redactKnownSecrets(logFile, knownSecretsDir, fingerprintsFile string) (int, error) {
+	var secrets []string
+	if knownSecretsDir != "" {
+		known, err := ReadKnownSecrets(knownSecretsDir)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read known secrets: %w", err)
+		}
+		secrets = append(secrets, known...)
+	}
+	if fingerprintsFile != "" {
+		fingerprinted, err := fingerprintedSecrets(logFile, fingerprintsFile)
+		if err != nil {
+			return 0, err
+		}
+		secrets = append(secrets, fingerprinted...)
+	}
+	if len(secrets) == 0 {
+		return 0, nil
+	}
+	return NewLogSanitizer(secrets).SanitizeFile(logFile)
+}
+
+func fingerprintedSecrets(logFile, fingerprintsFile string) ([]string, error) {
+	fingerprints, err := ReadKnownFingerprints(fingerprintsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known secret fingerprints: %w", err)
+	}
+	if len(fingerprints) == 0 {
+		return nil, nil
+	}
+	in, err := os.Open(logFile) //nolint:gosec // the log file is given by the step
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = in.Close() }()
+	secrets, err := FingerprintedSecrets(in, fingerprints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to match known secret fingerprints: %w", err)
+	}
+	return secrets, nil
+}
@@ -0,0 +1,17 @@
+#!/bin/sh
+# Scans the Renovate log with leaktk for the log-sanitizer step, which redacts
+# the findings. A failed scan is recorded, so the log is removed instead.
+LOG_FILE="${LOG_FILE:-/workspace/shared-data/renovate-logs.json}"
+SCAN_DIR="${SCAN_DIR:-/var/run/log-sanitizer}"
+if [ ! -f "$LOG_FILE" ]; then
+  echo 'Log file not found, skipping scan'
+  exit 0
+fi
+echo 'Scanning log file for leaked secrets...'
+if ! leaktk scan --kind JSONData "@${LOG_FILE}" >"$SCAN_DIR/findings.json" 2>"$SCAN_DIR/scan-error"; then
+  # The output may contain secrets, only the error is printed
+  echo "leaktk scan failed: $(cat "$SCAN_DIR/scan-error")"
+  rm -f "$SCAN_DIR/findings.json"
+  touch "$SCAN_DIR/scan-failed"
+fi
+exit 0
@@ -15,6 +15,7 @@
 package tekton
 
 import (
+	"context"
 	_ "embed"
 	"encoding/json"
 	"fmt"
@@ -22,23 +23,133 @@ import (
 	"reflect"
 	"slices"
 	"strings"
-	"time"
 	"unicode"
 
 	"github.com/hashicorp/go-multierror"
+	"github.com/konflux-ci/mintmaker/internal/config"
 	mmconst "github.com/konflux-ci/mintmaker/internal/constant"
 	"github.com/konflux-ci/mintmaker/internal/utils"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/pod"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
+	"knative.dev/pkg/apis"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
-//go:embed log_sanitizer.sh
-var logSanitizerScript string
+const (
+	// Where the projected ServiceAccount token for the token broker is mounted
+	tokenBrokerTokenDir = "/var/run/secrets/mintmaker/token-broker"
+	// Where the CA bundle used to verify the token broker is mounted
+	TokenBrokerCADir = "/etc/pki/token-broker-ca"
+	// Where the CA bundle used to verify the preset server is mounted
+	PresetsCADir = "/etc/pki/presets-ca"
+	// Lifetime requested for the projected ServiceAccount token
+	tokenBrokerTokenExpirationSeconds int64 = 3600
+
+	// Storage requested for the shared-data workspace when it is shared
+	// between tasks, it holds the OSV database and the Renovate log
+	sharedDataStorage = "4Gi"
+
+	// The volume of the sanitize-logs task holding LogScanDir
+	logScanVolume = "log-scan"
+	// The volume of the sanitize-logs task holding KnownSecretsDir
+	knownSecretsVolume = "known-secrets"
+
+	// Where the SSH deploy key is mounted in the renovate step
+	sshKeyDir = "/etc/renovate/ssh"
+
+	// The in-memory volume of the build task holding gitConfigDir, the git
+	// config with the refreshed token of WithGitTokenRefresh
+	gitConfigVolume = "git-config"
+	gitConfigDir    = "/var/run/mintmaker/git"
+
+	// The in-memory volume of the build task holding rpmCertsDir, the RPM
+	// entitlement certificate and key are kept off the shared-data workspace
+	rpmCertsVolume = "rpm-certs"
+	rpmCertsDir    = "/var/run/rpm-certs"
+
+	// Where the Renovate config ConfigMap is mounted in the renovate step
+	RenovateConfigDir = "/etc/renovate/config"
+	// RenovateConfigFile is the key and file name of the Renovate config
+	RenovateConfigFile = "config.json"
+	// RenovateConfigFileJS is the legacy key and file name of the Renovate
+	// config as a JavaScript module
+	RenovateConfigFileJS = "config.js"
+)
+
+// renovateTokenBrokerScript fetches a fresh token from the token broker right
+// before Renovate starts, instead of reading it from the per-run Secret. The
+// SSH deploy key, if any, is written to MINTMAKER_SSH_KEY_DIR when set. The
+// fingerprints of both are appended to MINTMAKER_KNOWN_FINGERPRINTS_FILE, so
+// the sanitize-logs task can redact them.
+//
+// Renovate reads the token once at startup. With MINTMAKER_GIT_CONFIG_DIR set,
+// the token is also written to a git config there as an Authorization header
+// for MINTMAKER_GIT_URL, and refreshed in the background ten minutes before
+// it expires, so git keeps working past the lifetime of the first token.
+const renovateTokenBrokerScript = recordPeakMemoryScript + `fetch_credentials() {
+  CA_ARGS=""
+  [ -f "` + TokenBrokerCADir + `/ca.crt" ] && CA_ARGS="--cacert ` + TokenBrokerCADir + `/ca.crt"
+  curl -sSf --retry 3 $CA_ARGS \
+    -H "Authorization: Bearer $(cat "$MINTMAKER_TOKEN_BROKER_TOKEN_FILE")" \
+    "$MINTMAKER_TOKEN_BROKER_URL/token"
+}
+# Writes the credentials where they are used and prints the seconds until
+# they are refreshed, 0 when they don't expire
+store_credentials() {
+  printf '%s' "$CREDENTIALS" | node -e '
+const fs = require("fs");
+const env = process.env;
+const credentials = JSON.parse(fs.readFileSync(0, "utf8"));
+if (env.MINTMAKER_KNOWN_FINGERPRINTS_FILE) {
+  fs.appendFileSync(env.MINTMAKER_KNOWN_FINGERPRINTS_FILE, (credentials.fingerprints || []).map((f) => f + "\n").join(""));
+}
+if (env.MINTMAKER_SSH_KEY_DIR && !fs.existsSync(env.MINTMAKER_SSH_KEY_DIR + "/id")) {
+  fs.writeFileSync(env.MINTMAKER_SSH_KEY_DIR + "/id", credentials.sshPrivateKey || "", { mode: 0o600 });
+  if (credentials.sshKnownHosts) fs.writeFileSync(env.MINTMAKER_SSH_KEY_DIR + "/known_hosts", credentials.sshKnownHosts);
+}
+if (env.MINTMAKER_GIT_CONFIG_DIR) {
+  const auth = Buffer.from(env.MINTMAKER_GIT_USERNAME + ":" + credentials.token).toString("base64");
+  const file = env.MINTMAKER_GIT_CONFIG_DIR + "/gitconfig";
+  fs.writeFileSync(file + ".tmp", "[http \"" + env.MINTMAKER_GIT_URL + "\"]\n\textraHeader = Authorization: Basic " + auth + "\n", { mode: 0o600 });
+  fs.renameSync(file + ".tmp", file);
+}
+const expiresAt = Date.parse(credentials.expiresAt);
+console.log(Number.isNaN(expiresAt) ? 0 : Math.max(60, Math.floor((expiresAt - Date.now()) / 1000) - 600));
+'
+}
+refresh_credentials() {
+  trap 'kill "$SLEEP_PID" 2>/dev/null; exit 0' TERM
+  refresh_in=$1
+  while [ "$refresh_in" -gt 0 ]; do
+    sleep "$refresh_in" &
+    SLEEP_PID=$!
+    wait "$SLEEP_PID"
+    if CREDENTIALS=$(fetch_credentials) && refresh_in=$(store_credentials); then
+      echo 'Refreshed the Git token from the token broker'
+    else
+      echo 'Failed to refresh the Git token from the token broker, retrying in a minute'
+      refresh_in=60
+    fi
+  done
+}
+if ! CREDENTIALS=$(fetch_credentials) || ! REFRESH_IN=$(store_credentials); then
+  echo 'Failed to fetch Renovate token from the token broker'
+  exit 1
+fi
+RENOVATE_TOKEN=$(printf '%s' "$CREDENTIALS" | node -p 'JSON.parse(require("fs").readFileSync(0, "utf8")).token')
+export RENOVATE_TOKEN
+unset CREDENTIALS
+if [ -n "$MINTMAKER_GIT_CONFIG_DIR" ] && [ "$REFRESH_IN" -gt 0 ]; then
+  refresh_credentials "$REFRESH_IN" &
+  REFRESH_PID=$!
+  trap 'kill "$REFRESH_PID" 2>/dev/null || true; record_peak_memory' EXIT
+fi
+` + runRenovateScript
 
 type PipelineRunBuilder struct {
 	err         *multierror.Error
@@ -46,7 +157,7 @@ type PipelineRunBuilder struct {
 }
 
 type MountOptions struct {
-	// Which task to mount to. If empty, defaults to xxx (e.g., "renovate")
+	// Which task to mount to. If empty, defaults to "build"
 	TaskName string
 	// Which steps to mount to. If empty, mounts to all steps
 	StepNames []string
@@ -98,9 +209,9 @@ func (o *MountOptions) WithOptional(optional bool) *MountOptions {
 	return o
 }
 
-// NewPipelineRunBuilder initializes a new PipelineRunBuilder with the given name prefix and namespace.
-// It sets the name of the PipelineRun to be generated with the provided prefix and sets its namespace.
-func NewPipelineRunBuilder(name, namespace string) *PipelineRunBuilder {
+// DefaultPipelineSpec returns the built-in PipelineSpec, used when no
+// PipelineRun template is configured.
+func DefaultPipelineSpec() *tektonv1.PipelineSpec {
 	var rootUser int64 = 0
 	var normalUser int64 = 1001120000
 	renovateImageURL := os.Getenv(mmconst.RenovateImageEnvName)
@@ -111,179 +222,449 @@ func NewPipelineRunBuilder(name, namespace string) *PipelineRunBuilder {
 	if leaktkImageURL == "" {
 		leaktkImageURL = mmconst.DefaultLeakTKImageURL
 	}
-	return &PipelineRunBuilder{
-		pipelineRun: &tektonv1.PipelineRun{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      name,
-				Namespace: namespace,
+	mintmakerImageURL := os.Getenv(mmconst.MintMakerImageEnvName)
+	if mintmakerImageURL == "" {
+		mintmakerImageURL = mmconst.DefaultMintMakerImageURL
+	}
+	return &tektonv1.PipelineSpec{
+		Workspaces: []tektonv1.PipelineWorkspaceDeclaration{
+			{
+				Name: "shared-data",
 			},
-			Spec: tektonv1.PipelineRunSpec{
-				PipelineSpec: &tektonv1.PipelineSpec{
-					Workspaces: []tektonv1.PipelineWorkspaceDeclaration{
-						{
-							Name: "shared-data",
+		},
+		Tasks: []tektonv1.PipelineTask{
+			{
+				Name:       renovateTaskName,
+				Workspaces: sharedDataBinding(),
+				TaskSpec: &tektonv1.EmbeddedTask{
+					TaskSpec: tektonv1.TaskSpec{
+						Workspaces: sharedDataDeclaration(),
+						Results: []tektonv1.TaskResult{
+							{
+								Name:        PeakMemoryResult,
+								Description: "Peak memory of the renovate step in bytes",
+							},
+							{
+								Name:        RenovateExitCodeResult,
+								Description: "Exit code of Renovate",
+							},
+						},
+						Volumes: []corev1.Volume{
+							{
+								Name: rpmCertsVolume,
+								VolumeSource: corev1.VolumeSource{
+									EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory},
+								},
+							},
+						},
+						Steps: []tektonv1.Step{
+							{
+								Name:   "prepare-db",
+								Image:  "quay.io/konflux-ci/mintmaker-osv-database:latest",
+								Script: "echo 'Copying OSV database to the shared workspace'; cp -r /data/osv-db /workspace/shared-data",
+								SecurityContext: &corev1.SecurityContext{
+									Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+									RunAsNonRoot:             ptr.To(true),
+									RunAsUser:                &normalUser,
+									AllowPrivilegeEscalation: ptr.To(false),
+								},
+								ComputeResources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										"cpu":    resource.MustParse("100m"),
+										"memory": resource.MustParse("512Mi"),
+									},
+									Limits: corev1.ResourceList{
+										"cpu":    resource.MustParse("100m"),
+										"memory": resource.MustParse("512Mi"),
+									},
+								},
+							},
+							{
+								Name:  "prepare-rpm-cert",
+								Image: "registry.access.redhat.com/ubi9",
+								Script: "[ ! -f \"/etc/renovate/secret/rpm-activationkey\" ] && echo 'RPM secret not found. Exiting.' && exit 0;" +
+									"echo 'Generating RPM certificate and copying it for the renovate step';" +
+									"KEY_NAME=$(cat /etc/renovate/secret/rpm-activationkey);" +
+									"ORG_ID=$(cat /etc/renovate/secret/rpm-org);" +
+									"subscription-manager register --activationkey=\"$KEY_NAME\" --org=\"$ORG_ID\";" +
+									"cp /etc/pki/entitlement/*-key.pem " + rpmCertsDir + "/key.pem;" +
+									"cp $(find /etc/pki/entitlement -maxdepth 1 -type f -name '*.pem' ! -name '*-key.pem' -print -quit) " + rpmCertsDir + "/cert.pem",
+								VolumeMounts: []corev1.VolumeMount{
+									{
+										Name:      rpmCertsVolume,
+										MountPath: rpmCertsDir,
+									},
+								},
+								SecurityContext: &corev1.SecurityContext{
+									Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+									AllowPrivilegeEscalation: ptr.To(false),
+									RunAsUser:                &rootUser,
+								},
+								ComputeResources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										"cpu":    resource.MustParse("100m"),
+										"memory": resource.MustParse("256Mi"),
+									},
+									Limits: corev1.ResourceList{
+										"cpu":    resource.MustParse("100m"),
+										"memory": resource.MustParse("256Mi"),
+									},
+								},
+							},
+							{
+								Name:  "renovate",
+								Image: renovateImageURL,
+								Script: recordPeakMemoryScript +
+									"RENOVATE_TOKEN=$(cat /etc/renovate/secret/renovate-token) " + runRenovateScript,
+								VolumeMounts: []corev1.VolumeMount{
+									{
+										Name:      rpmCertsVolume,
+										MountPath: rpmCertsDir,
+										ReadOnly:  true,
+									},
+								},
+								SecurityContext: &corev1.SecurityContext{
+									Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+									RunAsNonRoot:             ptr.To(true),
+									RunAsUser:                &normalUser,
+									AllowPrivilegeEscalation: ptr.To(false),
+								},
+								ComputeResources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										"cpu":    resource.MustParse("300m"),
+										"memory": resource.MustParse("3.5Gi"),
+									},
+									Limits: corev1.ResourceList{
+										"cpu":    resource.MustParse("300m"),
+										"memory": resource.MustParse("3.5Gi"),
+									},
+								},
+								Env: []corev1.EnvVar{
+									{
+										Name:  "HOME",
+										Value: "/home/renovate",
+									},
+									{
+										Name:  "RENOVATE_CONFIG_FILE",
+										Value: RenovateConfigDir + "/" + RenovateConfigFile,
+									},
+									{
+										Name:  "LOG_LEVEL",
+										Value: "debug",
+									},
+									{
+										Name:  "LOG_FORMAT",
+										Value: "json",
+									},
+									{
+										Name:  "OSV_OFFLINE_DISABLE_DOWNLOAD",
+										Value: "true",
+									},
+									{
+										Name:  "OSV_OFFLINE_ROOT_DIR",
+										Value: "/workspace/shared-data/osv-db",
+									},
+									{
+										Name:  "DNF_VAR_SSL_CLIENT_KEY",
+										Value: rpmCertsDir + "/key.pem",
+									},
+									{
+										Name:  "DNF_VAR_SSL_CLIENT_CERT",
+										Value: rpmCertsDir + "/cert.pem",
+									},
+									{
+										Name:  "RENOVATE_X_GITLAB_AUTO_MERGEABLE_CHECK_ATTEMPS",
+										Value: "7",
+									},
+								},
+							},
 						},
 					},
-					Tasks: []tektonv1.PipelineTask{
-						{
-							Name: "build",
-							Workspaces: []tektonv1.WorkspacePipelineTaskBinding{
-								{
-									Name:      "shared-data",
-									Workspace: "shared-data",
+				},
+			},
+			{
+				// Separate from the build task, so a failing sanitizer is
+				// visible as the status of its own task
+				Name:       sanitizeLogsTaskName,
+				RunAfter:   []string{renovateTaskName},
+				Workspaces: sharedDataBinding(),
+				TaskSpec: &tektonv1.EmbeddedTask{
+					TaskSpec: tektonv1.TaskSpec{
+						Workspaces: sharedDataDeclaration(),
+						Results: []tektonv1.TaskResult{
+							{
+								Name:        LogRedactionsResult,
+								Description: "Number of secrets redacted from the Renovate log",
+							},
+						},
+						// The leaktk findings are secrets, they are kept in memory
+						Volumes: []corev1.Volume{
+							{
+								Name: logScanVolume,
+								VolumeSource: corev1.VolumeSource{
+									EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory},
+								},
+							},
+						},
+						Steps: []tektonv1.Step{
+							{
+								// Known secrets are redacted before the scan, so
+								// they are gone even when it fails
+								Name:    redactKnownSecretsStepName,
+								Image:   mintmakerImageURL,
+								Command: []string{"/log-sanitizer"},
+								Args: []string{
+									"--log-file=/workspace/shared-data/renovate-logs.json",
+									"--scan-dir=" + LogScanDir,
+									"--known-secrets-only",
+								},
+								VolumeMounts: []corev1.VolumeMount{
+									{
+										Name:      logScanVolume,
+										MountPath: LogScanDir,
+									},
+								},
+								SecurityContext: &corev1.SecurityContext{
+									Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+									RunAsNonRoot:             ptr.To(true),
+									RunAsUser:                &normalUser,
+									AllowPrivilegeEscalation: ptr.To(false),
+								},
+								ComputeResources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										"cpu":    resource.MustParse("100m"),
+										"memory": resource.MustParse("128Mi"),
+									},
+									Limits: corev1.ResourceList{
+										"cpu":    resource.MustParse("100m"),
+										"memory": resource.MustParse("128Mi"),
+									},
+								},
+							},
+							{
+								Name:  "leaktk-scan",
+								Image: leaktkImageURL,
+								Env: []corev1.EnvVar{
+									{
+										Name:  "HOME",
+										Value: "/tmp",
+									},
+								},
+								Script: logScanScript,
+								VolumeMounts: []corev1.VolumeMount{
+									{
+										Name:      logScanVolume,
+										MountPath: LogScanDir,
+									},
+								},
+								SecurityContext: &corev1.SecurityContext{
+									Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+									RunAsNonRoot:             ptr.To(true),
+									RunAsUser:                &normalUser,
+									AllowPrivilegeEscalation: ptr.To(false),
+								},
+								ComputeResources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										"cpu":    resource.MustParse("100m"),
+										"memory": resource.MustParse("256Mi"),
+									},
+									Limits: corev1.ResourceList{
+										"cpu":    resource.MustParse("100m"),
+										"memory": resource.MustParse("256Mi"),
+									},
+								},
+							},
+							{
+								Name:    logSanitizerStepName,
+								Image:   mintmakerImageURL,
+								Command: []string{"/log-sanitizer"},
+								Args: []string{
+									"--log-file=/workspace/shared-data/renovate-logs.json",
+									"--scan-dir=" + LogScanDir,
+									"--redactions=$(results." + LogRedactionsResult + ".path)",
+								},
+								VolumeMounts: []corev1.VolumeMount{
+									{
+										Name:      logScanVolume,
+										MountPath: LogScanDir,
+										ReadOnly:  true,
+									},
+								},
+								SecurityContext: &corev1.SecurityContext{
+									Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+									RunAsNonRoot:             ptr.To(true),
+									RunAsUser:                &normalUser,
+									AllowPrivilegeEscalation: ptr.To(false),
+								},
+								ComputeResources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										"cpu":    resource.MustParse("100m"),
+										"memory": resource.MustParse("128Mi"),
+									},
+									Limits: corev1.ResourceList{
+										"cpu":    resource.MustParse("100m"),
+										"memory": resource.MustParse("128Mi"),
+									},
 								},
 							},
-							TaskSpec: &tektonv1.EmbeddedTask{
-								TaskSpec: tektonv1.TaskSpec{
-									Workspaces: []tektonv1.WorkspaceDeclaration{
-										{
-											Name: "shared-data",
-										},
-									},
-									Steps: []tektonv1.Step{
-										{
-											Name:   "prepare-db",
-											Image:  "quay.io/konflux-ci/mintmaker-osv-database:latest",
-											Script: "echo 'Copying OSV database to the shared workspace'; cp -r /data/osv-db /workspace/shared-data",
-											SecurityContext: &corev1.SecurityContext{
-												Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
-												RunAsNonRoot:             ptr.To(true),
-												RunAsUser:                &normalUser,
-												AllowPrivilegeEscalation: ptr.To(false),
-											},
-											ComputeResources: corev1.ResourceRequirements{
-												Requests: corev1.ResourceList{
-													"cpu":    resource.MustParse("100m"),
-													"memory": resource.MustParse("512Mi"),
-												},
-												Limits: corev1.ResourceList{
-													"cpu":    resource.MustParse("100m"),
-													"memory": resource.MustParse("512Mi"),
-												},
-											},
-										},
-										{
-											Name:  "prepare-rpm-cert",
-											Image: "registry.access.redhat.com/ubi9",
-											Script: "[ ! -f \"/etc/renovate/secret/rpm-activationkey\" ] && echo 'RPM secret not found. Exiting.' && exit 0;" +
-												"echo 'Generating RPM certificate and copying it to shared workspace';" +
-												"KEY_NAME=$(cat /etc/renovate/secret/rpm-activationkey);" +
-												"ORG_ID=$(cat /etc/renovate/secret/rpm-org);" +
-												"subscription-manager register --activationkey=\"$KEY_NAME\" --org=\"$ORG_ID\";" +
-												"mkdir -p /workspace/shared-data/rpm-certs;" +
-												"cp /etc/pki/entitlement/*-key.pem /workspace/shared-data/rpm-certs/key.pem;" +
-												"cp $(find /etc/pki/entitlement -maxdepth 1 -type f -name '*.pem' ! -name '*-key.pem' -print -quit) /workspace/shared-data/rpm-certs/cert.pem",
-											SecurityContext: &corev1.SecurityContext{
-												Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
-												AllowPrivilegeEscalation: ptr.To(false),
-												RunAsUser:                &rootUser,
-											},
-											ComputeResources: corev1.ResourceRequirements{
-												Requests: corev1.ResourceList{
-													"cpu":    resource.MustParse("100m"),
-													"memory": resource.MustParse("256Mi"),
-												},
-												Limits: corev1.ResourceList{
-													"cpu":    resource.MustParse("100m"),
-													"memory": resource.MustParse("256Mi"),
-												},
-											},
-										},
-										{
-											Name:  "renovate",
-											Image: renovateImageURL,
-											Script: "RENOVATE_TOKEN=$(cat /etc/renovate/secret/renovate-token) " +
-												"RENOVATE_CONFIG_FILE=/etc/renovate/config/config.js " +
-												"LOG_FILE=/workspace/shared-data/renovate-logs.json " +
-												"renovate || true",
-											SecurityContext: &corev1.SecurityContext{
-												Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
-												RunAsNonRoot:             ptr.To(true),
-												RunAsUser:                &normalUser,
-												AllowPrivilegeEscalation: ptr.To(false),
-											},
-											ComputeResources: corev1.ResourceRequirements{
-												Requests: corev1.ResourceList{
-													"cpu":    resource.MustParse("300m"),
-													"memory": resource.MustParse("3.5Gi"),
-												},
-												Limits: corev1.ResourceList{
-													"cpu":    resource.MustParse("300m"),
-													"memory": resource.MustParse("3.5Gi"),
-												},
-											},
-											Env: []corev1.EnvVar{
-												{
-													Name:  "HOME",
-													Value: "/home/renovate",
-												},
-												{
-													Name:  "LOG_LEVEL",
-													Value: "debug",
-												},
-												{
-													Name:  "LOG_FORMAT",
-													Value: "json",
-												},
-												{
-													Name:  "OSV_OFFLINE_DISABLE_DOWNLOAD",
-													Value: "true",
-												},
-												{
-													Name:  "OSV_OFFLINE_ROOT_DIR",
-													Value: "/workspace/shared-data/osv-db",
-												},
-												{
-													Name:  "DNF_VAR_SSL_CLIENT_KEY",
-													Value: "/workspace/shared-data/rpm-certs/key.pem",
-												},
-												{
-													Name:  "DNF_VAR_SSL_CLIENT_CERT",
-													Value: "/workspace/shared-data/rpm-certs/cert.pem",
-												},
-												{
-													Name:  "RENOVATE_X_GITLAB_AUTO_MERGEABLE_CHECK_ATTEMPS",
-													Value: "7",
-												},
-											},
-										},
-										{
-											Name:  "log-sanitizer",
-											Image: leaktkImageURL,
-											Env: []corev1.EnvVar{
-												{
-													Name:  "HOME",
-													Value: "/tmp",
-												},
-											},
-											Script: logSanitizerScript,
-											SecurityContext: &corev1.SecurityContext{
-												Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
-												RunAsNonRoot:             ptr.To(true),
-												RunAsUser:                &normalUser,
-												AllowPrivilegeEscalation: ptr.To(false),
-											},
-											ComputeResources: corev1.ResourceRequirements{
-												Requests: corev1.ResourceList{
-													"cpu":    resource.MustParse("100m"),
-													"memory": resource.MustParse("256Mi"),
-												},
-												Limits: corev1.ResourceList{
-													"cpu":    resource.MustParse("100m"),
-													"memory": resource.MustParse("256Mi"),
-												},
-											},
-										},
+						},
+					},
+				},
+			},
+			{
+				// Reads the log once it is sanitized
+				Name:       summarizeTaskName,
+				RunAfter:   []string{sanitizeLogsTaskName},
+				Workspaces: sharedDataBinding(),
+				TaskSpec: &tektonv1.EmbeddedTask{
+					TaskSpec: tektonv1.TaskSpec{
+						Workspaces: sharedDataDeclaration(),
+						Results:    summarizeTaskResults(),
+						Steps: []tektonv1.Step{
+							{
+								Name:    summarizeTaskName,
+								Image:   mintmakerImageURL,
+								Command: []string{"/renovate-report"},
+								Args: []string{
+									"--log-file=/workspace/shared-data/renovate-logs.json",
+									"--report=$(results." + ReportResult + ".path)",
+									"--prs-created=$(results." + PullRequestsCreatedResult + ".path)",
+									"--prs-updated=$(results." + PullRequestsUpdatedResult + ".path)",
+									"--updated-dependencies=$(results." + UpdatedDependenciesResult + ".path)",
+									"--errors=$(results." + ErrorsResult + ".path)",
+								},
+								SecurityContext: &corev1.SecurityContext{
+									Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+									RunAsNonRoot:             ptr.To(true),
+									RunAsUser:                &normalUser,
+									AllowPrivilegeEscalation: ptr.To(false),
+								},
+								ComputeResources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										"cpu":    resource.MustParse("100m"),
+										"memory": resource.MustParse("256Mi"),
+									},
+									Limits: corev1.ResourceList{
+										"cpu":    resource.MustParse("100m"),
+										"memory": resource.MustParse("256Mi"),
 									},
 								},
 							},
 						},
 					},
 				},
-				Workspaces: []tektonv1.WorkspaceBinding{
+			},
+		},
+		Finally: []tektonv1.PipelineTask{
+			{
+				// Runs after the tasks reading the log, and fails the
+				// PipelineRun when Renovate failed
+				Name: renovateStatusTaskName,
+				Params: tektonv1.Params{
 					{
-						Name:     "shared-data",
-						EmptyDir: &corev1.EmptyDirVolumeSource{},
+						Name:  "exit-code",
+						Value: *tektonv1.NewStructuredValues("$(tasks." + renovateTaskName + ".results." + RenovateExitCodeResult + ")"),
+					},
+				},
+				TaskSpec: &tektonv1.EmbeddedTask{
+					TaskSpec: tektonv1.TaskSpec{
+						Params: tektonv1.ParamSpecs{
+							{
+								Name: "exit-code",
+								Type: tektonv1.ParamTypeString,
+							},
+						},
+						Steps: []tektonv1.Step{
+							{
+								Name:   renovateStatusTaskName,
+								Image:  renovateImageURL,
+								Script: renovateStatusScript,
+								Env: []corev1.EnvVar{
+									{
+										Name:  "RENOVATE_EXIT_CODE",
+										Value: "$(params.exit-code)",
+									},
+								},
+								SecurityContext: &corev1.SecurityContext{
+									Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+									RunAsNonRoot:             ptr.To(true),
+									RunAsUser:                &normalUser,
+									AllowPrivilegeEscalation: ptr.To(false),
+								},
+								ComputeResources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										"cpu":    resource.MustParse("100m"),
+										"memory": resource.MustParse("64Mi"),
+									},
+									Limits: corev1.ResourceList{
+										"cpu":    resource.MustParse("100m"),
+										"memory": resource.MustParse("64Mi"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Results: pipelineResults(),
+	}
+}
+
+// sharedDataBinding binds the shared-data workspace of the pipeline to a task
+func sharedDataBinding() []tektonv1.WorkspacePipelineTaskBinding {
+	return []tektonv1.WorkspacePipelineTaskBinding{
+		{
+			Name:      sharedDataWorkspace,
+			Workspace: sharedDataWorkspace,
+		},
+	}
+}
+
+// sharedDataDeclaration declares the shared-data workspace in a task
+func sharedDataDeclaration() []tektonv1.WorkspaceDeclaration {
+	return []tektonv1.WorkspaceDeclaration{
+		{
+			Name: sharedDataWorkspace,
+		},
+	}
+}
+
+// summarizeTaskResults declares the results of the summarize task
+func summarizeTaskResults() []tektonv1.TaskResult {
+	results := make([]tektonv1.TaskResult, 0, len(runResultNames))
+	for _, name := range runResultNames {
+		results = append(results, tektonv1.TaskResult{Name: name})
+	}
+	return results
+}
+
+// sharedDataVolume returns how the shared-data workspace is bound: an emptyDir
+// when a single task uses it, otherwise a PersistentVolumeClaim, since an
+// emptyDir is not shared between the pods of different tasks
+func sharedDataVolume(spec *tektonv1.PipelineSpec) tektonv1.WorkspaceBinding {
+	tasks := 0
+	for _, task := range spec.Tasks {
+		if slices.ContainsFunc(task.Workspaces, func(w tektonv1.WorkspacePipelineTaskBinding) bool {
+			return w.Workspace == sharedDataWorkspace
+		}) {
+			tasks++
+		}
+	}
+	if tasks <= 1 {
+		return tektonv1.WorkspaceBinding{
+			Name:     sharedDataWorkspace,
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		}
+	}
+	return tektonv1.WorkspaceBinding{
+		Name: sharedDataWorkspace,
+		VolumeClaimTemplate: &corev1.PersistentVolumeClaim{
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceStorage: resource.MustParse(sharedDataStorage),
 					},
 				},
 			},
@@ -291,9 +672,81 @@ func NewPipelineRunBuilder(name, namespace string) *PipelineRunBuilder {
 	}
 }
 
-// Build returns the constructed PipelineRun and any accumulated error.
+// NewPipelineRunBuilder initializes a new PipelineRunBuilder with the given name prefix and namespace.
+// It sets the name of the PipelineRun to be generated with the provided prefix and sets its namespace.
+func NewPipelineRunBuilder(name, namespace string) *PipelineRunBuilder {
+	return NewPipelineRunBuilderFromSpec(name, namespace, DefaultPipelineSpec())
+}
+
+// NewPipelineRunBuilderFromSpec initializes a new PipelineRunBuilder running a
+// copy of the given PipelineSpec, usually a validated PipelineRun template.
+func NewPipelineRunBuilderFromSpec(name, namespace string, spec *tektonv1.PipelineSpec) *PipelineRunBuilder {
+	return &PipelineRunBuilder{
+		pipelineRun: &tektonv1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: tektonv1.PipelineRunSpec{
+				PipelineSpec: spec.DeepCopy(),
+			},
+		},
+	}
+}
+
+// NewPipelineRunBuilderFromPipelineRun initializes a new PipelineRunBuilder
+// with a copy of the spec, labels and annotations of an existing PipelineRun,
+// e.g. to retry it. Metadata set by Tekton and MintMaker's bookkeeping of the
+// finished run, such as its collected results, are not copied.
+func NewPipelineRunBuilderFromPipelineRun(name string, pipelineRun *tektonv1.PipelineRun) *PipelineRunBuilder {
+	spec := pipelineRun.Spec.DeepCopy()
+	spec.Status = ""
+	b := &PipelineRunBuilder{
+		pipelineRun: &tektonv1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: pipelineRun.Namespace,
+			},
+			Spec: *spec,
+		},
+	}
+	for key, value := range pipelineRun.Labels {
+		if !strings.HasPrefix(key, "tekton.dev/") {
+			b.WithLabels(map[string]string{key: value})
+		}
+	}
+	for key, value := range pipelineRun.Annotations {
+		if !strings.HasPrefix(key, "tekton.dev/") && key != corev1.LastAppliedConfigAnnotation &&
+			key != mmconst.MintMakerResultsCollectedAnnotation {
+			b.WithAnnotations(map[string]string{key: value})
+		}
+	}
+	return b
+}
+
+// Build returns the constructed PipelineRun and any accumulated error. The
+// PipelineRun is validated by Validate when nothing failed while building it.
 func (b *PipelineRunBuilder) Build() (*tektonv1.PipelineRun, error) {
-	return b.pipelineRun, b.err.ErrorOrNil()
+	if err := b.err.ErrorOrNil(); err != nil {
+		return b.pipelineRun, err
+	}
+	// Bound last, tasks using the workspace may have been added
+	spec := &b.pipelineRun.Spec
+	if spec.PipelineSpec != nil && !slices.ContainsFunc(spec.Workspaces, func(w tektonv1.WorkspaceBinding) bool {
+		return w.Name == sharedDataWorkspace
+	}) {
+		spec.Workspaces = append(spec.Workspaces, sharedDataVolume(spec.PipelineSpec))
+	}
+	return b.pipelineRun, b.Validate()
+}
+
+// Validate runs Tekton's validation of the PipelineRun, the same the Tekton
+// admission webhook does on creation.
+func (b *PipelineRunBuilder) Validate() error {
+	if err := b.pipelineRun.Validate(apis.WithinCreate(context.Background())); err != nil {
+		return fmt.Errorf("invalid PipelineRun: %w", err)
+	}
+	return nil
 }
 
 // WithAnnotations appends or updates annotations to the PipelineRun's metadata.
@@ -349,45 +802,39 @@ func (b *PipelineRunBuilder) WithConfigMap(name, mountPath string, items []corev
 		opts.ReadOnly = &readOnly
 	}
 
-	for i, task := range b.pipelineRun.Spec.PipelineSpec.Tasks {
-		// Add volume when task matches
-		if task.Name == opts.TaskName && task.TaskSpec != nil {
-			volumeName := fmt.Sprintf("configmap-%s", sanitizeVolumeName(name))
-			volume := corev1.Volume{
-				Name: volumeName,
-				VolumeSource: corev1.VolumeSource{
-					ConfigMap: &corev1.ConfigMapVolumeSource{
-						LocalObjectReference: corev1.LocalObjectReference{
-							Name: name,
-						},
-						Items:       items,
-						Optional:    opts.Optional,
-						DefaultMode: opts.DefaultMode,
-					},
+	taskSpec := b.taskSpec(opts.TaskName)
+	if taskSpec == nil {
+		return b
+	}
+	volumeName := fmt.Sprintf("configmap-%s", sanitizeVolumeName(name))
+	volume := corev1.Volume{
+		Name: volumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: name,
 				},
-			}
-			b.pipelineRun.Spec.PipelineSpec.Tasks[i].TaskSpec.Volumes = append(
-				b.pipelineRun.Spec.PipelineSpec.Tasks[i].TaskSpec.Volumes,
-				volume,
-			)
-
-			// Add volume mount to specified steps or all steps
-			volumeMount := corev1.VolumeMount{
-				Name:      volumeName,
-				MountPath: mountPath,
-				ReadOnly:  *opts.ReadOnly,
-			}
-
-			for j := range b.pipelineRun.Spec.PipelineSpec.Tasks[i].TaskSpec.Steps {
-				step := &b.pipelineRun.Spec.PipelineSpec.Tasks[i].TaskSpec.Steps[j]
-				if len(opts.StepNames) == 0 || slices.Contains(opts.StepNames, step.Name) {
-					step.VolumeMounts = append(step.VolumeMounts, volumeMount)
-				}
-			}
-			break
-		}
+				Items:       items,
+				Optional:    opts.Optional,
+				DefaultMode: opts.DefaultMode,
+			},
+		},
+	}
+	// The same ConfigMap may be mounted into several steps, e.g. the service
+	// CA for the token broker and Kite, the volume is shared then
+	if k := slices.IndexFunc(taskSpec.Volumes, func(v corev1.Volume) bool { return v.Name == volumeName }); k < 0 {
+		taskSpec.Volumes = append(taskSpec.Volumes, volume)
+	} else if !reflect.DeepEqual(taskSpec.Volumes[k], volume) {
+		b.err = multierror.Append(b.err, fmt.Errorf("ConfigMap %s is already mounted in task %s with different options", name, opts.TaskName))
+		return b
 	}
-	// TODO: error when the specified task is not found
+
+	// Add volume mount to specified steps or all steps
+	b.mountSteps(opts.TaskName, taskSpec, opts.StepNames, corev1.VolumeMount{
+		Name:      volumeName,
+		MountPath: mountPath,
+		ReadOnly:  *opts.ReadOnly,
+	})
 	return b
 }
 
@@ -408,46 +855,91 @@ func (b *PipelineRunBuilder) WithSecret(name, mountPath string, items []corev1.K
 		opts.ReadOnly = &readOnly
 	}
 
-	// Find the specified task
+	taskSpec := b.taskSpec(opts.TaskName)
+	if taskSpec == nil {
+		return b
+	}
+	// Generate unique volume name using random string to avoid conflicts
+	// when the same secret is mounted multiple times
+	volumeName := fmt.Sprintf("secret-%s-%s", sanitizeVolumeName(name), utils.RandomString(8))
+	taskSpec.Volumes = append(taskSpec.Volumes, corev1.Volume{
+		Name: volumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName:  name,
+				Items:       items,
+				Optional:    opts.Optional,
+				DefaultMode: opts.DefaultMode,
+			},
+		},
+	})
+
+	// Add volume mount to specified steps or all steps
+	b.mountSteps(opts.TaskName, taskSpec, opts.StepNames, corev1.VolumeMount{
+		Name:      volumeName,
+		MountPath: mountPath,
+		ReadOnly:  *opts.ReadOnly,
+	})
+	return b
+}
+
+// taskSpec returns the embedded spec of the named task, recording an error
+// when the task is missing or references a Task.
+func (b *PipelineRunBuilder) taskSpec(taskName string) *tektonv1.TaskSpec {
+	if b.pipelineRun.Spec.PipelineSpec == nil {
+		b.err = multierror.Append(b.err, fmt.Errorf("task %s not found: PipelineRun has no embedded PipelineSpec", taskName))
+		return nil
+	}
 	for i, task := range b.pipelineRun.Spec.PipelineSpec.Tasks {
-		if task.Name == opts.TaskName && task.TaskSpec != nil {
-			// Generate unique volume name using random string to avoid conflicts
-			// when the same secret is mounted multiple times
-			volumeName := fmt.Sprintf("secret-%s-%s", sanitizeVolumeName(name), utils.RandomString(8))
-			volume := corev1.Volume{
-				Name: volumeName,
-				VolumeSource: corev1.VolumeSource{
-					Secret: &corev1.SecretVolumeSource{
-						SecretName:  name,
-						Items:       items,
-						Optional:    opts.Optional,
-						DefaultMode: opts.DefaultMode,
-					},
-				},
-			}
-			b.pipelineRun.Spec.PipelineSpec.Tasks[i].TaskSpec.Volumes = append(
-				b.pipelineRun.Spec.PipelineSpec.Tasks[i].TaskSpec.Volumes,
-				volume,
-			)
-
-			// Add volume mount to specified steps or all steps
-			volumeMount := corev1.VolumeMount{
-				Name:      volumeName,
-				MountPath: mountPath,
-				ReadOnly:  *opts.ReadOnly,
-			}
+		if task.Name != taskName {
+			continue
+		}
+		if task.TaskSpec == nil {
+			b.err = multierror.Append(b.err, fmt.Errorf("task %s must embed its TaskSpec", taskName))
+			return nil
+		}
+		return &b.pipelineRun.Spec.PipelineSpec.Tasks[i].TaskSpec.TaskSpec
+	}
+	b.err = multierror.Append(b.err, fmt.Errorf("task %s not found", taskName))
+	return nil
+}
 
-			for j := range b.pipelineRun.Spec.PipelineSpec.Tasks[i].TaskSpec.Steps {
-				step := &b.pipelineRun.Spec.PipelineSpec.Tasks[i].TaskSpec.Steps[j]
-				if len(opts.StepNames) == 0 || slices.Contains(opts.StepNames, step.Name) {
-					step.VolumeMounts = append(step.VolumeMounts, volumeMount)
-				}
-			}
-			break
+// mountSteps adds the volume mount to the named steps of the task, or to all
+// of its steps when no names are given. Unknown steps and mount paths already
+// taken in a step are recorded as errors.
+func (b *PipelineRunBuilder) mountSteps(taskName string, taskSpec *tektonv1.TaskSpec, stepNames []string, volumeMount corev1.VolumeMount) {
+	for _, stepName := range stepNames {
+		if !slices.ContainsFunc(taskSpec.Steps, func(step tektonv1.Step) bool { return step.Name == stepName }) {
+			b.err = multierror.Append(b.err, fmt.Errorf("step %s not found in task %s", stepName, taskName))
 		}
 	}
-	// TODO: error when the specified task is not found
-	return b
+	for j := range taskSpec.Steps {
+		step := &taskSpec.Steps[j]
+		if len(stepNames) > 0 && !slices.Contains(stepNames, step.Name) {
+			continue
+		}
+		if slices.ContainsFunc(step.VolumeMounts, func(m corev1.VolumeMount) bool { return m.MountPath == volumeMount.MountPath }) {
+			b.err = multierror.Append(b.err, fmt.Errorf("step %s in task %s already has a mount at %s", step.Name, taskName, volumeMount.MountPath))
+			continue
+		}
+		step.VolumeMounts = append(step.VolumeMounts, volumeMount)
+	}
+}
+
+// renovateStep returns the renovate step of the renovate task, recording an
+// error when either is missing.
+func (b *PipelineRunBuilder) renovateStep() *tektonv1.Step {
+	taskSpec := b.taskSpec(renovateTaskName)
+	if taskSpec == nil {
+		return nil
+	}
+	for j := range taskSpec.Steps {
+		if taskSpec.Steps[j].Name == renovateStepName {
+			return &taskSpec.Steps[j]
+		}
+	}
+	b.err = multierror.Append(b.err, fmt.Errorf("step %s not found in task %s", renovateStepName, renovateTaskName))
+	return nil
 }
 
 // WithObjectReferences constructs tektonv1.Param entries for each of the provided client.Objects.
@@ -512,10 +1004,19 @@ func (b *PipelineRunBuilder) WithServiceAccount(serviceAccount string) *Pipeline
 	return b
 }
 
+// WithPodTemplate sets the pod template of the PipelineRun's TaskRuns, e.g.
+// to schedule Renovate on dedicated nodes. A nil template is ignored.
+func (b *PipelineRunBuilder) WithPodTemplate(podTemplate *pod.Template) *PipelineRunBuilder {
+	if podTemplate != nil {
+		b.pipelineRun.Spec.TaskRunTemplate.PodTemplate = podTemplate.DeepCopy()
+	}
+	return b
+}
+
 // WithTimeouts sets the Timeouts for the PipelineRun.
 func (b *PipelineRunBuilder) WithTimeouts(timeouts *tektonv1.TimeoutFields) *PipelineRunBuilder {
 	defaultTimeouts := &tektonv1.TimeoutFields{
-		Pipeline: &metav1.Duration{Duration: 1 * time.Hour},
+		Pipeline: &metav1.Duration{Duration: config.DefaultPipelineRunTimeout},
 	}
 	if timeouts == nil || *timeouts == (tektonv1.TimeoutFields{}) {
 		b.pipelineRun.Spec.Timeouts = defaultTimeouts
@@ -525,95 +1026,409 @@ func (b *PipelineRunBuilder) WithTimeouts(timeouts *tektonv1.TimeoutFields) *Pip
 	return b
 }
 
-// WithKiteIntegration adds a log-analyzer step to analyzing Renovate logs and create issues
-// in Kite
-func (b *PipelineRunBuilder) WithKiteIntegration(kiteAPIURL string) *PipelineRunBuilder {
-	var normalUser int64 = 1001120000
-
-	// Find the build task and add log-analyzer step
-	for i, task := range b.pipelineRun.Spec.PipelineSpec.Tasks {
-		if task.Name == "build" && task.TaskSpec != nil {
-			steps := &b.pipelineRun.Spec.PipelineSpec.Tasks[i].TaskSpec.Steps
-			logAnalyzerStep := tektonv1.Step{
-				Name:   "log-analyzer",
-				Image:  "quay.io/konflux-ci/renovate-log-analyzer:latest",
-				Script: "",
-				SecurityContext: &corev1.SecurityContext{
-					Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
-					RunAsNonRoot:             ptr.To(true),
-					RunAsUser:                &normalUser,
-					AllowPrivilegeEscalation: ptr.To(false),
-				},
-				Env: []corev1.EnvVar{
-					{
-						Name: "POD_NAME",
-						ValueFrom: &corev1.EnvVarSource{
-							FieldRef: &corev1.ObjectFieldSelector{
-								FieldPath: "metadata.name",
-							},
-						},
-					},
-					{
-						Name: "NAMESPACE",
-						ValueFrom: &corev1.EnvVarSource{
-							FieldRef: &corev1.ObjectFieldSelector{
-								FieldPath: "metadata.labels['mintmaker.appstudio.redhat.com/namespace']",
-							},
-						},
-					},
-					{
-						Name: "GIT_HOST",
-						ValueFrom: &corev1.EnvVarSource{
-							FieldRef: &corev1.ObjectFieldSelector{
-								FieldPath: "metadata.labels['mintmaker.appstudio.redhat.com/git-host']",
-							},
-						},
-					},
+// WithTokenBroker makes the renovate step fetch its token from the token broker.
+// A projected ServiceAccount token with the given audience is mounted into the
+// step and presented to the broker at brokerURL. The fingerprints of the
+// fetched credentials are recorded in KnownFingerprintsFile for the
+// redact-known-secrets step, or the log-sanitizer step of templates without it.
+func (b *PipelineRunBuilder) WithTokenBroker(brokerURL, audience string) *PipelineRunBuilder {
+	taskSpec := b.taskSpec(renovateTaskName)
+	if taskSpec == nil {
+		return b
+	}
+	volumeName := "token-broker-sa-token"
+	taskSpec.Volumes = append(taskSpec.Volumes, corev1.Volume{
+		Name: volumeName,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{
 					{
-						Name: "REPOSITORY",
-						ValueFrom: &corev1.EnvVarSource{
-							FieldRef: &corev1.ObjectFieldSelector{
-								FieldPath: "metadata.labels['mintmaker.appstudio.redhat.com/repository']",
-							},
+						ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+							Audience:          audience,
+							ExpirationSeconds: ptr.To(tokenBrokerTokenExpirationSeconds),
+							Path:              "token",
 						},
 					},
-					{
-						Name: "BRANCH",
-						ValueFrom: &corev1.EnvVarSource{
-							FieldRef: &corev1.ObjectFieldSelector{
-								FieldPath: "metadata.labels['mintmaker.appstudio.redhat.com/branch']",
-							},
-						},
+				},
+			},
+		},
+	})
+	b.mountSteps(renovateTaskName, taskSpec, []string{renovateStepName}, corev1.VolumeMount{
+		Name:      volumeName,
+		MountPath: tokenBrokerTokenDir,
+		ReadOnly:  true,
+	})
+
+	for j := range taskSpec.Steps {
+		step := &taskSpec.Steps[j]
+		if step.Name != renovateStepName {
+			continue
+		}
+		step.Script = renovateTokenBrokerScript
+		step.Env = append(step.Env,
+			corev1.EnvVar{
+				Name:  "MINTMAKER_TOKEN_BROKER_URL",
+				Value: strings.TrimSuffix(brokerURL, "/"),
+			},
+			corev1.EnvVar{
+				Name:  "MINTMAKER_TOKEN_BROKER_TOKEN_FILE",
+				Value: tokenBrokerTokenDir + "/token",
+			},
+		)
+	}
+
+	if stepName := b.knownSecretsStepName(); stepName != "" {
+		for j := range taskSpec.Steps {
+			step := &taskSpec.Steps[j]
+			if step.Name == renovateStepName {
+				step.Env = append(step.Env, corev1.EnvVar{Name: "MINTMAKER_KNOWN_FINGERPRINTS_FILE", Value: KnownFingerprintsFile})
+			}
+		}
+		sanitizeTaskSpec := b.taskSpec(sanitizeLogsTaskName)
+		for j := range sanitizeTaskSpec.Steps {
+			step := &sanitizeTaskSpec.Steps[j]
+			if step.Name == stepName {
+				step.Args = append(step.Args, "--known-fingerprints-file="+KnownFingerprintsFile)
+			}
+		}
+	}
+	return b
+}
+
+// WithRenovateConfigFile points Renovate to the given file in RenovateConfigDir,
+// setting RENOVATE_CONFIG_FILE when the spec doesn't. Defaults to RenovateConfigFile.
+func (b *PipelineRunBuilder) WithRenovateConfigFile(fileName string) *PipelineRunBuilder {
+	step := b.renovateStep()
+	if step == nil {
+		return b
+	}
+	env := corev1.EnvVar{Name: "RENOVATE_CONFIG_FILE", Value: RenovateConfigDir + "/" + fileName}
+	// Templates may leave it out, the config is always mounted by MintMaker
+	if k := slices.IndexFunc(step.Env, func(e corev1.EnvVar) bool { return e.Name == env.Name }); k >= 0 {
+		step.Env[k] = env
+	} else {
+		step.Env = append(step.Env, env)
+	}
+	return b
+}
+
+// WithPresetsCA mounts the OpenShift service CA, which signs the preset
+// server certificate, into the renovate step and makes Renovate trust it with
+// NODE_EXTRA_CA_CERTS, unless the spec sets its own. The CA is optional, so
+// clusters without it keep working with a plain HTTP preset server.
+func (b *PipelineRunBuilder) WithPresetsCA() *PipelineRunBuilder {
+	opts := NewMountOptions().
+		WithTaskName(renovateTaskName).
+		WithStepNames([]string{renovateStepName}).
+		WithReadOnly(true).
+		WithOptional(true)
+	b.WithConfigMap("openshift-service-ca.crt", PresetsCADir, []corev1.KeyToPath{
+		{Key: "service-ca.crt", Path: "ca.crt"},
+	}, opts)
+
+	step := b.renovateStep()
+	if step == nil {
+		return b
+	}
+	if !slices.ContainsFunc(step.Env, func(e corev1.EnvVar) bool { return e.Name == "NODE_EXTRA_CA_CERTS" }) {
+		step.Env = append(step.Env, corev1.EnvVar{Name: "NODE_EXTRA_CA_CERTS", Value: PresetsCADir + "/ca.crt"})
+	}
+	return b
+}
+
+// WithSSHKey mounts the SSH deploy key stored in the given secret into the
+// renovate step and makes git use it. Host keys are pinned when the secret
+// provides known_hosts, otherwise they are accepted on first use.
+func (b *PipelineRunBuilder) WithSSHKey(secretName string, knownHosts bool) *PipelineRunBuilder {
+	step := b.renovateStep()
+	if step == nil {
+		return b
+	}
+	items := []corev1.KeyToPath{
+		{Key: corev1.SSHAuthPrivateKey, Path: "id"},
+	}
+	if knownHosts {
+		items = append(items, corev1.KeyToPath{Key: "known_hosts", Path: "known_hosts"})
+	}
+	// ssh only rejects group readable keys owned by the current user, the
+	// volume is owned by root and readable through the pod fsGroup
+	opts := NewMountOptions().WithTaskName(renovateTaskName).WithStepNames([]string{renovateStepName}).WithDefaultMode(0440)
+	b.WithSecret(secretName, sshKeyDir, items, opts)
+
+	step.Env = append(step.Env, corev1.EnvVar{
+		Name:  "GIT_SSH_COMMAND",
+		Value: gitSSHCommand(knownHosts),
+	})
+	return b
+}
+
+// WithBrokeredSSHKey makes git use the SSH deploy key returned by the token
+// broker, which the renovate step writes to an in-memory emptyDir, so the key
+// is never stored in a Secret. It requires WithTokenBroker.
+func (b *PipelineRunBuilder) WithBrokeredSSHKey(knownHosts bool) *PipelineRunBuilder {
+	taskSpec := b.taskSpec(renovateTaskName)
+	if taskSpec == nil {
+		return b
+	}
+	volumeName := "ssh-key"
+	taskSpec.Volumes = append(taskSpec.Volumes, corev1.Volume{
+		Name: volumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory},
+		},
+	})
+	b.mountSteps(renovateTaskName, taskSpec, []string{renovateStepName}, corev1.VolumeMount{
+		Name:      volumeName,
+		MountPath: sshKeyDir,
+	})
+
+	for j := range taskSpec.Steps {
+		step := &taskSpec.Steps[j]
+		if step.Name != renovateStepName {
+			continue
+		}
+		step.Env = append(step.Env,
+			corev1.EnvVar{Name: "MINTMAKER_SSH_KEY_DIR", Value: sshKeyDir},
+			corev1.EnvVar{Name: "GIT_SSH_COMMAND", Value: gitSSHCommand(knownHosts)},
+		)
+	}
+	return b
+}
+
+// WithGitTokenRefresh has the renovate step refresh the token from the token
+// broker before it expires and make git use the refreshed token for the
+// repositories on host, from a git config kept in an in-memory emptyDir.
+// Renovate embeds the token it read at startup in the URLs it clones and
+// pushes to, git sends the refreshed one first. It requires WithTokenBroker.
+func (b *PipelineRunBuilder) WithGitTokenRefresh(platform, host string) *PipelineRunBuilder {
+	taskSpec := b.taskSpec(renovateTaskName)
+	if taskSpec == nil {
+		return b
+	}
+	taskSpec.Volumes = append(taskSpec.Volumes, corev1.Volume{
+		Name: gitConfigVolume,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory},
+		},
+	})
+	b.mountSteps(renovateTaskName, taskSpec, []string{renovateStepName}, corev1.VolumeMount{
+		Name:      gitConfigVolume,
+		MountPath: gitConfigDir,
+	})
+
+	for j := range taskSpec.Steps {
+		step := &taskSpec.Steps[j]
+		if step.Name != renovateStepName {
+			continue
+		}
+		step.Env = append(step.Env,
+			corev1.EnvVar{Name: "MINTMAKER_GIT_CONFIG_DIR", Value: gitConfigDir},
+			corev1.EnvVar{Name: "MINTMAKER_GIT_URL", Value: "https://" + host + "/"},
+			corev1.EnvVar{Name: "MINTMAKER_GIT_USERNAME", Value: gitTokenUsername(platform)},
+			// git reads the included file again on every command
+			corev1.EnvVar{Name: "GIT_CONFIG_COUNT", Value: "1"},
+			corev1.EnvVar{Name: "GIT_CONFIG_KEY_0", Value: "include.path"},
+			corev1.EnvVar{Name: "GIT_CONFIG_VALUE_0", Value: gitConfigDir + "/gitconfig"},
+		)
+	}
+	return b
+}
+
+// gitTokenUsername is the user name sent with a token to the git host.
+// GitHub App tokens need x-access-token, GitLab OAuth tokens need oauth2, which
+// GitLab and Forgejo accept with any token.
+func gitTokenUsername(platform string) string {
+	if platform == "github" {
+		return "x-access-token"
+	}
+	return "oauth2"
+}
+
+// gitSSHCommand returns the ssh command git uses with the key in sshKeyDir.
+// Host keys are pinned when known_hosts is provided, otherwise they are
+// accepted on first use.
+func gitSSHCommand(knownHosts bool) string {
+	hostKeyOptions := "-o StrictHostKeyChecking=accept-new -o UserKnownHostsFile=/tmp/known_hosts"
+	if knownHosts {
+		hostKeyOptions = "-o StrictHostKeyChecking=yes -o UserKnownHostsFile=" + sshKeyDir + "/known_hosts"
+	}
+	return "ssh -i " + sshKeyDir + "/id -o IdentitiesOnly=yes " + hostKeyOptions
+}
+
+// WithKnownSecrets mounts the given Secret keys into KnownSecretsDir of the
+// redact-known-secrets step, which redacts their values from the log before
+// leaktk scans it, or of the log-sanitizer step of templates without it. Calls
+// add to the same projected volume, so the paths of the sources must be
+// distinct. Templates without either step are left unchanged.
+func (b *PipelineRunBuilder) WithKnownSecrets(sources ...corev1.SecretProjection) *PipelineRunBuilder {
+	stepName := b.knownSecretsStepName()
+	if len(sources) == 0 || stepName == "" {
+		return b
+	}
+	taskSpec := b.taskSpec(sanitizeLogsTaskName)
+	if taskSpec == nil {
+		return b
+	}
+	projections := make([]corev1.VolumeProjection, 0, len(sources))
+	for i := range sources {
+		projections = append(projections, corev1.VolumeProjection{Secret: &sources[i]})
+	}
+	if k := slices.IndexFunc(taskSpec.Volumes, func(v corev1.Volume) bool { return v.Name == knownSecretsVolume }); k >= 0 {
+		projected := taskSpec.Volumes[k].Projected
+		if projected == nil {
+			b.err = multierror.Append(b.err, fmt.Errorf("volume %s in task %s is not a projected volume", knownSecretsVolume, sanitizeLogsTaskName))
+			return b
+		}
+		projected.Sources = append(projected.Sources, projections...)
+		return b
+	}
+
+	taskSpec.Volumes = append(taskSpec.Volumes, corev1.Volume{
+		Name: knownSecretsVolume,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{Sources: projections},
+		},
+	})
+	b.mountSteps(sanitizeLogsTaskName, taskSpec, []string{stepName}, corev1.VolumeMount{
+		Name:      knownSecretsVolume,
+		MountPath: KnownSecretsDir,
+		ReadOnly:  true,
+	})
+	for j := range taskSpec.Steps {
+		step := &taskSpec.Steps[j]
+		if step.Name == stepName {
+			step.Args = append(step.Args, "--known-secrets-dir="+KnownSecretsDir)
+		}
+	}
+	return b
+}
+
+// knownSecretsStepName returns the step of the sanitize-logs task redacting
+// the known secrets, empty when the template has none
+func (b *PipelineRunBuilder) knownSecretsStepName() string {
+	for _, stepName := range []string{redactKnownSecretsStepName, logSanitizerStepName} {
+		if b.hasStep(sanitizeLogsTaskName, stepName) {
+			return stepName
+		}
+	}
+	return ""
+}
+
+// hasStep reports whether the named task embeds the named step
+func (b *PipelineRunBuilder) hasStep(taskName, stepName string) bool {
+	if b.pipelineRun.Spec.PipelineSpec == nil {
+		return false
+	}
+	for _, task := range b.pipelineRun.Spec.PipelineSpec.Tasks {
+		if task.Name == taskName && task.TaskSpec != nil {
+			return slices.ContainsFunc(task.TaskSpec.Steps, func(step tektonv1.Step) bool { return step.Name == stepName })
+		}
+	}
+	return false
+}
+
+// WithKiteIntegration adds a log-analyzer task analyzing the sanitized Renovate
+// logs and creating issues in Kite. It runs after the sanitize-logs task, or
+// after the build task of templates without one.
+func (b *PipelineRunBuilder) WithKiteIntegration(kiteAPIURL string) *PipelineRunBuilder {
+	var normalUser int64 = 1001120000
+
+	if b.taskSpec(renovateTaskName) == nil {
+		return b
+	}
+	runAfter := renovateTaskName
+	if slices.ContainsFunc(b.pipelineRun.Spec.PipelineSpec.Tasks, func(task tektonv1.PipelineTask) bool {
+		return task.Name == sanitizeLogsTaskName
+	}) {
+		runAfter = sanitizeLogsTaskName
+	}
+	logAnalyzerStep := tektonv1.Step{
+		Name:   "log-analyzer",
+		Image:  "quay.io/konflux-ci/renovate-log-analyzer:latest",
+		Script: "",
+		SecurityContext: &corev1.SecurityContext{
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+			RunAsNonRoot:             ptr.To(true),
+			RunAsUser:                &normalUser,
+			AllowPrivilegeEscalation: ptr.To(false),
+		},
+		Env: []corev1.EnvVar{
+			{
+				Name: "POD_NAME",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "metadata.name",
 					},
-					{
-						Name: "PIPELINE_RUN",
-						ValueFrom: &corev1.EnvVarSource{
-							FieldRef: &corev1.ObjectFieldSelector{
-								FieldPath: "metadata.labels['tekton.dev/pipelineRun']",
-							},
-						},
+				},
+			},
+			{
+				Name: "NAMESPACE",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "metadata.labels['mintmaker.appstudio.redhat.com/namespace']",
 					},
-					{
-						Name:  "SSL_CERT_FILE",
-						Value: "/etc/pki/kite-ca/ca.crt",
+				},
+			},
+			{
+				Name: "GIT_HOST",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "metadata.labels['mintmaker.appstudio.redhat.com/git-host']",
 					},
-					{
-						Name:  "KITE_API_URL",
-						Value: kiteAPIURL,
+				},
+			},
+			{
+				Name: "REPOSITORY",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "metadata.labels['mintmaker.appstudio.redhat.com/repository']",
 					},
-					{
-						Name:  "LOG_FILE",
-						Value: "/workspace/shared-data/renovate-logs.json",
+				},
+			},
+			{
+				Name: "BRANCH",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "metadata.labels['mintmaker.appstudio.redhat.com/branch']",
 					},
-					{
-						Name:  "KITE_AUTH_TOKEN_FILE",
-						Value: "/var/run/secrets/kite/token",
+				},
+			},
+			{
+				Name: "PIPELINE_RUN",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "metadata.labels['tekton.dev/pipelineRun']",
 					},
 				},
-			}
-			*steps = append(*steps, logAnalyzerStep)
-			break
-		}
+			},
+			{
+				Name:  "SSL_CERT_FILE",
+				Value: "/etc/pki/kite-ca/ca.crt",
+			},
+			{
+				Name:  "KITE_API_URL",
+				Value: kiteAPIURL,
+			},
+			{
+				Name:  "LOG_FILE",
+				Value: "/workspace/shared-data/renovate-logs.json",
+			},
+			{
+				Name:  "KITE_AUTH_TOKEN_FILE",
+				Value: "/var/run/secrets/kite/token",
+			},
+		},
 	}
+	b.pipelineRun.Spec.PipelineSpec.Tasks = append(b.pipelineRun.Spec.PipelineSpec.Tasks, tektonv1.PipelineTask{
+		Name:       LogAnalyzerTaskName,
+		RunAfter:   []string{runAfter},
+		Workspaces: sharedDataBinding(),
+		TaskSpec: &tektonv1.EmbeddedTask{
+			TaskSpec: tektonv1.TaskSpec{
+				Workspaces: sharedDataDeclaration(),
+				Steps:      []tektonv1.Step{logAnalyzerStep},
+			},
+		},
+	})
 	return b
 }
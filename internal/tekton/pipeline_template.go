@@ -0,0 +1,220 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tekton
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logger "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// PipelineTemplateKey holds the PipelineSpec in a template ConfigMap
+	PipelineTemplateKey = "pipeline.yaml"
+	// PipelineTemplateAnnotation records the template a PipelineRun was built from
+	PipelineTemplateAnnotation = "mintmaker.appstudio.redhat.com/pipeline-template"
+
+	// The workspace shared by the steps, bound to an emptyDir by the builder,
+	// or to a PersistentVolumeClaim when several tasks use it
+	sharedDataWorkspace = "shared-data"
+	// The task and step MintMaker mounts the Renovate config and credentials to
+	renovateTaskName = "build"
+	renovateStepName = "renovate"
+	// The tasks of the built-in spec reading the Renovate log after the build
+	sanitizeLogsTaskName = "sanitize-logs"
+	summarizeTaskName    = "summarize"
+	// The steps of the sanitize-logs task MintMaker mounts the known secrets
+	// to, the first one redacting them before the leaktk scan. Templates may
+	// only have the last one, which then redacts them after the scan.
+	redactKnownSecretsStepName = "redact-known-secrets"
+	logSanitizerStepName       = "log-sanitizer"
+	// The finally task of the built-in spec failing the run when Renovate failed
+	renovateStatusTaskName = "renovate-status"
+	// LogAnalyzerTaskName is the task added by WithKiteIntegration
+	LogAnalyzerTaskName = "log-analyzer"
+
+	// PipelineTemplateBuiltIn is the source of DefaultPipelineSpec
+	PipelineTemplateBuiltIn = "built-in"
+)
+
+// PipelineTemplate is a validated PipelineSpec and where it was read from
+type PipelineTemplate struct {
+	Spec *tektonv1.PipelineSpec
+	// Source is "configmap/<name>@<hash>", "pipeline/<name>@<hash>" or
+	// PipelineTemplateBuiltIn
+	Source string
+}
+
+// ParsePipelineTemplate reads a PipelineSpec from YAML or JSON and validates it
+func ParsePipelineTemplate(data []byte) (*tektonv1.PipelineSpec, error) {
+	spec := &tektonv1.PipelineSpec{}
+	if err := yaml.UnmarshalStrict(data, spec); err != nil {
+		return nil, fmt.Errorf("failed to parse PipelineSpec: %w", err)
+	}
+	if err := ValidatePipelineTemplate(spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// ValidatePipelineTemplate checks the spec is a valid Tekton PipelineSpec that
+// MintMaker can apply its mounts and config to: it declares the shared-data
+// workspace and embeds the build task with a renovate step.
+func ValidatePipelineTemplate(spec *tektonv1.PipelineSpec) error {
+	if err := spec.Validate(context.Background()); err != nil {
+		return fmt.Errorf("invalid PipelineSpec: %w", err)
+	}
+	if !slices.ContainsFunc(spec.Workspaces, func(w tektonv1.PipelineWorkspaceDeclaration) bool {
+		return w.Name == sharedDataWorkspace
+	}) {
+		return fmt.Errorf("workspace %s is not declared", sharedDataWorkspace)
+	}
+	i := slices.IndexFunc(spec.Tasks, func(t tektonv1.PipelineTask) bool { return t.Name == renovateTaskName })
+	if i < 0 {
+		return fmt.Errorf("task %s is missing", renovateTaskName)
+	}
+	if spec.Tasks[i].TaskSpec == nil {
+		return fmt.Errorf("task %s must be embedded with taskSpec, not referenced", renovateTaskName)
+	}
+	if !slices.ContainsFunc(spec.Tasks[i].TaskSpec.Steps, func(s tektonv1.Step) bool { return s.Name == renovateStepName }) {
+		return fmt.Errorf("task %s has no %s step", renovateTaskName, renovateStepName)
+	}
+	return nil
+}
+
+// TemplateLoader reads the PipelineRun template from a ConfigMap or a Tekton
+// Pipeline and reads it again once the refresh interval passed. An invalid
+// template is rejected and the last good one stays active. Without a source
+// the built-in spec is used.
+type TemplateLoader struct {
+	// Namespace holds the template ConfigMap or Pipeline
+	Namespace string
+	// ConfigMap holds the template in PipelineTemplateKey
+	ConfigMap string
+	// Pipeline is a Tekton Pipeline whose spec is embedded in PipelineRuns
+	Pipeline string
+
+	mu        sync.RWMutex
+	template  *PipelineTemplate
+	checkedAt time.Time
+	now       func() time.Time
+}
+
+// NewTemplateLoader returns a loader for the template in the given ConfigMap
+// or Pipeline, at most one of them may be set
+func NewTemplateLoader(namespace, configMap, pipeline string) *TemplateLoader {
+	return &TemplateLoader{Namespace: namespace, ConfigMap: configMap, Pipeline: pipeline, now: time.Now}
+}
+
+// Get returns the active template, reading its source first when it wasn't
+// read within refreshInterval. The template is shared, the builder copies it.
+func (l *TemplateLoader) Get(ctx context.Context, k8sClient client.Client, refreshInterval time.Duration) (*PipelineTemplate, error) {
+	if l.ConfigMap == "" && l.Pipeline == "" {
+		return &PipelineTemplate{Spec: DefaultPipelineSpec(), Source: PipelineTemplateBuiltIn}, nil
+	}
+
+	l.mu.RLock()
+	if l.template != nil && l.now().Sub(l.checkedAt) < refreshInterval {
+		defer l.mu.RUnlock()
+		return l.template, nil
+	}
+	l.mu.RUnlock()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// Another caller may have refreshed while we waited for the lock
+	if l.template != nil && l.now().Sub(l.checkedAt) < refreshInterval {
+		return l.template, nil
+	}
+	if err := l.refresh(ctx, k8sClient); err != nil {
+		if l.template == nil {
+			return nil, err
+		}
+		logger.FromContext(ctx).Error(err, "keeping the last good PipelineRun template", "source", l.template.Source)
+	}
+	return l.template, nil
+}
+
+// refresh reads the template and activates it when it changed and is valid.
+// Must be called with the write lock held.
+func (l *TemplateLoader) refresh(ctx context.Context, k8sClient client.Client) error {
+	// Failures are retried after the next refresh interval too
+	l.checkedAt = l.now()
+
+	var spec *tektonv1.PipelineSpec
+	var source string
+	if l.ConfigMap != "" {
+		key := types.NamespacedName{Namespace: l.Namespace, Name: l.ConfigMap}
+		configMap := corev1.ConfigMap{}
+		if err := k8sClient.Get(ctx, key, &configMap); err != nil {
+			return fmt.Errorf("failed to get PipelineRun template %s: %w", key, err)
+		}
+		data, ok := configMap.Data[PipelineTemplateKey]
+		if !ok {
+			return fmt.Errorf("rejected PipelineRun template %s: %s is missing", key, PipelineTemplateKey)
+		}
+		var err error
+		if spec, err = ParsePipelineTemplate([]byte(data)); err != nil {
+			return fmt.Errorf("rejected PipelineRun template %s: %w", key, err)
+		}
+		source = "configmap/" + l.ConfigMap
+	} else {
+		key := types.NamespacedName{Namespace: l.Namespace, Name: l.Pipeline}
+		pipeline := tektonv1.Pipeline{}
+		if err := k8sClient.Get(ctx, key, &pipeline); err != nil {
+			return fmt.Errorf("failed to get PipelineRun template %s: %w", key, err)
+		}
+		if err := ValidatePipelineTemplate(&pipeline.Spec); err != nil {
+			return fmt.Errorf("rejected PipelineRun template %s: %w", key, err)
+		}
+		spec = &pipeline.Spec
+		source = "pipeline/" + l.Pipeline
+	}
+
+	hash, err := hashPipelineSpec(spec)
+	if err != nil {
+		return err
+	}
+	source += "@" + hash
+	if l.template != nil && l.template.Source == source {
+		return nil
+	}
+	logger.FromContext(ctx).Info("activated PipelineRun template", "source", source)
+	l.template = &PipelineTemplate{Spec: spec, Source: source}
+	return nil
+}
+
+// hashPipelineSpec returns a stable hash of the spec, identifying the
+// template version a PipelineRun was built from
+func hashPipelineSpec(spec *tektonv1.PipelineSpec) (string, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize PipelineSpec: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16], nil
+}
@@ -0,0 +1,202 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tekton
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+const templateYAML = `
+workspaces:
+- name: shared-data
+tasks:
+- name: build
+  workspaces:
+  - name: shared-data
+    workspace: shared-data
+  taskSpec:
+    workspaces:
+    - name: shared-data
+    steps:
+    - name: renovate
+      image: quay.io/konflux-ci/mintmaker-renovate-image:v2
+      script: renovate
+      computeResources:
+        requests:
+          memory: 2Gi
+`
+
+var _ = Describe("PipelineRun template", func() {
+
+	When("ParsePipelineTemplate is called", func() {
+		It("should accept the built-in spec", func() {
+			data, err := yaml.Marshal(DefaultPipelineSpec())
+			Expect(err).NotTo(HaveOccurred())
+			_, err = ParsePipelineTemplate(data)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should parse a minimal template", func() {
+			spec, err := ParsePipelineTemplate([]byte(templateYAML))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(spec.Tasks[0].TaskSpec.Steps[0].Image).To(Equal("quay.io/konflux-ci/mintmaker-renovate-image:v2"))
+		})
+
+		DescribeTable("should reject invalid templates",
+			func(data, message string) {
+				_, err := ParsePipelineTemplate([]byte(data))
+				Expect(err).To(MatchError(ContainSubstring(message)))
+			},
+			Entry("unknown field", "tasks: []\nunknown: true", "unknown field"),
+			Entry("invalid Tekton spec", "tasks:\n- name: build\n  taskSpec:\n    steps: []", "invalid PipelineSpec"),
+			Entry("missing workspace", "tasks:\n- name: build\n  taskSpec:\n    steps:\n    - name: renovate\n      image: renovate",
+				"workspace shared-data is not declared"),
+			Entry("missing build task", "workspaces:\n- name: shared-data\ntasks:\n- name: other\n  taskSpec:\n    steps:\n    - name: renovate\n      image: renovate",
+				"task build is missing"),
+			Entry("referenced build task", "workspaces:\n- name: shared-data\ntasks:\n- name: build\n  taskRef:\n    name: renovate",
+				"must be embedded"),
+			Entry("missing renovate step", "workspaces:\n- name: shared-data\ntasks:\n- name: build\n  taskSpec:\n    steps:\n    - name: other\n      image: renovate",
+				"has no renovate step"),
+		)
+	})
+
+	When("a TemplateLoader is used", func() {
+		var (
+			ctx       context.Context
+			k8sClient client.Client
+			configMap *corev1.ConfigMap
+			now       time.Time
+		)
+
+		newLoader := func(configMapName, pipelineName string) *TemplateLoader {
+			loader := NewTemplateLoader("mintmaker", configMapName, pipelineName)
+			loader.now = func() time.Time { return now }
+			return loader
+		}
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			now = time.Now()
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "mintmaker", Name: "renovate-pipeline-v2"},
+				Data:       map[string]string{PipelineTemplateKey: templateYAML},
+			}
+			spec, err := ParsePipelineTemplate([]byte(templateYAML))
+			Expect(err).NotTo(HaveOccurred())
+			pipeline := &tektonv1.Pipeline{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "mintmaker", Name: "renovate"},
+				Spec:       *spec,
+			}
+			scheme := runtime.NewScheme()
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+			Expect(tektonv1.AddToScheme(scheme)).To(Succeed())
+			k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap, pipeline).Build()
+		})
+
+		It("should return the built-in spec without a source", func() {
+			template, err := newLoader("", "").Get(ctx, k8sClient, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(template.Source).To(Equal(PipelineTemplateBuiltIn))
+			Expect(template.Spec).To(Equal(DefaultPipelineSpec()))
+		})
+
+		It("should read the template from a ConfigMap", func() {
+			template, err := newLoader("renovate-pipeline-v2", "").Get(ctx, k8sClient, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(template.Source).To(HavePrefix("configmap/renovate-pipeline-v2@"))
+			Expect(template.Spec.Tasks[0].TaskSpec.Steps[0].Image).To(Equal("quay.io/konflux-ci/mintmaker-renovate-image:v2"))
+		})
+
+		It("should read the template from a Pipeline", func() {
+			template, err := newLoader("", "renovate").Get(ctx, k8sClient, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(template.Source).To(HavePrefix("pipeline/renovate@"))
+			Expect(template.Spec.Tasks[0].Name).To(Equal("build"))
+		})
+
+		It("should fail when the first template is invalid", func() {
+			configMap.Data[PipelineTemplateKey] = "tasks: []"
+			Expect(k8sClient.Update(ctx, configMap)).To(Succeed())
+
+			_, err := newLoader("renovate-pipeline-v2", "").Get(ctx, k8sClient, time.Minute)
+			Expect(err).To(MatchError(ContainSubstring("rejected PipelineRun template")))
+		})
+
+		It("should keep the last good template when an update is invalid", func() {
+			loader := newLoader("renovate-pipeline-v2", "")
+			first, err := loader.Get(ctx, k8sClient, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+
+			configMap.Data[PipelineTemplateKey] = "tasks: []"
+			Expect(k8sClient.Update(ctx, configMap)).To(Succeed())
+			now = now.Add(2 * time.Minute)
+
+			template, err := loader.Get(ctx, k8sClient, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(template).To(BeIdenticalTo(first))
+		})
+
+		It("should activate a valid update after the refresh interval", func() {
+			loader := newLoader("renovate-pipeline-v2", "")
+			first, err := loader.Get(ctx, k8sClient, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+
+			configMap.Data[PipelineTemplateKey] = templateYAML + "      env:\n      - name: LOG_LEVEL\n        value: info\n"
+			Expect(k8sClient.Update(ctx, configMap)).To(Succeed())
+
+			template, err := loader.Get(ctx, k8sClient, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(template).To(BeIdenticalTo(first))
+
+			now = now.Add(2 * time.Minute)
+			template, err = loader.Get(ctx, k8sClient, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(template.Source).NotTo(Equal(first.Source))
+			Expect(template.Spec.Tasks[0].TaskSpec.Steps[0].Env).To(HaveLen(1))
+		})
+	})
+
+	When("NewPipelineRunBuilderFromSpec is called", func() {
+		It("should apply MintMaker's mounts to a copy of the template", func() {
+			spec, err := ParsePipelineTemplate([]byte(templateYAML))
+			Expect(err).NotTo(HaveOccurred())
+
+			pipelineRun, err := NewPipelineRunBuilderFromSpec("test", "mintmaker", spec).
+				WithConfigMap("test", RenovateConfigDir, nil, NewMountOptions().WithStepNames([]string{"renovate"})).
+				WithRenovateConfigFile(RenovateConfigFile).
+				Build()
+			Expect(err).NotTo(HaveOccurred())
+
+			step := pipelineRun.Spec.PipelineSpec.Tasks[0].TaskSpec.Steps[0]
+			Expect(step.VolumeMounts).To(HaveLen(1))
+			Expect(step.Env).To(ContainElement(corev1.EnvVar{Name: "RENOVATE_CONFIG_FILE", Value: RenovateConfigDir + "/" + RenovateConfigFile}))
+			Expect(pipelineRun.Spec.Workspaces[0].Name).To(Equal("shared-data"))
+			// The template itself is left untouched
+			Expect(spec.Tasks[0].TaskSpec.Steps[0].VolumeMounts).To(BeEmpty())
+			Expect(spec.Tasks[0].TaskSpec.Volumes).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,106 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tekton
+
+import (
+	"encoding/json"
+	"strconv"
+
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+const (
+	// PullRequestsCreatedResult is the number of pull requests Renovate created
+	PullRequestsCreatedResult = "renovate-prs-created"
+	// PullRequestsUpdatedResult is the number of pull requests Renovate updated
+	PullRequestsUpdatedResult = "renovate-prs-updated"
+	// UpdatedDependenciesResult is the number of dependencies with an update
+	// branch
+	UpdatedDependenciesResult = "renovate-updated-dependencies"
+	// ErrorsResult is the number of error and fatal entries in the Renovate log
+	ErrorsResult = "renovate-errors"
+	// ReportResult is the JSON report of the run, see renovatelog.Report
+	ReportResult = "renovate-report"
+)
+
+// runResultNames are the task results of the summarize task, exposed as
+// PipelineRun results with the same names
+var runResultNames = []string{
+	PullRequestsCreatedResult,
+	PullRequestsUpdatedResult,
+	UpdatedDependenciesResult,
+	ErrorsResult,
+	ReportResult,
+}
+
+// RunResults are the counts parsed from the Renovate log of a PipelineRun
+type RunResults struct {
+	PullRequestsCreated int
+	PullRequestsUpdated int
+	UpdatedDependencies int
+	Errors              int
+}
+
+// pipelineResults exposes the results of the summarize task and the exit code
+// of Renovate as PipelineRun results
+func pipelineResults() []tektonv1.PipelineResult {
+	results := make([]tektonv1.PipelineResult, 0, len(runResultNames))
+	for _, name := range runResultNames {
+		results = append(results, tektonv1.PipelineResult{
+			Name:  name,
+			Value: *tektonv1.NewStructuredValues("$(tasks." + summarizeTaskName + ".results." + name + ")"),
+		})
+	}
+	return append(results, tektonv1.PipelineResult{
+		Name:  RenovateExitCodeResult,
+		Value: *tektonv1.NewStructuredValues("$(tasks." + renovateTaskName + ".results." + RenovateExitCodeResult + ")"),
+	})
+}
+
+// ParseRunResults returns the results of a completed PipelineRun. It returns
+// false when the PipelineRun reported none of them, e.g. when it was built
+// from a template without the summarize task.
+func ParseRunResults(pipelineRun *tektonv1.PipelineRun) (RunResults, bool) {
+	var results RunResults
+	fields := map[string]*int{
+		PullRequestsCreatedResult: &results.PullRequestsCreated,
+		PullRequestsUpdatedResult: &results.PullRequestsUpdated,
+		UpdatedDependenciesResult: &results.UpdatedDependencies,
+		ErrorsResult:              &results.Errors,
+	}
+	found := false
+	for _, result := range pipelineRun.Status.Results {
+		field, ok := fields[result.Name]
+		if !ok {
+			continue
+		}
+		if value, err := strconv.Atoi(result.Value.StringVal); err == nil && value >= 0 {
+			*field = value
+			found = true
+		}
+	}
+	return results, found
+}
+
+// RunReport returns the JSON report of a completed PipelineRun. It returns
+// false when the PipelineRun reported none or it is not valid JSON.
+func RunReport(pipelineRun *tektonv1.PipelineRun) (string, bool) {
+	for _, result := range pipelineRun.Status.Results {
+		if result.Name == ReportResult {
+			return result.Value.StringVal, json.Valid([]byte(result.Value.StringVal))
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tekton
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+func pipelineRunWithResults(results map[string]string) *tektonv1.PipelineRun {
+	pipelineRun := &tektonv1.PipelineRun{}
+	for name, value := range results {
+		pipelineRun.Status.Results = append(pipelineRun.Status.Results, tektonv1.PipelineRunResult{
+			Name:  name,
+			Value: *tektonv1.NewStructuredValues(value),
+		})
+	}
+	return pipelineRun
+}
+
+var _ = Describe("PipelineRun results", func() {
+
+	When("ParseRunResults is called", func() {
+		DescribeTable("should parse the results of the summarize task",
+			func(results map[string]string, expected RunResults, expectedFound bool) {
+				parsed, found := ParseRunResults(pipelineRunWithResults(results))
+				Expect(found).To(Equal(expectedFound))
+				Expect(parsed).To(Equal(expected))
+			},
+			Entry("all results", map[string]string{
+				PullRequestsCreatedResult: "2",
+				PullRequestsUpdatedResult: "3",
+				UpdatedDependenciesResult: "7",
+				ErrorsResult:              "1",
+			}, RunResults{PullRequestsCreated: 2, PullRequestsUpdated: 3, UpdatedDependencies: 7, Errors: 1}, true),
+			Entry("some results", map[string]string{
+				ErrorsResult: "4",
+			}, RunResults{Errors: 4}, true),
+			Entry("no results", map[string]string{}, RunResults{}, false),
+			Entry("unrelated results", map[string]string{"other": "1"}, RunResults{}, false),
+			Entry("invalid values", map[string]string{
+				PullRequestsCreatedResult: "many",
+				ErrorsResult:              "-1",
+			}, RunResults{}, false),
+		)
+	})
+
+	When("RunReport is called", func() {
+		It("should return a JSON report", func() {
+			report, ok := RunReport(pipelineRunWithResults(map[string]string{ReportResult: `{"errorCount":1}`}))
+			Expect(ok).To(BeTrue())
+			Expect(report).To(Equal(`{"errorCount":1}`))
+		})
+
+		It("should ignore missing and invalid reports", func() {
+			_, ok := RunReport(pipelineRunWithResults(map[string]string{ErrorsResult: "1"}))
+			Expect(ok).To(BeFalse())
+			_, ok = RunReport(pipelineRunWithResults(map[string]string{ReportResult: `{"errorCount":`}))
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,149 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tekton
+
+import (
+	"encoding/json"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// SizeProfileAnnotation selects the size profile on a Component and
+	// records the one used on a PipelineRun
+	SizeProfileAnnotation = "mintmaker.appstudio.redhat.com/renovate-size"
+	// SizeProfileAuto picks the profile from the peak memory of the previous
+	// run, the same as not setting the annotation
+	SizeProfileAuto = "auto"
+
+	// PeakMemoryResult is the task result the renovate step writes its peak
+	// memory usage in bytes to
+	PeakMemoryResult = "renovate-peak-memory"
+
+	// The container of the renovate step in the TaskRun pod
+	renovateStepContainer = "step-" + renovateStepName
+
+	// Peak memory is multiplied by this when picking a profile, so runs
+	// growing slightly are not OOM killed
+	peakMemoryHeadroom = 1.25
+)
+
+// recordPeakMemoryScript writes the peak memory of the step's cgroup (v2,
+// falling back to v1) to the PeakMemoryResult task result when the step exits,
+// whatever the exit code of Renovate. It goes first in the step script.
+const recordPeakMemoryScript = `record_peak_memory() {
+  cat /sys/fs/cgroup/memory.peak /sys/fs/cgroup/memory/memory.max_usage_in_bytes 2>/dev/null |
+    head -n 1 | tr -d '\n' > "$(results.` + PeakMemoryResult + `.path)" || true
+}
+trap record_peak_memory EXIT
+`
+
+// SizeProfile is the CPU and memory of the renovate step, requests and limits
+// are the same
+type SizeProfile struct {
+	Name   string
+	CPU    resource.Quantity
+	Memory resource.Quantity
+}
+
+// SizeProfiles are ordered from the smallest to the largest
+var SizeProfiles = []SizeProfile{
+	{Name: "small", CPU: resource.MustParse("200m"), Memory: resource.MustParse("2Gi")},
+	{Name: "medium", CPU: resource.MustParse("300m"), Memory: resource.MustParse("3.5Gi")},
+	{Name: "large", CPU: resource.MustParse("500m"), Memory: resource.MustParse("6Gi")},
+}
+
+// LookupSizeProfile returns the profile with the given name
+func LookupSizeProfile(name string) (SizeProfile, bool) {
+	for _, profile := range SizeProfiles {
+		if profile.Name == name {
+			return profile, true
+		}
+	}
+	return SizeProfile{}, false
+}
+
+// SizeProfileForPeakMemory returns the smallest profile with room for the
+// given peak memory in bytes, or the largest profile
+func SizeProfileForPeakMemory(peak int64) SizeProfile {
+	needed := int64(float64(peak) * peakMemoryHeadroom)
+	for _, profile := range SizeProfiles {
+		if profile.Memory.Value() >= needed {
+			return profile
+		}
+	}
+	return SizeProfiles[len(SizeProfiles)-1]
+}
+
+// PeakMemory returns the peak memory in bytes of the renovate step from the
+// status of a completed TaskRun pod. An OOM killed step is reported with a
+// peak above its memory limit, so a larger profile gets picked.
+func PeakMemory(pod *corev1.Pod) (int64, bool) {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != renovateStepContainer || status.State.Terminated == nil {
+			continue
+		}
+		if status.State.Terminated.Reason == "OOMKilled" {
+			for _, container := range pod.Spec.Containers {
+				if limit, ok := container.Resources.Limits[corev1.ResourceMemory]; ok && container.Name == renovateStepContainer {
+					return limit.Value() + 1, true
+				}
+			}
+			return 0, false
+		}
+		// Tekton reports results as JSON in the termination message
+		var results []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		}
+		if err := json.Unmarshal([]byte(status.State.Terminated.Message), &results); err != nil {
+			return 0, false
+		}
+		for _, result := range results {
+			if result.Key != PeakMemoryResult {
+				continue
+			}
+			peak, err := strconv.ParseInt(result.Value, 10, 64)
+			return peak, err == nil && peak > 0
+		}
+	}
+	return 0, false
+}
+
+// WithSizeProfile sets the resources of the renovate step to the profile and
+// records it in the SizeProfileAnnotation.
+func (b *PipelineRunBuilder) WithSizeProfile(profile SizeProfile) *PipelineRunBuilder {
+	resources := corev1.ResourceList{
+		corev1.ResourceCPU:    profile.CPU,
+		corev1.ResourceMemory: profile.Memory,
+	}
+	for i, task := range b.pipelineRun.Spec.PipelineSpec.Tasks {
+		if task.Name != renovateTaskName || task.TaskSpec == nil {
+			continue
+		}
+		steps := b.pipelineRun.Spec.PipelineSpec.Tasks[i].TaskSpec.Steps
+		for j := range steps {
+			if steps[j].Name == renovateStepName {
+				steps[j].ComputeResources = corev1.ResourceRequirements{
+					Requests: resources,
+					Limits:   resources.DeepCopy(),
+				}
+			}
+		}
+	}
+	return b.WithAnnotations(map[string]string{SizeProfileAnnotation: profile.Name})
+}
@@ -0,0 +1,184 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tekton
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// runRenovateStep runs the script of the renovate step of the built-in spec
+// with set -e like Tekton does, with the renovate stub in mockDir and the task
+// results written to resultsDir
+func runRenovateStep(mockDir, resultsDir string) (string, int) {
+	var script string
+	for _, step := range DefaultPipelineSpec().Tasks[0].TaskSpec.Steps {
+		if step.Name == "renovate" {
+			script = step.Script
+		}
+	}
+	return runStepScript(script, mockDir, resultsDir)
+}
+
+// runStepScript runs the script of a step of the build task like Tekton does,
+// with the stubs in mockDir, the task results written to resultsDir and the
+// given environment variables
+func runStepScript(script, mockDir, resultsDir string, env ...string) (string, int) {
+	for _, result := range []string{PeakMemoryResult, RenovateExitCodeResult} {
+		script = strings.ReplaceAll(script, "$(results."+result+".path)", filepath.Join(resultsDir, result))
+	}
+	scriptFile := filepath.Join(resultsDir, "script.sh")
+	Expect(os.WriteFile(scriptFile, []byte("#!/bin/sh\nset -e\n"+script), 0600)).To(Succeed())
+
+	cmd := exec.Command("sh", scriptFile) //nolint:gosec // test helper runs the script under test
+	cmd.Env = append([]string{"PATH=" + mockDir + ":/usr/bin:/bin", "HOME=/tmp"}, env...)
+	var outBuf bytes.Buffer
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &outBuf
+	err := cmd.Run()
+	exitCode := 0
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		exitCode = exitErr.ExitCode()
+	} else if err != nil {
+		exitCode = -1
+	}
+	return outBuf.String(), exitCode
+}
+
+var _ = Describe("Size profiles", func() {
+
+	When("LookupSizeProfile is called", func() {
+		It("should return known profiles", func() {
+			profile, ok := LookupSizeProfile("large")
+			Expect(ok).To(BeTrue())
+			Expect(profile.Memory.String()).To(Equal("6Gi"))
+		})
+
+		It("should not return unknown profiles", func() {
+			_, ok := LookupSizeProfile("huge")
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	When("SizeProfileForPeakMemory is called", func() {
+		DescribeTable("should pick the smallest profile with headroom",
+			func(peak string, expected string) {
+				quantity := resource.MustParse(peak)
+				Expect(SizeProfileForPeakMemory(quantity.Value()).Name).To(Equal(expected))
+			},
+			Entry("small run", "1Gi", "small"),
+			Entry("small run without headroom", "1900Mi", "medium"),
+			Entry("medium run", "2.5Gi", "medium"),
+			Entry("monorepo", "4Gi", "large"),
+			Entry("beyond the largest profile", "10Gi", "large"),
+		)
+	})
+
+	When("PeakMemory is called", func() {
+		renovatePod := func(state corev1.ContainerState) *corev1.Pod {
+			return &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "step-renovate",
+							Resources: corev1.ResourceRequirements{
+								Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+							},
+						},
+					},
+				},
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{
+						{Name: "step-prepare-db", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: "[]"}}},
+						{Name: "step-renovate", State: state},
+					},
+				},
+			}
+		}
+
+		It("should read the peak memory result from the termination message", func() {
+			pod := renovatePod(corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				Reason:  "Completed",
+				Message: `[{"key":"renovate-peak-memory","value":"1073741824","type":1}]`,
+			}})
+			peak, ok := PeakMemory(pod)
+			Expect(ok).To(BeTrue())
+			Expect(peak).To(Equal(int64(1073741824)))
+		})
+
+		It("should report an OOM killed step above its memory limit", func() {
+			pod := renovatePod(corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"}})
+			peak, ok := PeakMemory(pod)
+			Expect(ok).To(BeTrue())
+			Expect(SizeProfileForPeakMemory(peak).Name).To(Equal("medium"))
+		})
+
+		It("should not report a peak without the result", func() {
+			pod := renovatePod(corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed", Message: "[]"}})
+			_, ok := PeakMemory(pod)
+			Expect(ok).To(BeFalse())
+		})
+
+		It("should not report a peak for a running step", func() {
+			pod := renovatePod(corev1.ContainerState{Running: &corev1.ContainerStateRunning{}})
+			_, ok := PeakMemory(pod)
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	When("WithSizeProfile is called", func() {
+		It("should set the renovate step resources and record the profile", func() {
+			profile, _ := LookupSizeProfile("small")
+			pipelineRun, err := NewPipelineRunBuilder("test", "mintmaker").WithSizeProfile(profile).Build()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(pipelineRun.Annotations).To(HaveKeyWithValue(SizeProfileAnnotation, "small"))
+			for _, step := range pipelineRun.Spec.PipelineSpec.Tasks[0].TaskSpec.Steps {
+				if step.Name == "renovate" {
+					Expect(step.ComputeResources.Requests.Memory().String()).To(Equal("2Gi"))
+					Expect(step.ComputeResources.Limits.Cpu().String()).To(Equal("200m"))
+				} else {
+					Expect(step.ComputeResources.Limits.Memory().String()).NotTo(Equal("2Gi"))
+				}
+			}
+		})
+
+		It("should record the peak memory when Renovate fails", func() {
+			tmpDir := GinkgoT().TempDir()
+			writeStub(tmpDir, "renovate", "exit 3")
+
+			runRenovateStep(tmpDir, tmpDir)
+			Expect(filepath.Join(tmpDir, PeakMemoryResult)).To(BeAnExistingFile())
+		})
+
+		It("should record the peak memory in the built-in spec", func() {
+			task := DefaultPipelineSpec().Tasks[0]
+			Expect(task.TaskSpec.Results).To(ContainElement(HaveField("Name", PeakMemoryResult)))
+			for _, step := range task.TaskSpec.Steps {
+				if step.Name == "renovate" {
+					Expect(step.Script).To(ContainSubstring("$(results." + PeakMemoryResult + ".path)"))
+				}
+			}
+		})
+	})
+})
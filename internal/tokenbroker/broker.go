@@ -0,0 +1,290 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tokenbroker serves short-lived Git tokens to Renovate pods.
+//
+// A Renovate pod authenticates with its projected ServiceAccount token. The
+// broker validates it with a TokenReview, resolves the pod that owns the
+// token and maps the pod's MintMaker labels back to the Konflux Component.
+// It then returns a freshly minted token from the component's GitComponent,
+// and its SSH deploy key when it clones over SSH. Credentials are only ever
+// held in memory and never persisted in etcd.
+package tokenbroker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
+
+	"github.com/konflux-ci/mintmaker/internal/component"
+	mmconst "github.com/konflux-ci/mintmaker/internal/constant"
+	"github.com/konflux-ci/mintmaker/internal/credentials"
+	"github.com/konflux-ci/mintmaker/internal/tekton"
+)
+
+const (
+	// TokenPath is the HTTP path the broker serves tokens on
+	TokenPath = "/token"
+
+	// Keys of the extra user info set by the API server for bound ServiceAccount tokens
+	podNameExtraKey = "authentication.kubernetes.io/pod-name"
+	podUIDExtraKey  = "authentication.kubernetes.io/pod-uid"
+
+	serviceAccountUserPrefix = "system:serviceaccount:"
+)
+
+var errUnauthorized = errors.New("unauthorized")
+
+// TokenResponse is the JSON body returned to Renovate pods.
+type TokenResponse struct {
+	Token string `json:"token"`
+	// SSHPrivateKey and SSHKnownHosts are set for components cloning with an
+	// SSH deploy key
+	SSHPrivateKey string `json:"sshPrivateKey,omitempty"`
+	SSHKnownHosts string `json:"sshKnownHosts,omitempty"`
+	// ExpiresAt is when Token expires, the pod fetches a new one before then
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// Fingerprints of the token and SSH key, recorded by the pod so they are
+	// redacted from the Renovate log
+	Fingerprints []string `json:"fingerprints,omitempty"`
+}
+
+// Broker is an http.Handler and a manager.Runnable serving Git tokens.
+type Broker struct {
+	Client          client.Client
+	NewGitComponent component.GitComponentFactory
+	// Audience that the presented ServiceAccount token must be issued for
+	Audience string
+	// BindAddress is the address the broker listens on
+	BindAddress string
+	// CertFile and KeyFile enable HTTPS when both are set
+	CertFile string
+	KeyFile  string
+}
+
+// Start serves the broker until the context is cancelled.
+func (b *Broker) Start(ctx context.Context) error {
+	log := ctrllog.FromContext(ctx).WithName("TokenBroker")
+
+	mux := http.NewServeMux()
+	mux.Handle(TokenPath, b)
+	server := &http.Server{
+		Addr:              b.BindAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(_ net.Listener) context.Context { return ctx },
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Info("starting token broker", "address", b.BindAddress, "tls", b.CertFile != "")
+		var err error
+		if b.CertFile != "" && b.KeyFile != "" {
+			err = server.ListenAndServeTLS(b.CertFile, b.KeyFile)
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+
+	select {
+	case <-ctx.Done():
+		log.Info("shutting down token broker")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdownCtx) //nolint:contextcheck // parent context is already cancelled
+	case err := <-errCh:
+		return err
+	}
+}
+
+// NeedLeaderElection returns false, every replica serves tokens.
+func (b *Broker) NeedLeaderElection() bool {
+	return false
+}
+
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get
+// +kubebuilder:rbac:groups=appstudio.redhat.com,resources=components,verbs=get
+
+// ServeHTTP authenticates the calling pod and responds with a fresh Git token.
+func (b *Broker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := ctrllog.FromContext(ctx).WithName("TokenBroker")
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	bearer, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || bearer == "" {
+		http.Error(w, "missing bearer token", http.StatusUnauthorized)
+		return
+	}
+
+	pod, err := b.authenticate(ctx, bearer)
+	if err != nil {
+		if errors.Is(err, errUnauthorized) {
+			log.Info("rejected token request", "reason", err.Error())
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		log.Error(err, "failed to authenticate token request")
+		http.Error(w, "failed to authenticate", http.StatusInternalServerError)
+		return
+	}
+	log = log.WithValues(
+		"pod", pod.Name,
+		"pipelineRun", pod.Labels["tekton.dev/pipelineRun"],
+		"component", pod.Labels[mmconst.MintMakerComponentNameLabel],
+		"componentNamespace", pod.Labels[mmconst.MintMakerComponentNamespaceLabel],
+	)
+	ctx = ctrllog.IntoContext(ctx, log)
+
+	response, err := b.mintToken(ctx, pod)
+	if err != nil {
+		log.Error(err, "failed to mint token for pod")
+		http.Error(w, "failed to mint token", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error(err, "failed to write token response")
+		return
+	}
+	log.Info("issued token to pod")
+}
+
+// authenticate validates the ServiceAccount token and returns the MintMaker
+// PipelineRun pod the token was bound to.
+func (b *Broker) authenticate(ctx context.Context, bearer string) (*corev1.Pod, error) {
+	review := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token:     bearer,
+			Audiences: []string{b.Audience},
+		},
+	}
+	if err := b.Client.Create(ctx, review); err != nil {
+		return nil, fmt.Errorf("token review failed: %w", err)
+	}
+	if !review.Status.Authenticated {
+		return nil, fmt.Errorf("%w: token not authenticated: %s", errUnauthorized, review.Status.Error)
+	}
+	if !slices.Contains(review.Status.Audiences, b.Audience) {
+		return nil, fmt.Errorf("%w: token not issued for audience %s", errUnauthorized, b.Audience)
+	}
+
+	// ServiceAccount usernames have the form system:serviceaccount:<namespace>:<name>
+	saNamespace, _, found := strings.Cut(strings.TrimPrefix(review.Status.User.Username, serviceAccountUserPrefix), ":")
+	if !strings.HasPrefix(review.Status.User.Username, serviceAccountUserPrefix) || !found {
+		return nil, fmt.Errorf("%w: %s is not a ServiceAccount", errUnauthorized, review.Status.User.Username)
+	}
+	if saNamespace != mmconst.MintMakerNamespaceName {
+		return nil, fmt.Errorf("%w: ServiceAccount is not from the %s namespace", errUnauthorized, mmconst.MintMakerNamespaceName)
+	}
+
+	// Only tokens bound to a pod carry the pod name and UID
+	podName := firstExtra(review.Status.User.Extra, podNameExtraKey)
+	podUID := firstExtra(review.Status.User.Extra, podUIDExtraKey)
+	if podName == "" || podUID == "" {
+		return nil, fmt.Errorf("%w: token is not bound to a pod", errUnauthorized)
+	}
+
+	pod := &corev1.Pod{}
+	if err := b.Client.Get(ctx, client.ObjectKey{Namespace: saNamespace, Name: podName}, pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: pod %s not found", errUnauthorized, podName)
+		}
+		return nil, fmt.Errorf("failed to get pod %s: %w", podName, err)
+	}
+	if string(pod.UID) != podUID {
+		return nil, fmt.Errorf("%w: pod %s UID mismatch", errUnauthorized, podName)
+	}
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return nil, fmt.Errorf("%w: pod %s has already finished", errUnauthorized, podName)
+	}
+	if pod.Labels["tekton.dev/pipelineRun"] == "" ||
+		pod.Labels[mmconst.MintMakerComponentNameLabel] == "" ||
+		pod.Labels[mmconst.MintMakerComponentNamespaceLabel] == "" {
+		return nil, fmt.Errorf("%w: pod %s is not a MintMaker PipelineRun pod", errUnauthorized, podName)
+	}
+
+	return pod, nil
+}
+
+// mintToken maps the pod back to its Component and returns a fresh token for
+// it, with the SSH deploy key of components cloning over SSH.
+func (b *Broker) mintToken(ctx context.Context, pod *corev1.Pod) (*TokenResponse, error) {
+	comp := &appstudiov1alpha1.Component{}
+	key := client.ObjectKey{
+		Namespace: pod.Labels[mmconst.MintMakerComponentNamespaceLabel],
+		Name:      pod.Labels[mmconst.MintMakerComponentNameLabel],
+	}
+	if err := b.Client.Get(ctx, key, comp); err != nil {
+		return nil, fmt.Errorf("failed to get component %s: %w", key, err)
+	}
+
+	gitComp, err := b.NewGitComponent(ctx, comp, b.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	// The pod must belong to the same git host the component lives on, otherwise
+	// the labels were tampered with and we would hand out a foreign token.
+	if host := pod.Labels[mmconst.MintMakerGitHostLabel]; host != gitComp.GetHost() {
+		return nil, fmt.Errorf("pod git host %q does not match component git host %q", host, gitComp.GetHost())
+	}
+
+	cred, err := gitComp.GetCredential()
+	if err != nil {
+		return nil, err
+	}
+	response := &TokenResponse{Token: cred.Token, Fingerprints: tekton.SecretFingerprints(cred.Token)}
+	if !cred.ExpiresAt.IsZero() {
+		response.ExpiresAt = &cred.ExpiresAt
+	}
+	if cred.Type == credentials.CredentialTypeSSH {
+		response.SSHPrivateKey = string(cred.SSHPrivateKey)
+		response.SSHKnownHosts = string(cred.SSHKnownHosts)
+		response.Fingerprints = append(response.Fingerprints, tekton.SecretFingerprints(response.SSHPrivateKey)...)
+	}
+	return response, nil
+}
+
+func firstExtra(extra map[string]authenticationv1.ExtraValue, key string) string {
+	if values := extra[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}